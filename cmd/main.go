@@ -7,21 +7,11 @@ package main
 
 import (
 	"context"
-	"log"
+	"os"
 
-	"github.com/board-box/backend/internal/app"
+	"github.com/board-box/backend/internal/cli"
 )
 
 func main() {
-	ctx := context.Background()
-
-	a, err := app.NewApp(ctx)
-	if err != nil {
-		log.Fatalf("could not create app: %v\n", err)
-		return
-	}
-
-	if err = a.Run(); err != nil {
-		log.Fatalf("could not create app: %v\n", err)
-	}
+	os.Exit(cli.Run(context.Background(), os.Args[1:], cli.IO{In: os.Stdin, Out: os.Stdout, Err: os.Stderr}))
 }
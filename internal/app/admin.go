@@ -0,0 +1,188 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/board-box/backend/internal/config"
+	"github.com/board-box/backend/internal/pagination"
+	"github.com/board-box/backend/internal/service/chat"
+	"github.com/board-box/backend/internal/service/collection"
+	"github.com/board-box/backend/internal/service/game"
+	"github.com/board-box/backend/internal/service/gameimport"
+	"github.com/board-box/backend/internal/service/play"
+	"github.com/board-box/backend/internal/service/similar"
+	"github.com/board-box/backend/internal/service/user"
+)
+
+// Open собирает конфигурацию, базу и сервисы без HTTP-роутера — для
+// административных команд. После работы приложение нужно закрыть через Close.
+func Open(ctx context.Context) (*App, error) {
+	a := &App{}
+
+	inits := []func(context.Context) error{
+		a.initConfigs,
+		a.initAuth,
+		a.initDB,
+		a.initService,
+	}
+	for _, fn := range inits {
+		if err := fn(ctx); err != nil {
+			a.Close()
+			return nil, err
+		}
+	}
+
+	return a, nil
+}
+
+// Close закрывает пул соединений с базой.
+func (a *App) Close() {
+	if a.db != nil {
+		a.db.Close()
+	}
+}
+
+func (a *App) Config() *config.Config {
+	return a.cfg
+}
+
+func (a *App) Users() *user.Service {
+	return a.userSvc
+}
+
+func (a *App) GameImport() *gameimport.Service {
+	return a.gameImportSvc
+}
+
+func (a *App) Similar() *similar.Service {
+	return a.similarSvc
+}
+
+// Seed добавляет в каталог игры из JSON-массива в формате ответа GET /games/{id}.
+// Идентификаторы из файла игнорируются. Возвращает число добавленных игр.
+func (a *App) Seed(ctx context.Context, r io.Reader) (int, error) {
+	var games []game.Game
+	if err := json.NewDecoder(r).Decode(&games); err != nil {
+		return 0, fmt.Errorf("decode games: %w", err)
+	}
+
+	for i, g := range games {
+		if g.Title == "" {
+			return i, fmt.Errorf("game #%d: title is empty", i+1)
+		}
+		if _, err := a.gameSvc.CreateGame(ctx, g); err != nil {
+			return i, fmt.Errorf("game %q: %w", g.Title, err)
+		}
+	}
+
+	return len(games), nil
+}
+
+// UserExport — все данные пользователя: профиль, коллекции, партии и диалоги
+// с ассистентом.
+type UserExport struct {
+	User          user.User               `json:"user"`
+	Collections   []collection.Collection `json:"collections"`
+	Plays         []play.Play             `json:"plays"`
+	Conversations []chat.Conversation     `json:"conversations"`
+	ExportedAt    time.Time               `json:"exported_at"`
+}
+
+// collectionGameIDs возвращает все игры коллекции по порядку.
+func (a *App) collectionGameIDs(ctx context.Context, collectionID, userID int64) ([]int64, error) {
+	var ids []int64
+	page := pagination.Params{Limit: pagination.MaxLimit}
+	for {
+		gameIDs, meta, err := a.collectionSvc.ListCollectionGameIDs(ctx, collectionID, userID, page)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, gameIDs...)
+
+		if meta.NextCursor == "" {
+			return ids, nil
+		}
+		page.Cursor = meta.NextCursor
+	}
+}
+
+// ExportUser собирает все данные пользователя и пишет их в out в виде JSON.
+func (a *App) ExportUser(ctx context.Context, userID int64, out io.Writer) error {
+	u, err := a.userSvc.Info(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	export := UserExport{
+		User:          u,
+		Collections:   []collection.Collection{},
+		Plays:         []play.Play{},
+		Conversations: []chat.Conversation{},
+		ExportedAt:    time.Now().UTC(),
+	}
+
+	page := pagination.Params{Limit: pagination.MaxLimit}
+	for {
+		collections, meta, err := a.collectionSvc.ListCollections(ctx, userID, collection.ListFilter{}, page)
+		if err != nil {
+			return fmt.Errorf("list collections: %w", err)
+		}
+		for _, c := range collections {
+			// В списке только первые игры коллекции, в выгрузке нужны все
+			if len(c.GameIDs) < c.GameCount {
+				if c.GameIDs, err = a.collectionGameIDs(ctx, c.ID, userID); err != nil {
+					return fmt.Errorf("collection %d: %w", c.ID, err)
+				}
+			}
+			export.Collections = append(export.Collections, c)
+		}
+
+		if meta.NextCursor == "" {
+			break
+		}
+		page.Cursor = meta.NextCursor
+	}
+
+	page = pagination.Params{Limit: pagination.MaxLimit}
+	for {
+		plays, meta, err := a.playSvc.ListPlays(ctx, userID, play.ListFilter{}, page)
+		if err != nil {
+			return fmt.Errorf("list plays: %w", err)
+		}
+		export.Plays = append(export.Plays, plays...)
+
+		if meta.NextCursor == "" {
+			break
+		}
+		page.Cursor = meta.NextCursor
+	}
+
+	page = pagination.Params{Limit: pagination.MaxLimit}
+	for {
+		conversations, meta, err := a.chatSvc.ListConversations(ctx, userID, page)
+		if err != nil {
+			return fmt.Errorf("list conversations: %w", err)
+		}
+
+		for _, c := range conversations {
+			full, err := a.chatSvc.GetConversation(ctx, c.ID, userID)
+			if err != nil {
+				return fmt.Errorf("conversation %d: %w", c.ID, err)
+			}
+			export.Conversations = append(export.Conversations, full)
+		}
+
+		if meta.NextCursor == "" {
+			break
+		}
+		page.Cursor = meta.NextCursor
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(export)
+}
@@ -0,0 +1,161 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/board-box/backend/internal/auth"
+	"github.com/board-box/backend/internal/service/collection"
+	"github.com/board-box/backend/internal/service/user"
+)
+
+func TestSeed(t *testing.T) {
+	a := newTestApp(t)
+	ctx := context.Background()
+
+	n, err := a.Seed(ctx, strings.NewReader(`[
+		{"id": 999, "title": "Азул", "genre": "Абстрактная", "person": "2-4", "avg_time": "30-45 мин", "age": "8+", "complexity": "1.5"},
+		{"title": "Колонизаторы", "genre": "Стратегия"}
+	]`))
+	if err != nil || n != 2 {
+		t.Fatalf("Seed() = %d, %v; want 2 games", n, err)
+	}
+
+	var list listGamesResponse
+	expect(t, a.do(t, http.MethodGet, "/games/", "", nil), http.StatusOK, &list)
+	if list.Total != 2 {
+		t.Fatalf("total = %d, want 2", list.Total)
+	}
+
+	if _, err = a.Seed(ctx, strings.NewReader(`[{"genre": "Без названия"}]`)); err == nil {
+		t.Fatal("Seed() accepted a game without title")
+	}
+	if _, err = a.Seed(ctx, strings.NewReader(`{"title": "не массив"}`)); err == nil {
+		t.Fatal("Seed() accepted an object instead of an array")
+	}
+}
+
+func TestCreateAdmin(t *testing.T) {
+	a := newTestApp(t)
+	ctx := context.Background()
+
+	// Новый пользователь регистрируется и сразу получает роль admin
+	created, err := a.userSvc.CreateAdmin(ctx, "root", "root@example.com", "root-password")
+	if err != nil {
+		t.Fatalf("CreateAdmin() error = %v", err)
+	}
+	if !hasRole(created.Roles, auth.RoleAdmin) || !hasRole(created.Roles, auth.RoleUser) {
+		t.Fatalf("roles = %v, want user and admin", created.Roles)
+	}
+
+	u := testUser{ID: created.ID, Email: created.Email}
+	a.login(t, &u, "root-password")
+	expect(t, a.do(t, http.MethodGet, "/admin/ops/db", u.Token, nil), http.StatusOK, nil)
+
+	// Существующий пользователь получает роль, пароль не меняется
+	bob := a.registerUser(t, "bob")
+	promoted, err := a.userSvc.CreateAdmin(ctx, "ignored", bob.Email, "")
+	if err != nil {
+		t.Fatalf("CreateAdmin() for existing user error = %v", err)
+	}
+	if promoted.ID != bob.ID || !hasRole(promoted.Roles, auth.RoleAdmin) {
+		t.Fatalf("promoted = %+v, want user %d with admin role", promoted, bob.ID)
+	}
+	a.login(t, &bob, "password-bob")
+
+	if _, err = a.userSvc.CreateAdmin(ctx, "nobody", "nobody@example.com", ""); !errors.Is(err, user.ErrEmptyPassword) {
+		t.Fatalf("CreateAdmin() without password error = %v, want ErrEmptyPassword", err)
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	a := newTestApp(t)
+	ctx := context.Background()
+
+	alice := a.registerUser(t, "alice")
+
+	if err := a.userSvc.ResetPassword(ctx, alice.Email, "new-password"); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+
+	// Старые сессии завершены, старый пароль больше не подходит
+	expect(t, a.do(t, http.MethodGet, "/user/info", alice.Token, nil), http.StatusUnauthorized, nil)
+	rec := a.do(t, http.MethodPost, "/user/login", "", map[string]string{"email": alice.Email, "password": "password-alice"})
+	expect(t, rec, http.StatusUnauthorized, nil)
+
+	a.login(t, &alice, "new-password")
+	expect(t, a.do(t, http.MethodGet, "/user/info", alice.Token, nil), http.StatusOK, nil)
+
+	if err := a.userSvc.ResetPassword(ctx, "ghost@example.com", "x"); !errors.Is(err, user.ErrUserNotFound) {
+		t.Fatalf("ResetPassword() for unknown user error = %v, want ErrUserNotFound", err)
+	}
+	if err := a.userSvc.ResetPassword(ctx, alice.Email, ""); !errors.Is(err, user.ErrEmptyPassword) {
+		t.Fatalf("ResetPassword() with empty password error = %v, want ErrEmptyPassword", err)
+	}
+}
+
+func TestExportUser(t *testing.T) {
+	a := newTestApp(t)
+	ids := a.seedGames(t)
+	alice := a.registerUser(t, "alice")
+	bob := a.registerUser(t, "bob")
+
+	var coll struct {
+		ID int64 `json:"id"`
+	}
+	expect(t, a.do(t, http.MethodPost, "/collections/", alice.Token, map[string]any{"name": "Любимые"}), http.StatusCreated, &coll)
+	expect(t, a.do(t, http.MethodPost, "/collections/"+itoa(coll.ID)+"/games/"+itoa(ids["Серп"]), alice.Token, nil), http.StatusNoContent, nil)
+	expect(t, a.do(t, http.MethodPost, "/collections/", bob.Token, map[string]any{"name": "Чужая"}), http.StatusCreated, nil)
+	expect(t, a.do(t, http.MethodPost, "/chat/", alice.Token, map[string]any{"message": "Посоветуй игру"}), http.StatusOK, nil)
+	logged := map[string]any{"game_id": ids["Серп"], "players": []map[string]any{{"user_id": alice.ID, "winner": true}}}
+	expect(t, a.do(t, http.MethodPost, "/plays/", alice.Token, logged), http.StatusCreated, nil)
+
+	var buf bytes.Buffer
+	if err := a.ExportUser(context.Background(), alice.ID, &buf); err != nil {
+		t.Fatalf("ExportUser() error = %v", err)
+	}
+
+	var export UserExport
+	if err := json.Unmarshal(buf.Bytes(), &export); err != nil {
+		t.Fatalf("decode export: %v\n%s", err, buf.String())
+	}
+	if strings.Contains(buf.String(), "password") {
+		t.Fatalf("export contains password hash:\n%s", buf.String())
+	}
+
+	if export.User.ID != alice.ID || export.User.Email != alice.Email {
+		t.Fatalf("user = %+v, want alice", export.User)
+	}
+	// Кроме Любимых в выгрузке пустые системные коллекции alice
+	var custom []collection.Collection
+	for _, c := range export.Collections {
+		if c.Type == collection.TypeCustom {
+			custom = append(custom, c)
+		} else if len(c.GameIDs) != 0 {
+			t.Errorf("system collection %+v is not empty", c)
+		}
+	}
+	if len(custom) != 1 || custom[0].Name != "Любимые" || !equalIDs(custom[0].GameIDs, []int64{ids["Серп"]}) {
+		t.Fatalf("collections = %+v, want only Любимые with Серп", export.Collections)
+	}
+	if len(export.Plays) != 1 || export.Plays[0].GameID != ids["Серп"] || len(export.Plays[0].Players) != 1 {
+		t.Fatalf("plays = %+v, want one play of Серп", export.Plays)
+	}
+	if len(export.Conversations) != 1 || len(export.Conversations[0].Messages) != 2 {
+		t.Fatalf("conversations = %+v, want one with question and answer", export.Conversations)
+	}
+}
+
+func hasRole(roles []auth.Role, role auth.Role) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
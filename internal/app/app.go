@@ -3,24 +3,40 @@ package app
 import (
 	"context"
 	"fmt"
+	"io"
+	"log"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
-	"time"
 
 	"github.com/board-box/backend/docs"
 	"github.com/board-box/backend/internal/auth"
 	"github.com/board-box/backend/internal/config"
+	"github.com/board-box/backend/internal/db"
 	chatHandler "github.com/board-box/backend/internal/handler/chat"
 	collectionHandler "github.com/board-box/backend/internal/handler/collection"
+	collectionImportHandler "github.com/board-box/backend/internal/handler/collectionimport"
 	gameHandler "github.com/board-box/backend/internal/handler/game"
+	gameImportHandler "github.com/board-box/backend/internal/handler/gameimport"
+	opsHandler "github.com/board-box/backend/internal/handler/ops"
+	playHandler "github.com/board-box/backend/internal/handler/play"
+	ratingHandler "github.com/board-box/backend/internal/handler/rating"
+	similarHandler "github.com/board-box/backend/internal/handler/similar"
 	userHandler "github.com/board-box/backend/internal/handler/user"
+	"github.com/board-box/backend/internal/migrator"
 	"github.com/board-box/backend/internal/service/chat"
+	"github.com/board-box/backend/internal/service/chat/llm"
 	"github.com/board-box/backend/internal/service/collection"
+	"github.com/board-box/backend/internal/service/collectionimport"
 	"github.com/board-box/backend/internal/service/game"
+	"github.com/board-box/backend/internal/service/gameimport"
+	"github.com/board-box/backend/internal/service/play"
+	"github.com/board-box/backend/internal/service/rating"
+	"github.com/board-box/backend/internal/service/similar"
 	"github.com/board-box/backend/internal/service/user"
 	"github.com/gin-gonic/gin"
-	pgx "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -30,14 +46,20 @@ type App struct {
 	jwt *auth.JWTManager
 
 	r  *gin.Engine
-	db *pgx.Conn
-
-	authMW func(c *gin.Context)
-
-	chatSvc       *chat.Service
-	gameSvc       *game.Service
-	userSvc       *user.Service
-	collectionSvc *collection.Service
+	db *pgxpool.Pool
+
+	authMW  func(c *gin.Context)
+	adminMW func(c *gin.Context)
+
+	chatSvc             *chat.Service
+	gameSvc             *game.Service
+	gameImportSvc       *gameimport.Service
+	userSvc             *user.Service
+	collectionSvc       *collection.Service
+	collectionImportSvc *collectionimport.Service
+	playSvc             *play.Service
+	ratingSvc           *rating.Service
+	similarSvc          *similar.Service
 }
 
 func NewApp(ctx context.Context) (*App, error) {
@@ -60,24 +82,38 @@ func (a *App) Run() error {
 		}
 	}()
 
-	<-quit
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	if err := a.db.Close(ctx); err != nil {
-		return fmt.Errorf("error closing db: %w", err)
+	var wg sync.WaitGroup
+	if interval := a.cfg.Similar.RefreshInterval; interval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.similarSvc.RunRefresher(ctx, interval, func(err error) {
+				log.Printf("refresh similar games: %v", err)
+			})
+		}()
 	}
 
+	<-quit
+	cancel()
+	// Пересчёт должен завершиться до закрытия пула соединений
+	wg.Wait()
+
+	a.db.Close()
+
 	return nil
 }
 
 func (a *App) initDeps(ctx context.Context) error {
 	inits := []func(context.Context) error{
 		a.initConfigs,
-		a.initMiddleware,
+		a.initAuth,
 		a.initDB,
+		a.initMigrations,
 		a.initService,
+		a.initMiddleware,
 		a.initRouter,
 	}
 
@@ -98,21 +134,24 @@ func (a *App) initConfigs(_ context.Context) error {
 	}
 
 	docs.SwaggerInfo.Host = a.cfg.ExternalAddr()
+	return nil
+}
 
-	a.jwt = auth.NewJWTManager(a.cfg.JWT.SecretKey, a.cfg.JWT.TokenDuration)
+func (a *App) initAuth(_ context.Context) error {
+	a.jwt = auth.NewJWTManager(a.cfg.JWT.SecretKey, a.cfg.JWT.TokenDuration, a.cfg.JWT.RefreshDuration)
 	return nil
 }
 
 func (a *App) initMiddleware(_ context.Context) error {
-	a.authMW = auth.Middleware(a.jwt.SecretKey)
+	a.authMW = auth.Middleware(a.jwt.SecretKey, a.userSvc)
+	a.adminMW = auth.RequireRole(auth.RoleAdmin)
 	return nil
 }
 
-func (a *App) initDB(_ context.Context) error {
+func (a *App) initDB(ctx context.Context) error {
 	var err error
-	ctx := context.Background()
 
-	a.db, err = pgx.Connect(ctx, a.cfg.DSN())
+	a.db, err = db.NewPool(ctx, a.cfg.DSN(), a.cfg.Postgres)
 	if err != nil {
 		return fmt.Errorf("unable to connect to database: %v\n", err)
 	}
@@ -120,11 +159,56 @@ func (a *App) initDB(_ context.Context) error {
 	return nil
 }
 
+// initMigrations накатывает миграции при старте, если включён APP_AUTO_MIGRATE.
+func (a *App) initMigrations(ctx context.Context) error {
+	if !a.cfg.App.AutoMigrate {
+		return nil
+	}
+
+	if err := migrator.Up(ctx, a.db, os.Stdout); err != nil {
+		return fmt.Errorf("apply migrations: %w", err)
+	}
+	return nil
+}
+
+// Migrate выполняет команду миграций (up, down, status, redo) без запуска
+// HTTP-сервера.
+func Migrate(ctx context.Context, command string, out io.Writer) error {
+	a := &App{}
+	for _, fn := range []func(context.Context) error{a.initConfigs, a.initDB} {
+		if err := fn(ctx); err != nil {
+			return err
+		}
+	}
+	defer a.db.Close()
+
+	return migrator.Run(ctx, a.db, command, out)
+}
+
 func (a *App) initService(_ context.Context) error {
-	a.chatSvc = chat.NewService(a.cfg.ChatApiKey)
-	a.gameSvc = game.NewService(a.db)
-	a.userSvc = user.NewService(a.db, a.jwt)
-	a.collectionSvc = collection.NewService(a.db, a.gameSvc)
+	provider, err := llm.New(a.cfg.Chat.Provider, llm.Options{
+		BaseURL:     a.cfg.Chat.BaseURL,
+		Model:       a.cfg.Chat.Model,
+		APIKey:      a.cfg.Chat.APIKey,
+		Temperature: a.cfg.Chat.Temperature,
+		Timeout:     a.cfg.Chat.Timeout,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Все репозитории работают через TxDB, чтобы участвовать в транзакциях WithTx
+	txDB := db.NewTxDB(a.db)
+
+	a.gameSvc = game.NewService(game.NewRepository(txDB))
+	a.gameImportSvc = gameimport.NewService(a.gameSvc, txDB)
+	a.collectionSvc = collection.NewService(collection.NewRepository(txDB), txDB, a.gameSvc)
+	a.userSvc = user.NewService(user.NewRepository(txDB), txDB, a.jwt, a.collectionSvc)
+	a.collectionImportSvc = collectionimport.NewService(a.gameSvc, a.collectionSvc, txDB)
+	a.playSvc = play.NewService(play.NewRepository(txDB), txDB, a.gameSvc)
+	a.ratingSvc = rating.NewService(rating.NewRepository(txDB), txDB)
+	a.similarSvc = similar.NewService(similar.NewRepository(txDB), txDB, a.gameSvc)
+	a.chatSvc = chat.NewService(chat.NewRepository(txDB), provider, a.gameSvc, a.collectionSvc)
 	return nil
 }
 
@@ -133,18 +217,36 @@ func (a *App) initRouter(_ context.Context) error {
 
 	api := a.r.Group("/api/v1")
 
-	gameRouter := gameHandler.New(a.gameSvc)
+	gameRouter := gameHandler.New(a.gameSvc, a.authMW, a.adminMW)
 	gameRouter.RegisterRoutes(api)
 
+	ratingRouter := ratingHandler.New(a.ratingSvc, a.authMW)
+	ratingRouter.RegisterRoutes(api)
+
+	similarRouter := similarHandler.New(a.similarSvc, a.authMW)
+	similarRouter.RegisterRoutes(api)
+
+	gameImportRouter := gameImportHandler.New(a.gameImportSvc, a.authMW, a.adminMW)
+	gameImportRouter.RegisterRoutes(api)
+
 	collectionRouter := collectionHandler.New(a.collectionSvc, a.authMW)
 	collectionRouter.RegisterRoutes(api)
 
-	userRouter := userHandler.New(a.userSvc, a.authMW)
+	collectionImportRouter := collectionImportHandler.New(a.collectionImportSvc, a.authMW)
+	collectionImportRouter.RegisterRoutes(api)
+
+	playRouter := playHandler.New(a.playSvc, a.authMW)
+	playRouter.RegisterRoutes(api)
+
+	userRouter := userHandler.New(a.userSvc, a.authMW, a.adminMW)
 	userRouter.RegisterRoutes(api)
 
 	chatRouter := chatHandler.New(a.chatSvc, a.authMW)
 	chatRouter.RegisterRoutes(api)
 
+	opsRouter := opsHandler.New(a.db, a.authMW, a.adminMW)
+	opsRouter.RegisterRoutes(api)
+
 	a.r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	return nil
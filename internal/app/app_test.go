@@ -0,0 +1,173 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/board-box/backend/internal/config"
+	"github.com/board-box/backend/internal/service/chat/llm"
+	"github.com/board-box/backend/internal/service/game"
+	"github.com/board-box/backend/internal/testdb"
+	"github.com/gin-gonic/gin"
+)
+
+const apiPrefix = "/api/v1"
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(testdb.Main(m))
+}
+
+// newTestApp собирает приложение так же, как NewApp, но поверх тестовой
+// базы и с заглушкой вместо LLM.
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+
+	a := &App{
+		cfg: &config.Config{
+			JWT: config.JWTConfig{
+				SecretKey:       "test-secret",
+				TokenDuration:   15 * time.Minute,
+				RefreshDuration: time.Hour,
+			},
+			Chat: config.ChatConfig{Provider: llm.ProviderStub},
+		},
+		db: testdb.Pool(t),
+	}
+
+	inits := []func(context.Context) error{
+		a.initAuth,
+		a.initService,
+		a.initMiddleware,
+		a.initRouter,
+	}
+	for _, fn := range inits {
+		if err := fn(context.Background()); err != nil {
+			t.Fatalf("init app: %v", err)
+		}
+	}
+
+	return a
+}
+
+// do выполняет запрос к роутеру. body сериализуется в JSON, если не nil.
+func (a *App) do(t *testing.T, method, path, token string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("encode body: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(method, apiPrefix+path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rec := httptest.NewRecorder()
+	a.r.ServeHTTP(rec, req)
+	return rec
+}
+
+// expect проверяет код ответа и разбирает тело в out, если out не nil.
+func expect(t *testing.T, rec *httptest.ResponseRecorder, status int, out any) {
+	t.Helper()
+
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d; body: %s", rec.Code, status, rec.Body.String())
+	}
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("decode body %q: %v", rec.Body.String(), err)
+		}
+	}
+}
+
+type testUser struct {
+	ID           int64
+	Email        string
+	Token        string
+	RefreshToken string
+}
+
+// registerUser регистрирует пользователя через API и входит под ним.
+func (a *App) registerUser(t *testing.T, name string) testUser {
+	t.Helper()
+
+	u := testUser{Email: name + "@example.com"}
+	creds := map[string]string{"username": name, "email": u.Email, "password": "password-" + name}
+	expect(t, a.do(t, http.MethodPost, "/user/register", "", creds), http.StatusCreated, nil)
+
+	err := a.db.QueryRow(context.Background(), "SELECT id FROM users WHERE email = $1", u.Email).Scan(&u.ID)
+	if err != nil {
+		t.Fatalf("find user: %v", err)
+	}
+
+	a.login(t, &u, creds["password"])
+	return u
+}
+
+func (a *App) login(t *testing.T, u *testUser, password string) {
+	t.Helper()
+
+	var tokens struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	rec := a.do(t, http.MethodPost, "/user/login", "", map[string]string{"email": u.Email, "password": password})
+	expect(t, rec, http.StatusOK, &tokens)
+
+	u.Token, u.RefreshToken = tokens.Token, tokens.RefreshToken
+}
+
+// registerAdmin регистрирует пользователя с ролью admin.
+func (a *App) registerAdmin(t *testing.T, name string) testUser {
+	t.Helper()
+
+	u := a.registerUser(t, name)
+	_, err := a.db.Exec(context.Background(), "INSERT INTO user_role (user_id, role) VALUES ($1, 'admin')", u.ID)
+	if err != nil {
+		t.Fatalf("grant admin: %v", err)
+	}
+
+	// Роли попадают в токен при входе
+	a.login(t, &u, "password-"+name)
+	return u
+}
+
+// seedGames наполняет каталог небольшим набором игр.
+func (a *App) seedGames(t *testing.T) map[string]int64 {
+	t.Helper()
+
+	games := []game.Game{
+		{Title: "Каркассон", Genre: "Стратегия", Person: "2-5", AvgTime: "30-45 мин", Age: "7+", Difficulty: "2", Description: "Строительство средневековых городов"},
+		{Title: "Кодовые имена", Genre: "Вечеринка", Person: "4-8", AvgTime: "15 мин", Age: "10+", Difficulty: "1", Description: "Командная игра в слова"},
+		{Title: "Серп", Genre: "Стратегия", Person: "1-5", AvgTime: "90-115 мин", Age: "14+", Difficulty: "3.5", Description: "Альтернативная Восточная Европа 1920-х"},
+		{Title: "Манчкин", Genre: "Карточная", Person: "3-6", AvgTime: "60 мин", Age: "12+", Difficulty: "1.5", Description: "Пародия на ролевые игры"},
+	}
+
+	ids := make(map[string]int64, len(games))
+	for _, g := range games {
+		id, err := a.gameSvc.CreateGame(context.Background(), g)
+		if err != nil {
+			t.Fatalf("seed game %q: %v", g.Title, err)
+		}
+		ids[g.Title] = id
+	}
+
+	return ids
+}
+
+func itoa(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
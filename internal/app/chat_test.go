@@ -0,0 +1,86 @@
+package app
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/board-box/backend/internal/service/chat"
+)
+
+type chatResponse struct {
+	ConversationID int64        `json:"conversation_id"`
+	Message        chat.Message `json:"message"`
+}
+
+func TestChatPersistsConversation(t *testing.T) {
+	a := newTestApp(t)
+	u := a.registerUser(t, "chatter")
+
+	var first chatResponse
+	expect(t, a.do(t, http.MethodPost, "/chat/", u.Token, map[string]any{"message": "Во что поиграть вдвоём?"}), http.StatusOK, &first)
+	if first.Message.Content != "Вы спросили: Во что поиграть вдвоём?" {
+		t.Errorf("answer = %q", first.Message.Content)
+	}
+
+	var second chatResponse
+	body := map[string]any{"conversation_id": first.ConversationID, "message": "А втроём?"}
+	expect(t, a.do(t, http.MethodPost, "/chat/", u.Token, body), http.StatusOK, &second)
+	if second.ConversationID != first.ConversationID {
+		t.Errorf("conversation_id = %d, want %d", second.ConversationID, first.ConversationID)
+	}
+
+	var conv chat.Conversation
+	expect(t, a.do(t, http.MethodGet, "/chat/conversations/"+itoa(first.ConversationID), u.Token, nil), http.StatusOK, &conv)
+	if len(conv.Messages) != 4 {
+		t.Fatalf("messages = %d, want 4", len(conv.Messages))
+	}
+	if conv.Title != "Во что поиграть вдвоём?" {
+		t.Errorf("title = %q", conv.Title)
+	}
+
+	other := a.registerUser(t, "eve")
+	expect(t, a.do(t, http.MethodGet, "/chat/conversations/"+itoa(first.ConversationID), other.Token, nil), http.StatusNotFound, nil)
+}
+
+func TestChatToolsReturnGameReferences(t *testing.T) {
+	a := newTestApp(t)
+	games := a.seedGames(t)
+	u := a.registerUser(t, "seeker")
+
+	// Заглушка вызывает инструмент по команде "/имя аргументы"
+	var resp chatResponse
+	body := map[string]any{"message": `/search_games {"genre":"Стратегия"}`}
+	expect(t, a.do(t, http.MethodPost, "/chat/", u.Token, body), http.StatusOK, &resp)
+
+	if !strings.Contains(resp.Message.Content, "Серп") {
+		t.Errorf("answer does not mention catalog games: %q", resp.Message.Content)
+	}
+	if want := []int64{games["Каркассон"], games["Серп"]}; !equalIDs(resp.Message.GameIDs, want) {
+		t.Errorf("game_ids = %v, want %v", resp.Message.GameIDs, want)
+	}
+
+	var conv chat.Conversation
+	expect(t, a.do(t, http.MethodGet, "/chat/conversations/"+itoa(resp.ConversationID), u.Token, nil), http.StatusOK, &conv)
+	if got := conv.Messages[len(conv.Messages)-1].GameIDs; !equalIDs(got, resp.Message.GameIDs) {
+		t.Errorf("stored game_ids = %v, want %v", got, resp.Message.GameIDs)
+	}
+}
+
+func TestChatStream(t *testing.T) {
+	a := newTestApp(t)
+	u := a.registerUser(t, "streamer")
+
+	rec := a.do(t, http.MethodPost, "/chat/stream", u.Token, map[string]any{"message": "Привет всем"})
+	expect(t, rec, http.StatusOK, nil)
+
+	out := rec.Body.String()
+	for _, event := range []string{"event:conversation", "event:delta", "event:done"} {
+		if !strings.Contains(out, event) {
+			t.Errorf("stream has no %q: %s", event, out)
+		}
+	}
+	if !strings.Contains(out, "Вы спросили: Привет всем") {
+		t.Errorf("done event does not carry the full answer: %s", out)
+	}
+}
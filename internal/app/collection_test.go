@@ -0,0 +1,472 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/board-box/backend/internal/service/collection"
+	"github.com/board-box/backend/internal/service/game"
+)
+
+func TestCollectionGames(t *testing.T) {
+	a := newTestApp(t)
+	games := a.seedGames(t)
+	u := a.registerUser(t, "collector")
+
+	var c collection.Collection
+	expect(t, a.do(t, http.MethodPost, "/collections/", u.Token, map[string]string{"name": "Полка"}), http.StatusCreated, &c)
+
+	base := "/collections/" + itoa(c.ID)
+	for _, title := range []string{"Серп", "Каркассон"} {
+		expect(t, a.do(t, http.MethodPost, base+"/games/"+itoa(games[title]), u.Token, nil), http.StatusNoContent, nil)
+	}
+	// Повторное добавление ничего не меняет
+	expect(t, a.do(t, http.MethodPost, base+"/games/"+itoa(games["Серп"]), u.Token, nil), http.StatusNoContent, nil)
+
+	var got collection.Collection
+	expect(t, a.do(t, http.MethodGet, base, u.Token, nil), http.StatusOK, &got)
+	if want := []int64{games["Серп"], games["Каркассон"]}; !equalIDs(entryGameIDs(got), want) {
+		t.Errorf("entries = %v, want games %v", entryGameIDs(got), want)
+	}
+
+	// Регрессия: addGameToCollection писал в таблицу collection вместо collection_game
+	var collections int
+	if err := a.db.QueryRow(t.Context(), "SELECT count(*) FROM collection").Scan(&collections); err != nil {
+		t.Fatal(err)
+	}
+	if collections != 1 {
+		t.Errorf("collection rows = %d, want 1", collections)
+	}
+
+	expect(t, a.do(t, http.MethodDelete, base+"/games/"+itoa(games["Серп"]), u.Token, nil), http.StatusNoContent, nil)
+	expect(t, a.do(t, http.MethodGet, base, u.Token, nil), http.StatusOK, &got)
+	if want := []int64{games["Каркассон"]}; !equalIDs(entryGameIDs(got), want) {
+		t.Errorf("entries after remove = %v, want games %v", entryGameIDs(got), want)
+	}
+}
+
+func TestCollectionAccess(t *testing.T) {
+	a := newTestApp(t)
+	games := a.seedGames(t)
+	owner := a.registerUser(t, "owner")
+	other := a.registerUser(t, "stranger")
+
+	var c collection.Collection
+	expect(t, a.do(t, http.MethodPost, "/collections/", owner.Token, map[string]string{"name": "Моё"}), http.StatusCreated, &c)
+	base := "/collections/" + itoa(c.ID)
+
+	expect(t, a.do(t, http.MethodGet, base, other.Token, nil), http.StatusNotFound, nil)
+	expect(t, a.do(t, http.MethodPost, base+"/games/"+itoa(games["Серп"]), other.Token, nil), http.StatusForbidden, nil)
+	expect(t, a.do(t, http.MethodDelete, base+"/games/"+itoa(games["Серп"]), other.Token, nil), http.StatusForbidden, nil)
+
+	// Несуществующая игра не попадает в коллекцию
+	expect(t, a.do(t, http.MethodPost, base+"/games/999999", owner.Token, nil), http.StatusNotFound, nil)
+}
+
+func TestListCollectionsPagination(t *testing.T) {
+	a := newTestApp(t)
+	u := a.registerUser(t, "many")
+
+	for _, name := range []string{"А", "Б", "В"} {
+		expect(t, a.do(t, http.MethodPost, "/collections/", u.Token, map[string]string{"name": name}), http.StatusCreated, nil)
+	}
+
+	var page struct {
+		Collections []collection.Collection `json:"collections"`
+		NextCursor  string                  `json:"next_cursor"`
+		Total       int64                   `json:"total"`
+	}
+	// Системные коллекции создаются сами, поэтому смотрим только свои
+	expect(t, a.do(t, http.MethodGet, "/collections/?type=custom&limit=2", u.Token, nil), http.StatusOK, &page)
+	if len(page.Collections) != 2 || page.NextCursor == "" || page.Total != 3 {
+		t.Fatalf("first page: %d collections, cursor %q, total %d", len(page.Collections), page.NextCursor, page.Total)
+	}
+
+	expect(t, a.do(t, http.MethodGet, "/collections/?type=custom&limit=2&cursor="+page.NextCursor, u.Token, nil), http.StatusOK, &page)
+	if len(page.Collections) != 1 || page.Collections[0].Name != "В" || page.NextCursor != "" {
+		t.Errorf("second page: %+v, cursor %q", page.Collections, page.NextCursor)
+	}
+}
+
+func TestListCollectionsGamePreview(t *testing.T) {
+	a := newTestApp(t)
+	u := a.registerUser(t, "hoarder")
+
+	var c collection.Collection
+	expect(t, a.do(t, http.MethodPost, "/collections/", u.Token, map[string]string{"name": "Всё подряд"}), http.StatusCreated, &c)
+
+	var ids []int64
+	for i := range 12 {
+		id, err := a.gameSvc.CreateGame(t.Context(), game.Game{Title: "Игра " + itoa(int64(i+1))})
+		if err != nil {
+			t.Fatalf("create game: %v", err)
+		}
+		expect(t, a.do(t, http.MethodPost, "/collections/"+itoa(c.ID)+"/games/"+itoa(id), u.Token, nil), http.StatusNoContent, nil)
+		ids = append(ids, id)
+	}
+
+	// В списке — число игр и только первые из них
+	var list struct {
+		Collections []collection.Collection `json:"collections"`
+	}
+	expect(t, a.do(t, http.MethodGet, "/collections/?type=custom", u.Token, nil), http.StatusOK, &list)
+	if len(list.Collections) != 1 || list.Collections[0].GameCount != 12 || !equalIDs(list.Collections[0].GameIDs, ids[:10]) {
+		t.Fatalf("collections = %+v", list.Collections)
+	}
+}
+
+// entryGameIDs возвращает ID игр из записей коллекции по порядку.
+func entryGameIDs(c collection.Collection) []int64 {
+	ids := make([]int64, 0, len(c.Entries))
+	for _, e := range c.Entries {
+		ids = append(ids, e.GameID)
+	}
+	return ids
+}
+
+func equalIDs(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCollectionSharing(t *testing.T) {
+	a := newTestApp(t)
+	games := a.seedGames(t)
+	owner := a.registerUser(t, "host")
+	other := a.registerUser(t, "guest")
+
+	var c collection.Collection
+	expect(t, a.do(t, http.MethodPost, "/collections/", owner.Token, map[string]string{"name": "Беру на игротеку"}), http.StatusCreated, &c)
+	if c.Visibility != collection.VisibilityPrivate || c.ShareSlug != nil {
+		t.Fatalf("new collection = %+v, want private without slug", c)
+	}
+
+	base := "/collections/" + itoa(c.ID)
+	for _, title := range []string{"Серп", "Каркассон"} {
+		expect(t, a.do(t, http.MethodPost, base+"/games/"+itoa(games[title]), owner.Token, nil), http.StatusNoContent, nil)
+	}
+
+	update := map[string]any{"name": c.Name, "visibility": "unlisted"}
+	expect(t, a.do(t, http.MethodPut, base, owner.Token, update), http.StatusOK, &c)
+	if c.Visibility != collection.VisibilityUnlisted || c.ShareSlug == nil || len(*c.ShareSlug) < 20 {
+		t.Fatalf("unlisted collection = %+v, want a share slug", c)
+	}
+	slug := *c.ShareSlug
+
+	// По ссылке коллекция открывается без авторизации, с играми целиком
+	var shared collection.SharedCollection
+	expect(t, a.do(t, http.MethodGet, "/shared/collections/"+slug, "", nil), http.StatusOK, &shared)
+	if shared.Owner != "host" || len(shared.Games) != 2 || shared.Games[0].Title != "Серп" || shared.Games[1].ID != games["Каркассон"] {
+		t.Fatalf("shared collection = %+v", shared)
+	}
+
+	// Переименование без visibility не меняет видимость и ссылку
+	expect(t, a.do(t, http.MethodPut, base, owner.Token, map[string]any{"name": "Игротека"}), http.StatusOK, &c)
+	if c.Visibility != collection.VisibilityUnlisted || c.ShareSlug == nil || *c.ShareSlug != slug {
+		t.Fatalf("renamed collection = %+v", c)
+	}
+
+	// Коллекции по ссылке нет в списке публичных
+	var list struct {
+		Collections []collection.Collection `json:"collections"`
+		Total       int64                   `json:"total"`
+	}
+	expect(t, a.do(t, http.MethodGet, "/shared/users/"+itoa(owner.ID)+"/collections", "", nil), http.StatusOK, &list)
+	if list.Total != 0 {
+		t.Fatalf("public collections = %+v, want none", list)
+	}
+
+	update["visibility"] = "public"
+	expect(t, a.do(t, http.MethodPut, base, owner.Token, update), http.StatusOK, &c)
+	expect(t, a.do(t, http.MethodGet, "/shared/users/"+itoa(owner.ID)+"/collections", "", nil), http.StatusOK, &list)
+	if list.Total != 1 || list.Collections[0].ShareSlug == nil || *list.Collections[0].ShareSlug != slug {
+		t.Fatalf("public collections = %+v", list)
+	}
+
+	// Новая ссылка заменяет старую
+	expect(t, a.do(t, http.MethodPost, base+"/share-slug", other.Token, nil), http.StatusForbidden, nil)
+	expect(t, a.do(t, http.MethodPost, base+"/share-slug", owner.Token, nil), http.StatusOK, &c)
+	if c.ShareSlug == nil || *c.ShareSlug == slug {
+		t.Fatalf("rotated collection = %+v", c)
+	}
+	expect(t, a.do(t, http.MethodGet, "/shared/collections/"+slug, "", nil), http.StatusNotFound, nil)
+	expect(t, a.do(t, http.MethodGet, "/shared/collections/"+*c.ShareSlug, "", nil), http.StatusOK, nil)
+
+	// Личная коллекция по ссылке не открывается, даже если ссылка была
+	update["visibility"] = "private"
+	expect(t, a.do(t, http.MethodPut, base, owner.Token, update), http.StatusOK, &c)
+	expect(t, a.do(t, http.MethodGet, "/shared/collections/"+*c.ShareSlug, "", nil), http.StatusNotFound, nil)
+
+	update["visibility"] = "secret"
+	expect(t, a.do(t, http.MethodPut, base, owner.Token, update), http.StatusBadRequest, nil)
+}
+
+func TestCollectionMembers(t *testing.T) {
+	a := newTestApp(t)
+	games := a.seedGames(t)
+	owner := a.registerUser(t, "host")
+	editor := a.registerUser(t, "helper")
+	viewer := a.registerUser(t, "watcher")
+
+	var c collection.Collection
+	expect(t, a.do(t, http.MethodPost, "/collections/", owner.Token, map[string]string{"name": "Клуб"}), http.StatusCreated, &c)
+	if c.Role != collection.RoleOwner {
+		t.Fatalf("new collection role = %q, want owner", c.Role)
+	}
+	base := "/collections/" + itoa(c.ID)
+
+	var m collection.Member
+	expect(t, a.do(t, http.MethodPost, base+"/members", owner.Token, map[string]string{"username": "helper", "role": "editor"}), http.StatusCreated, &m)
+	if m.UserID != editor.ID || m.Role != collection.RoleEditor || m.AcceptedAt != nil {
+		t.Fatalf("invited member = %+v", m)
+	}
+	expect(t, a.do(t, http.MethodPost, base+"/members", owner.Token, map[string]string{"email": "WATCHER@example.com"}), http.StatusCreated, &m)
+	if m.UserID != viewer.ID || m.Role != collection.RoleViewer {
+		t.Fatalf("invited by email = %+v, want viewer", m)
+	}
+	expect(t, a.do(t, http.MethodPost, base+"/members", owner.Token, map[string]string{"username": "helper"}), http.StatusConflict, nil)
+	expect(t, a.do(t, http.MethodPost, base+"/members", owner.Token, map[string]string{"username": "nobody"}), http.StatusNotFound, nil)
+
+	// Пока приглашение не принято, коллекция недоступна
+	expect(t, a.do(t, http.MethodGet, base, editor.Token, nil), http.StatusNotFound, nil)
+	expect(t, a.do(t, http.MethodPost, base+"/games/"+itoa(games["Серп"]), editor.Token, nil), http.StatusForbidden, nil)
+
+	var invites struct {
+		Invites []collection.Invite `json:"invites"`
+	}
+	expect(t, a.do(t, http.MethodGet, "/collections/invites", editor.Token, nil), http.StatusOK, &invites)
+	if len(invites.Invites) != 1 || invites.Invites[0].CollectionID != c.ID || invites.Invites[0].InvitedBy != "host" {
+		t.Fatalf("invites = %+v", invites)
+	}
+
+	var accepted collection.Collection
+	expect(t, a.do(t, http.MethodPost, "/collections/invites/"+itoa(c.ID)+"/accept", editor.Token, nil), http.StatusOK, &accepted)
+	if accepted.Role != collection.RoleEditor || accepted.UserID != owner.ID {
+		t.Fatalf("accepted collection = %+v", accepted)
+	}
+	expect(t, a.do(t, http.MethodPost, "/collections/invites/"+itoa(c.ID)+"/accept", editor.Token, nil), http.StatusNotFound, nil)
+	expect(t, a.do(t, http.MethodPost, "/collections/invites/"+itoa(c.ID)+"/accept", viewer.Token, nil), http.StatusOK, nil)
+
+	// Редактор меняет состав игр, но не саму коллекцию
+	expect(t, a.do(t, http.MethodPost, base+"/games/"+itoa(games["Серп"]), editor.Token, nil), http.StatusNoContent, nil)
+	expect(t, a.do(t, http.MethodPut, base, editor.Token, map[string]string{"name": "Мой клуб"}), http.StatusForbidden, nil)
+	expect(t, a.do(t, http.MethodPost, base+"/members", editor.Token, map[string]string{"username": "watcher"}), http.StatusForbidden, nil)
+
+	// Зритель только смотрит
+	expect(t, a.do(t, http.MethodGet, base, viewer.Token, nil), http.StatusOK, &c)
+	if c.Role != collection.RoleViewer || !equalIDs(entryGameIDs(c), []int64{games["Серп"]}) {
+		t.Fatalf("collection for viewer = %+v", c)
+	}
+	expect(t, a.do(t, http.MethodDelete, base+"/games/"+itoa(games["Серп"]), viewer.Token, nil), http.StatusForbidden, nil)
+	expect(t, a.do(t, http.MethodDelete, base, viewer.Token, nil), http.StatusForbidden, nil)
+
+	// Чужая коллекция есть в списке коллекций участника
+	var list struct {
+		Collections []collection.Collection `json:"collections"`
+		Total       int64                   `json:"total"`
+	}
+	expect(t, a.do(t, http.MethodGet, "/collections/?type=custom", viewer.Token, nil), http.StatusOK, &list)
+	if list.Total != 1 || list.Collections[0].ID != c.ID || list.Collections[0].Role != collection.RoleViewer {
+		t.Fatalf("viewer collections = %+v", list)
+	}
+
+	// Повышенный до редактора зритель может добавлять игры
+	expect(t, a.do(t, http.MethodPatch, base+"/members/"+itoa(viewer.ID), owner.Token, map[string]string{"role": "editor"}), http.StatusOK, &m)
+	if m.Role != collection.RoleEditor {
+		t.Fatalf("updated member = %+v", m)
+	}
+	expect(t, a.do(t, http.MethodPost, base+"/games/"+itoa(games["Манчкин"]), viewer.Token, nil), http.StatusNoContent, nil)
+
+	// Создателя нельзя исключить или понизить даже другому владельцу
+	expect(t, a.do(t, http.MethodPatch, base+"/members/"+itoa(editor.ID), owner.Token, map[string]string{"role": "owner"}), http.StatusOK, nil)
+	expect(t, a.do(t, http.MethodPatch, base+"/members/"+itoa(owner.ID), editor.Token, map[string]string{"role": "viewer"}), http.StatusForbidden, nil)
+	expect(t, a.do(t, http.MethodDelete, base+"/members/"+itoa(owner.ID), editor.Token, nil), http.StatusForbidden, nil)
+
+	// Участник может выйти сам
+	expect(t, a.do(t, http.MethodDelete, base+"/members/"+itoa(viewer.ID), viewer.Token, nil), http.StatusNoContent, nil)
+	expect(t, a.do(t, http.MethodGet, base, viewer.Token, nil), http.StatusNotFound, nil)
+
+	var members struct {
+		Members []collection.Member `json:"members"`
+	}
+	expect(t, a.do(t, http.MethodGet, base+"/members", owner.Token, nil), http.StatusOK, &members)
+	if len(members.Members) != 2 {
+		t.Fatalf("members = %+v, want host and helper", members)
+	}
+	for _, member := range members.Members {
+		if member.Role != collection.RoleOwner || member.AcceptedAt == nil {
+			t.Errorf("member = %+v, want accepted owner", member)
+		}
+	}
+	expect(t, a.do(t, http.MethodGet, base+"/members", viewer.Token, nil), http.StatusNotFound, nil)
+
+	// Отклонённое приглашение пропадает
+	expect(t, a.do(t, http.MethodPost, base+"/members", editor.Token, map[string]string{"username": "watcher"}), http.StatusCreated, nil)
+	expect(t, a.do(t, http.MethodDelete, "/collections/invites/"+itoa(c.ID), viewer.Token, nil), http.StatusNoContent, nil)
+	expect(t, a.do(t, http.MethodGet, "/collections/invites", viewer.Token, nil), http.StatusOK, &invites)
+	if len(invites.Invites) != 0 {
+		t.Fatalf("invites after decline = %+v", invites)
+	}
+
+	expect(t, a.do(t, http.MethodPatch, base+"/members/"+itoa(editor.ID), owner.Token, map[string]string{"role": "admin"}), http.StatusBadRequest, nil)
+}
+
+func TestCollectionEntries(t *testing.T) {
+	a := newTestApp(t)
+	games := a.seedGames(t)
+	owner := a.registerUser(t, "host")
+	viewer := a.registerUser(t, "watcher")
+
+	var c collection.Collection
+	expect(t, a.do(t, http.MethodPost, "/collections/", owner.Token, map[string]string{"name": "Полка"}), http.StatusCreated, &c)
+	base := "/collections/" + itoa(c.ID)
+	for _, title := range []string{"Серп", "Каркассон", "Манчкин"} {
+		expect(t, a.do(t, http.MethodPost, base+"/games/"+itoa(games[title]), owner.Token, nil), http.StatusNoContent, nil)
+	}
+
+	var entry collection.Entry
+	update := map[string]any{
+		"note":          "  Не хватает жетона  ",
+		"condition":     "good",
+		"purchase_date": "2024-05-01",
+		"price":         2490.5,
+		"lent_to":       "Миша",
+		"expansions":    []string{"Охотники и собиратели", " "},
+	}
+	expect(t, a.do(t, http.MethodPut, base+"/games/"+itoa(games["Каркассон"]), owner.Token, update), http.StatusOK, &entry)
+	if entry.Note == nil || *entry.Note != "Не хватает жетона" || entry.Condition == nil || *entry.Condition != collection.ConditionGood ||
+		entry.Price == nil || *entry.Price != 2490.5 || !entry.LentOut || entry.LentTo == nil ||
+		len(entry.Expansions) != 1 || entry.Position != 2 || entry.Game.Title != "Каркассон" {
+		t.Fatalf("entry = %+v", entry)
+	}
+	if entry.PurchaseDate == nil || entry.PurchaseDate.Format("2006-01-02") != "2024-05-01" {
+		t.Errorf("purchase_date = %v", entry.PurchaseDate)
+	}
+
+	expect(t, a.do(t, http.MethodPut, base+"/games/"+itoa(games["Кодовые имена"]), owner.Token, update), http.StatusNotFound, nil)
+	update["condition"] = "broken"
+	expect(t, a.do(t, http.MethodPut, base+"/games/"+itoa(games["Каркассон"]), owner.Token, update), http.StatusBadRequest, nil)
+
+	order := []int64{games["Манчкин"], games["Серп"], games["Каркассон"]}
+	expect(t, a.do(t, http.MethodPut, base+"/order", owner.Token, map[string]any{"game_ids": order}), http.StatusOK, &c)
+	if !equalIDs(entryGameIDs(c), order) {
+		t.Fatalf("entries after reorder = %v, want %v", entryGameIDs(c), order)
+	}
+	// Данные экземпляра не теряются при смене порядка
+	if last := c.Entries[2]; last.Note == nil || last.Position != 3 || last.Game.ID != games["Каркассон"] {
+		t.Errorf("moved entry = %+v", last)
+	}
+
+	// Новая игра встаёт в конец
+	expect(t, a.do(t, http.MethodPost, base+"/games/"+itoa(games["Кодовые имена"]), owner.Token, nil), http.StatusNoContent, nil)
+	var page struct {
+		GameIDs []int64 `json:"game_ids"`
+	}
+	expect(t, a.do(t, http.MethodGet, base+"/games", owner.Token, nil), http.StatusOK, &page)
+	if want := []int64{order[0], order[1], order[2], games["Кодовые имена"]}; !equalIDs(page.GameIDs, want) {
+		t.Errorf("game_ids = %v, want %v", page.GameIDs, want)
+	}
+
+	// Порядок должен перечислять каждую игру ровно один раз
+	for _, bad := range [][]int64{order, {order[0], order[0], order[1], games["Кодовые имена"]}} {
+		expect(t, a.do(t, http.MethodPut, base+"/order", owner.Token, map[string]any{"game_ids": bad}), http.StatusBadRequest, nil)
+	}
+
+	expect(t, a.do(t, http.MethodPost, base+"/members", owner.Token, map[string]string{"username": "watcher"}), http.StatusCreated, nil)
+	expect(t, a.do(t, http.MethodPost, "/collections/invites/"+itoa(c.ID)+"/accept", viewer.Token, nil), http.StatusOK, nil)
+	expect(t, a.do(t, http.MethodPut, base+"/order", viewer.Token, map[string]any{"game_ids": order}), http.StatusForbidden, nil)
+	expect(t, a.do(t, http.MethodPut, base+"/games/"+itoa(games["Серп"]), viewer.Token, map[string]any{}), http.StatusForbidden, nil)
+}
+
+func TestSystemCollections(t *testing.T) {
+	a := newTestApp(t)
+	games := a.seedGames(t)
+	u := a.registerUser(t, "collector")
+	other := a.registerUser(t, "neighbour")
+
+	var list struct {
+		Collections []collection.Collection `json:"collections"`
+		Total       int64                   `json:"total"`
+	}
+	// Системные коллекции создаются при регистрации, а не при чтении списка
+	var created int
+	err := a.db.QueryRow(t.Context(), "SELECT count(*) FROM collection WHERE user_id = $1 AND type <> 'custom'", u.ID).Scan(&created)
+	if err != nil || created != 4 {
+		t.Fatalf("system collections after registration = %d, %v; want 4", created, err)
+	}
+	expect(t, a.do(t, http.MethodGet, "/collections/", u.Token, nil), http.StatusOK, &list)
+	if list.Total != 4 {
+		t.Fatalf("system collections = %+v, want 4", list)
+	}
+	byType := func(typ string) collection.Collection {
+		t.Helper()
+
+		expect(t, a.do(t, http.MethodGet, "/collections/?type="+typ, u.Token, nil), http.StatusOK, &list)
+		if list.Total != 1 || string(list.Collections[0].Type) != typ || list.Collections[0].Role != collection.RoleOwner {
+			t.Fatalf("collections of type %s = %+v", typ, list)
+		}
+		return list.Collections[0]
+	}
+	wishlist, owned := byType("wishlist"), byType("owned")
+	expect(t, a.do(t, http.MethodGet, "/collections/?type=favourite", u.Token, nil), http.StatusBadRequest, nil)
+
+	wishBase := "/collections/" + itoa(wishlist.ID)
+	for _, title := range []string{"Серп", "Каркассон"} {
+		expect(t, a.do(t, http.MethodPost, wishBase+"/games/"+itoa(games[title]), u.Token, nil), http.StatusNoContent, nil)
+	}
+	var entry collection.Entry
+	update := map[string]any{"priority": 1, "note": "На день рождения"}
+	expect(t, a.do(t, http.MethodPut, wishBase+"/games/"+itoa(games["Серп"]), u.Token, update), http.StatusOK, &entry)
+	if entry.Priority == nil || *entry.Priority != 1 {
+		t.Fatalf("wishlist entry = %+v, want priority 1", entry)
+	}
+	expect(t, a.do(t, http.MethodPut, wishBase+"/games/"+itoa(games["Серп"]), u.Token, map[string]any{"priority": 9}), http.StatusBadRequest, nil)
+
+	// Купленная игра переезжает в свою коллекцию вместе с заметкой, но без приоритета
+	var moved collection.Collection
+	move := "/games/" + itoa(games["Серп"]) + "/move"
+	expect(t, a.do(t, http.MethodPost, wishBase+move, u.Token, map[string]any{"to_type": "owned"}), http.StatusOK, &moved)
+	if moved.ID != owned.ID || len(moved.Entries) != 1 || moved.Entries[0].GameID != games["Серп"] ||
+		moved.Entries[0].Note == nil || moved.Entries[0].Priority != nil {
+		t.Fatalf("owned after move = %+v", moved)
+	}
+	var c collection.Collection
+	expect(t, a.do(t, http.MethodGet, wishBase, u.Token, nil), http.StatusOK, &c)
+	if !equalIDs(entryGameIDs(c), []int64{games["Каркассон"]}) {
+		t.Fatalf("wishlist after move = %v", entryGameIDs(c))
+	}
+
+	ownedBase := "/collections/" + itoa(owned.ID)
+	expect(t, a.do(t, http.MethodPut, ownedBase+"/games/"+itoa(games["Серп"]), u.Token, map[string]any{"priority": 2}), http.StatusBadRequest, nil)
+	expect(t, a.do(t, http.MethodPost, wishBase+move, u.Token, map[string]any{"to_type": "owned"}), http.StatusNotFound, nil)
+	expect(t, a.do(t, http.MethodPost, ownedBase+move, u.Token, map[string]any{"to_collection_id": owned.ID}), http.StatusBadRequest, nil)
+	expect(t, a.do(t, http.MethodPost, ownedBase+move, u.Token, map[string]any{}), http.StatusBadRequest, nil)
+	expect(t, a.do(t, http.MethodPost, ownedBase+move, u.Token, map[string]any{"to_type": "owned", "to_collection_id": wishlist.ID}), http.StatusBadRequest, nil)
+
+	// В чужую коллекцию перенести нельзя, и игра остаётся на месте
+	var foreign collection.Collection
+	expect(t, a.do(t, http.MethodPost, "/collections/", other.Token, map[string]string{"name": "Чужая"}), http.StatusCreated, &foreign)
+	expect(t, a.do(t, http.MethodPost, ownedBase+move, u.Token, map[string]any{"to_collection_id": foreign.ID}), http.StatusForbidden, nil)
+	expect(t, a.do(t, http.MethodGet, ownedBase, u.Token, nil), http.StatusOK, &c)
+	if !equalIDs(entryGameIDs(c), []int64{games["Серп"]}) {
+		t.Fatalf("owned after forbidden move = %v", entryGameIDs(c))
+	}
+
+	// Если игра уже есть в цели, перенос отклоняется, а запись с заметкой остаётся
+	expect(t, a.do(t, http.MethodPost, wishBase+"/games/"+itoa(games["Серп"]), u.Token, nil), http.StatusNoContent, nil)
+	expect(t, a.do(t, http.MethodPut, wishBase+"/games/"+itoa(games["Серп"]), u.Token, map[string]any{"note": "Подарить"}), http.StatusOK, nil)
+	expect(t, a.do(t, http.MethodPost, wishBase+move, u.Token, map[string]any{"to_type": "owned"}), http.StatusConflict, nil)
+	expect(t, a.do(t, http.MethodGet, wishBase, u.Token, nil), http.StatusOK, &c)
+	if len(c.Entries) != 2 || c.Entries[1].GameID != games["Серп"] || c.Entries[1].Note == nil || *c.Entries[1].Note != "Подарить" {
+		t.Fatalf("wishlist after conflicting move = %+v", c.Entries)
+	}
+
+	expect(t, a.do(t, http.MethodDelete, wishBase, u.Token, nil), http.StatusForbidden, nil)
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/board-box/backend/internal/service/collection"
+	"github.com/board-box/backend/internal/service/collectionimport"
+)
+
+const bggCollectionCSV = `objectname,objectid,own,wishlist
+Каркассон,822,1,0
+Серп,169786,1,0
+Каркасон,1,1,0
+Twilight Imperium,233078,0,1
+Неизвестная игра,2,1,0
+`
+
+func TestCollectionImport(t *testing.T) {
+	a := newTestApp(t)
+	games := a.seedGames(t)
+	u := a.registerUser(t, "migrant")
+
+	const path = "/collections/import"
+
+	// Без confirm возвращается только отчёт
+	var report collectionimport.Report
+	expect(t, a.upload(t, path, u.Token, "collection.csv", bggCollectionCSV, nil), http.StatusOK, &report)
+	if report.Confirmed || report.Name != "collection" || report.Total != 5 || report.Skipped != 1 || report.Matched < 2 {
+		t.Fatalf("report = %+v", report)
+	}
+	for _, row := range []int{2, 3} {
+		if e := report.Entries[row-2]; e.Status != collectionimport.StatusMatched || e.MatchedBy != collectionimport.MatchByTitle {
+			t.Errorf("row %d = %+v, want exact title match", row, e)
+		}
+	}
+	// Опечатка находится по похожему названию
+	typo := report.Entries[2]
+	if typo.Status == collectionimport.StatusUnmatched ||
+		(typo.Game == nil || typo.Game.ID != games["Каркассон"]) && (len(typo.Candidates) == 0 || typo.Candidates[0].ID != games["Каркассон"]) {
+		t.Errorf("typo entry = %+v", typo)
+	}
+	if e := report.Entries[4]; e.Status != collectionimport.StatusUnmatched {
+		t.Errorf("unknown game entry = %+v", e)
+	}
+
+	var list struct {
+		Total int64 `json:"total"`
+	}
+	expect(t, a.do(t, http.MethodGet, "/collections/?type=custom", u.Token, nil), http.StatusOK, &list)
+	if list.Total != 0 {
+		t.Fatalf("report created %d collections", list.Total)
+	}
+
+	// Для ненайденной записи выбираем игру сами
+	fields := map[string]string{"confirm": "true", "name": "С BGG", "choose": "6=" + itoa(games["Манчкин"])}
+	expect(t, a.upload(t, path, u.Token, "collection.csv", bggCollectionCSV, fields), http.StatusCreated, &report)
+	if !report.Confirmed || report.CollectionID == 0 {
+		t.Fatalf("confirmed report = %+v", report)
+	}
+
+	var c collection.Collection
+	expect(t, a.do(t, http.MethodGet, "/collections/"+itoa(report.CollectionID), u.Token, nil), http.StatusOK, &c)
+	want := []int64{games["Каркассон"], games["Серп"], games["Манчкин"]}
+	if c.Name != "С BGG" || !equalIDs(entryGameIDs(c), want) {
+		t.Errorf("collection = %+v, want games %v", c, want)
+	}
+
+	// Выгрузка BoardBox импортируется обратно по ID игр
+	rec := a.do(t, http.MethodGet, "/collections/"+itoa(c.ID)+"/export", u.Token, nil)
+	expect(t, rec, http.StatusOK, nil)
+	fields = map[string]string{"confirm": "true"}
+	expect(t, a.upload(t, path, u.Token, "export.json", rec.Body.String(), fields), http.StatusCreated, &report)
+	if report.Name != "С BGG" || report.Matched != 3 || report.Entries[0].MatchedBy != collectionimport.MatchByID {
+		t.Errorf("round trip report = %+v", report)
+	}
+
+	expect(t, a.upload(t, path, u.Token, "collection.csv", bggCollectionCSV, map[string]string{"choose": "42=1"}), http.StatusBadRequest, nil)
+	expect(t, a.upload(t, path, u.Token, "collection.txt", bggCollectionCSV, nil), http.StatusBadRequest, nil)
+	expect(t, a.upload(t, path, u.Token, "empty.csv", "title\nНеизвестная игра\n", map[string]string{"confirm": "true"}), http.StatusUnprocessableEntity, nil)
+	expect(t, a.upload(t, path, "", "collection.csv", bggCollectionCSV, nil), http.StatusUnauthorized, nil)
+}
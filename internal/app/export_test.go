@@ -0,0 +1,101 @@
+package app
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/board-box/backend/internal/service/collection"
+	"github.com/board-box/backend/internal/service/gameimport"
+)
+
+type exportRow struct {
+	CollectionID int64  `json:"collection_id"`
+	Collection   string `json:"collection"`
+	GameID       int64  `json:"game_id"`
+	Title        string `json:"title"`
+}
+
+func TestCollectionExport(t *testing.T) {
+	a := newTestApp(t)
+	games := a.seedGames(t)
+	owner := a.registerUser(t, "owner")
+	other := a.registerUser(t, "stranger")
+
+	shelves := map[string][]string{
+		"Полка":   {"Серп", "Каркассон"},
+		"Вишлист": {"Манчкин"},
+	}
+	ids := make(map[string]int64, len(shelves))
+	for name, titles := range shelves {
+		var c collection.Collection
+		expect(t, a.do(t, http.MethodPost, "/collections/", owner.Token, map[string]string{"name": name}), http.StatusCreated, &c)
+		ids[name] = c.ID
+		for _, title := range titles {
+			expect(t, a.do(t, http.MethodPost, "/collections/"+itoa(c.ID)+"/games/"+itoa(games[title]), owner.Token, nil), http.StatusNoContent, nil)
+		}
+	}
+	// Пустая коллекция в выгрузку не попадает
+	expect(t, a.do(t, http.MethodPost, "/collections/", owner.Token, map[string]string{"name": "Пусто"}), http.StatusCreated, nil)
+
+	var rows []exportRow
+	rec := a.do(t, http.MethodGet, "/collections/"+itoa(ids["Полка"])+"/export", owner.Token, nil)
+	expect(t, rec, http.StatusOK, &rows)
+	if len(rows) != 2 || rows[0].Title != "Серп" || rows[1].GameID != games["Каркассон"] || rows[0].Collection != "Полка" {
+		t.Fatalf("collection export = %+v", rows)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); !strings.Contains(cd, "collection-"+itoa(ids["Полка"])+".json") {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+
+	rec = a.do(t, http.MethodGet, "/collections/export?format=csv", owner.Token, nil)
+	expect(t, rec, http.StatusOK, nil)
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(rec.Body.String(), "\ufeff"))).ReadAll()
+	if err != nil {
+		t.Fatalf("read CSV: %v", err)
+	}
+	if len(records) != 4 || records[0][0] != "collection_id" || records[1][1] != "Вишлист" {
+		t.Fatalf("CSV export = %q", records)
+	}
+
+	// Чужие коллекции не выгружаются
+	expect(t, a.do(t, http.MethodGet, "/collections/"+itoa(ids["Полка"])+"/export", other.Token, nil), http.StatusNotFound, nil)
+	expect(t, a.do(t, http.MethodGet, "/collections/export", other.Token, nil), http.StatusOK, &rows)
+	if len(rows) != 0 {
+		t.Errorf("stranger export = %+v", rows)
+	}
+
+	expect(t, a.do(t, http.MethodGet, "/collections/export?format=xlsx", owner.Token, nil), http.StatusBadRequest, nil)
+	expect(t, a.do(t, http.MethodGet, "/collections/export", "", nil), http.StatusUnauthorized, nil)
+}
+
+func TestCatalogExport(t *testing.T) {
+	a := newTestApp(t)
+	games := a.seedGames(t)
+	admin := a.registerAdmin(t, "admin")
+	user := a.registerUser(t, "alice")
+
+	expect(t, a.do(t, http.MethodGet, "/admin/games/export", user.Token, nil), http.StatusForbidden, nil)
+
+	rec := a.do(t, http.MethodGet, "/admin/games/export?format=csv", admin.Token, nil)
+	expect(t, rec, http.StatusOK, nil)
+
+	// Выгрузка каталога читается импортом без настройки колонок
+	report, err := a.gameImportSvc.Import(t.Context(), strings.NewReader(rec.Body.String()), gameimport.Options{
+		Format: gameimport.FormatCSV,
+		DryRun: true,
+	})
+	if err != nil {
+		t.Fatalf("import export: %v", err)
+	}
+	if report.Total != len(games) || report.Failed != 0 {
+		t.Fatalf("import report = %+v", report)
+	}
+	if len(report.IgnoredColumns) != 1 || report.IgnoredColumns[0] != "id" {
+		t.Errorf("ignored columns = %v, want [id]", report.IgnoredColumns)
+	}
+}
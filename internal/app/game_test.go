@@ -0,0 +1,177 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/board-box/backend/internal/service/game"
+)
+
+type listGamesResponse struct {
+	Games      []game.Game `json:"games"`
+	Facets     game.Facets `json:"facets"`
+	NextCursor string      `json:"next_cursor"`
+	Total      int64       `json:"total"`
+}
+
+func TestListGamesFilters(t *testing.T) {
+	a := newTestApp(t)
+	a.seedGames(t)
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{name: "all", query: "", want: []string{"Каркассон", "Кодовые имена", "Манчкин", "Серп"}},
+		{name: "genre", query: "genre=Стратегия", want: []string{"Каркассон", "Серп"}},
+		{name: "players and time", query: "players=4&max_time=60", want: []string{"Каркассон", "Кодовые имена", "Манчкин"}},
+		{name: "player age", query: "player_age=10", want: []string{"Каркассон", "Кодовые имена"}},
+		{name: "legacy lists", query: "person=2-5&person=4-8&avg_time=15%20мин", want: []string{"Кодовые имена"}},
+		{name: "legacy age", query: "age=12%2B&age=14%2B", want: []string{"Манчкин", "Серп"}},
+		{name: "complexity", query: "complexity_min=3", want: []string{"Серп"}},
+		{name: "full text", query: "q=города", want: []string{"Каркассон"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp listGamesResponse
+			expect(t, a.do(t, http.MethodGet, "/games/?"+tt.query, "", nil), http.StatusOK, &resp)
+
+			var got []string
+			for _, g := range resp.Games {
+				got = append(got, g.Title)
+			}
+			if !equalStrings(got, tt.want) {
+				t.Errorf("games = %v, want %v", got, tt.want)
+			}
+			if resp.Total != int64(len(tt.want)) {
+				t.Errorf("total = %d, want %d", resp.Total, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestListGamesFacets(t *testing.T) {
+	a := newTestApp(t)
+	a.seedGames(t)
+
+	var resp listGamesResponse
+	expect(t, a.do(t, http.MethodGet, "/games/?genre=Стратегия", "", nil), http.StatusOK, &resp)
+
+	// Фильтр по жанру не сужает фасет жанров
+	counts := map[string]int64{}
+	for _, v := range resp.Facets[game.FacetGenre] {
+		counts[v.Value] = v.Count
+	}
+	want := map[string]int64{"Стратегия": 2, "Вечеринка": 1, "Карточная": 1}
+	for genre, n := range want {
+		if counts[genre] != n {
+			t.Errorf("genre facet %q = %d, want %d", genre, counts[genre], n)
+		}
+	}
+
+	// Каждый фасет считается без своего параметра, но с остальными:
+	// строковый complexity не сужается complexity=2, complexity_level — complexity_min=3
+	var both listGamesResponse
+	expect(t, a.do(t, http.MethodGet, "/games/?complexity=2&complexity_min=3", "", nil), http.StatusOK, &both)
+	if got, want := both.Facets[game.FacetComplexity], []game.FacetValue{{Value: "3.5", Count: 1}}; !equalFacet(got, want) {
+		t.Errorf("complexity facet = %v, want %v", got, want)
+	}
+	if got, want := both.Facets[game.FacetComplexityLevel], []game.FacetValue{{Value: "2", Count: 1}}; !equalFacet(got, want) {
+		t.Errorf("complexity_level facet = %v, want %v", got, want)
+	}
+}
+
+func equalFacet(a, b []game.FacetValue) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestListGamesCursorPagination(t *testing.T) {
+	a := newTestApp(t)
+	a.seedGames(t)
+
+	var titles []string
+	cursor := ""
+	for page := 0; ; page++ {
+		if page > 4 {
+			t.Fatal("pagination does not terminate")
+		}
+
+		path := "/games/?limit=3"
+		if cursor != "" {
+			path += "&cursor=" + cursor
+		}
+
+		var resp listGamesResponse
+		expect(t, a.do(t, http.MethodGet, path, "", nil), http.StatusOK, &resp)
+		for _, g := range resp.Games {
+			titles = append(titles, g.Title)
+		}
+
+		if resp.NextCursor == "" {
+			break
+		}
+		cursor = resp.NextCursor
+	}
+
+	want := []string{"Каркассон", "Кодовые имена", "Манчкин", "Серп"}
+	if !equalStrings(titles, want) {
+		t.Errorf("titles = %v, want %v", titles, want)
+	}
+
+	expect(t, a.do(t, http.MethodGet, "/games/?cursor=garbage", "", nil), http.StatusBadRequest, nil)
+}
+
+func TestGameAdminCRUD(t *testing.T) {
+	a := newTestApp(t)
+	user := a.registerUser(t, "player")
+	admin := a.registerAdmin(t, "admin")
+
+	body := map[string]any{"title": "Азул", "genre": "Абстрактная", "person": "2-4", "avg_time": "30-45 мин"}
+
+	expect(t, a.do(t, http.MethodPost, "/games/", "", body), http.StatusUnauthorized, nil)
+	expect(t, a.do(t, http.MethodPost, "/games/", user.Token, body), http.StatusForbidden, nil)
+
+	var created game.Game
+	expect(t, a.do(t, http.MethodPost, "/games/", admin.Token, body), http.StatusCreated, &created)
+	if created.MinPlayers == nil || *created.MinPlayers != 2 || created.MaxPlayers == nil || *created.MaxPlayers != 4 {
+		t.Errorf("players = %v-%v, want 2-4", created.MinPlayers, created.MaxPlayers)
+	}
+
+	path := "/games/" + itoa(created.ID)
+
+	var patched game.Game
+	expect(t, a.do(t, http.MethodPatch, path, admin.Token, map[string]any{"max_players": 6}), http.StatusOK, &patched)
+	if patched.Person != "2-6" {
+		t.Errorf("person = %q, want %q", patched.Person, "2-6")
+	}
+	if patched.Genre != "Абстрактная" {
+		t.Errorf("patch reset genre to %q", patched.Genre)
+	}
+
+	expect(t, a.do(t, http.MethodPost, "/games/", admin.Token, map[string]any{"genre": "без названия"}), http.StatusBadRequest, nil)
+
+	expect(t, a.do(t, http.MethodDelete, path, admin.Token, nil), http.StatusNoContent, nil)
+	expect(t, a.do(t, http.MethodGet, path, "", nil), http.StatusNotFound, nil)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/board-box/backend/internal/service/gameimport"
+)
+
+// upload отправляет файл multipart-формой.
+func (a *App) upload(t *testing.T, path, token, filename, content string, fields map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for k, v := range fields {
+		if err := w.WriteField(k, v); err != nil {
+			t.Fatalf("write field: %v", err)
+		}
+	}
+	if filename != "" {
+		part, err := w.CreateFormFile("file", filename)
+		if err != nil {
+			t.Fatalf("create form file: %v", err)
+		}
+		_, _ = part.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close multipart: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, apiPrefix+path, &buf)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rec := httptest.NewRecorder()
+	a.r.ServeHTTP(rec, req)
+	return rec
+}
+
+const catalogCSV = `Name,objectid,Players,Playing Time,Weight,Notes
+Каркассон,822,2-5,45,1.9,классика
+Колонизаторы,13,3-4,120,2.3,
+Сломанная,abc,2,30,,
+`
+
+func TestGameImport(t *testing.T) {
+	a := newTestApp(t)
+	admin := a.registerAdmin(t, "admin")
+	user := a.registerUser(t, "alice")
+
+	const path = "/admin/games/import"
+
+	expect(t, a.upload(t, path, user.Token, "games.csv", catalogCSV, nil), http.StatusForbidden, nil)
+	expect(t, a.upload(t, path, admin.Token, "", "", nil), http.StatusBadRequest, nil)
+	expect(t, a.upload(t, path, admin.Token, "games.txt", catalogCSV, nil), http.StatusBadRequest, nil)
+	expect(t, a.upload(t, path, admin.Token, "games.csv", catalogCSV, map[string]string{"map": "Name=name"}), http.StatusBadRequest, nil)
+
+	// Пробный запуск ничего не записывает
+	var report gameimport.Report
+	expect(t, a.upload(t, path, admin.Token, "games.csv", catalogCSV, map[string]string{"dry_run": "true"}), http.StatusOK, &report)
+	if !report.DryRun || report.Created != 2 || report.Failed != 1 || len(report.IgnoredColumns) != 1 {
+		t.Fatalf("dry run report = %+v", report)
+	}
+
+	var list listGamesResponse
+	expect(t, a.do(t, http.MethodGet, "/games/", "", nil), http.StatusOK, &list)
+	if list.Total != 0 {
+		t.Fatalf("dry run created %d games", list.Total)
+	}
+
+	expect(t, a.upload(t, path, admin.Token, "games.csv", catalogCSV, nil), http.StatusOK, &report)
+	if report.Created != 2 || report.Updated != 0 || report.Failed != 1 || report.Rows[2].Row != 4 {
+		t.Fatalf("report = %+v", report)
+	}
+
+	catan, err := a.gameSvc.GetGame(context.Background(), report.Rows[1].GameID)
+	if err != nil {
+		t.Fatalf("get imported game: %v", err)
+	}
+	if catan.Title != "Колонизаторы" || *catan.ExternalID != "bgg:13" || *catan.MinPlayers != 3 || *catan.MaxPlayTime != 120 || catan.Difficulty == "" {
+		t.Fatalf("imported game = %+v", catan)
+	}
+
+	// Повторная загрузка обновляет игры по bgg_id, а не создаёт дубликаты
+	const renamed = "Name,objectid\nCatan,13\n"
+	expect(t, a.upload(t, path, admin.Token, "games.csv", renamed, map[string]string{"format": "csv"}), http.StatusOK, &report)
+	if report.Created != 0 || report.Updated != 1 || report.Rows[0].GameID != catan.ID {
+		t.Fatalf("re-import report = %+v", report)
+	}
+
+	// Колонок, которых нет в файле, повторная загрузка не трогает
+	renamedCatan, err := a.gameSvc.GetGame(context.Background(), catan.ID)
+	if err != nil {
+		t.Fatalf("get re-imported game: %v", err)
+	}
+	if renamedCatan.Title != "Catan" || renamedCatan.Person != catan.Person || *renamedCatan.MinPlayers != 3 ||
+		*renamedCatan.MaxPlayTime != 120 || renamedCatan.Difficulty != catan.Difficulty || renamedCatan.DifficultyLevel == nil {
+		t.Fatalf("re-imported game = %+v, was %+v", renamedCatan, catan)
+	}
+
+	expect(t, a.do(t, http.MethodGet, "/games/", "", nil), http.StatusOK, &list)
+	if list.Total != 2 {
+		t.Fatalf("total = %d, want 2", list.Total)
+	}
+
+	const jsonl = `{"title": "Серп", "external_id": "hobbygames:serp", "min_players": 1, "max_players": 5}`
+	expect(t, a.upload(t, path, admin.Token, "games.jsonl", jsonl, nil), http.StatusOK, &report)
+	if report.Format != gameimport.FormatJSONL || report.Created != 1 {
+		t.Fatalf("jsonl report = %+v", report)
+	}
+
+	// Запись, которую отвергла база, откатывается одна, остальные сохраняются
+	if _, err = a.db.Exec(context.Background(), "ALTER TABLE game ADD CONSTRAINT test_rejected_title CHECK (title <> 'Отвергнутая') NOT VALID"); err != nil {
+		t.Fatalf("add constraint: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = a.db.Exec(context.Background(), "ALTER TABLE game DROP CONSTRAINT IF EXISTS test_rejected_title")
+	})
+	const rejected = "title\nАзул\nОтвергнутая\nМанчкин\n"
+	expect(t, a.upload(t, path, admin.Token, "games.csv", rejected, nil), http.StatusOK, &report)
+	if report.Created != 2 || report.Failed != 1 || report.Rows[1].Action != gameimport.ActionError || report.Rows[1].Error == "" || report.Rows[2].GameID == 0 {
+		t.Fatalf("report with a rejected row = %+v", report)
+	}
+}
@@ -0,0 +1,22 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/board-box/backend/internal/db"
+)
+
+func TestOpsDBStatsAdminOnly(t *testing.T) {
+	a := newTestApp(t)
+	u := a.registerUser(t, "operator")
+	admin := a.registerAdmin(t, "sre")
+
+	expect(t, a.do(t, http.MethodGet, "/admin/ops/db", u.Token, nil), http.StatusForbidden, nil)
+
+	var stats db.Stats
+	expect(t, a.do(t, http.MethodGet, "/admin/ops/db", admin.Token, nil), http.StatusOK, &stats)
+	if stats.MaxConns == 0 || stats.TotalConns == 0 {
+		t.Errorf("unexpected pool stats: %+v", stats)
+	}
+}
@@ -0,0 +1,172 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/board-box/backend/internal/service/collection"
+	"github.com/board-box/backend/internal/service/game"
+	"github.com/board-box/backend/internal/service/play"
+)
+
+func TestPlays(t *testing.T) {
+	a := newTestApp(t)
+	games := a.seedGames(t)
+	alice := a.registerUser(t, "alice")
+	bob := a.registerUser(t, "bob")
+
+	logPlay := func(u testUser, title, date string, players ...map[string]any) play.Play {
+		t.Helper()
+
+		var p play.Play
+		body := map[string]any{"game_id": games[title], "played_on": date, "players": players}
+		expect(t, a.do(t, http.MethodPost, "/plays/", u.Token, body), http.StatusCreated, &p)
+		return p
+	}
+	me := func(u testUser, winner bool) map[string]any {
+		return map[string]any{"user_id": u.ID, "winner": winner}
+	}
+	guest := map[string]any{"guest_name": "Аня", "score": 12}
+
+	p := logPlay(alice, "Серп", "2026-10-01", me(alice, true), me(bob, false), guest)
+	if p.GameTitle != "Серп" || len(p.Players) != 3 || p.Players[0].Username == nil || *p.Players[0].Username != "alice" ||
+		p.Players[0].Pending || !p.Players[1].Pending ||
+		p.Players[2].GuestName == nil || p.Players[2].Score == nil || *p.Players[2].Score != 12 {
+		t.Fatalf("logged play = %+v", p)
+	}
+	logPlay(alice, "Серп", "2026-10-05", me(alice, false), guest)
+	logPlay(alice, "Серп", "2026-10-07", me(alice, true))
+	logPlay(alice, "Каркассон", "2026-10-02", me(alice, true), guest)
+	logPlay(alice, "Каркассон", "2026-10-03", me(alice, false), guest)
+	// Партия, записанная за других: в статистике игр есть, в доле побед нет
+	logPlay(alice, "Манчкин", "2026-10-04", guest)
+	logPlay(bob, "Кодовые имена", "2026-10-06", me(bob, true))
+
+	// Партию видят и тот, кто записал, и зарегистрированные игроки
+	expect(t, a.do(t, http.MethodGet, "/plays/"+itoa(p.ID), bob.Token, nil), http.StatusOK, nil)
+	expect(t, a.do(t, http.MethodDelete, "/plays/"+itoa(p.ID), bob.Token, nil), http.StatusNotFound, nil)
+
+	// Но у игрока партия учитывается, только когда он подтвердит участие
+	var list struct {
+		Plays []play.Play `json:"plays"`
+		Total int64       `json:"total"`
+	}
+	expect(t, a.do(t, http.MethodGet, "/plays/?game_id="+itoa(games["Серп"]), bob.Token, nil), http.StatusOK, &list)
+	if list.Total != 0 {
+		t.Fatalf("bob plays of Серп before confirm = %+v", list)
+	}
+	expect(t, a.do(t, http.MethodGet, "/plays/pending", bob.Token, nil), http.StatusOK, &list)
+	if len(list.Plays) != 1 || list.Plays[0].ID != p.ID {
+		t.Fatalf("bob pending plays = %+v", list)
+	}
+	expect(t, a.do(t, http.MethodPost, "/plays/pending/"+itoa(p.ID)+"/confirm", alice.Token, nil), http.StatusNotFound, nil)
+	expect(t, a.do(t, http.MethodPost, "/plays/pending/"+itoa(p.ID)+"/confirm", bob.Token, nil), http.StatusNoContent, nil)
+	expect(t, a.do(t, http.MethodPost, "/plays/pending/"+itoa(p.ID)+"/confirm", bob.Token, nil), http.StatusNotFound, nil)
+
+	expect(t, a.do(t, http.MethodGet, "/plays/?limit=2", alice.Token, nil), http.StatusOK, &list)
+	if list.Total != 6 || len(list.Plays) != 2 || list.Plays[0].PlayedOn.Format("2006-01-02") != "2026-10-07" {
+		t.Fatalf("alice plays = %+v", list)
+	}
+	expect(t, a.do(t, http.MethodGet, "/plays/?game_id="+itoa(games["Серп"]), bob.Token, nil), http.StatusOK, &list)
+	if list.Total != 1 || list.Plays[0].ID != p.ID {
+		t.Fatalf("bob plays of Серп = %+v", list)
+	}
+
+	var most struct {
+		Games []play.GameStat `json:"games"`
+	}
+	expect(t, a.do(t, http.MethodGet, "/plays/stats/most-played?limit=2", alice.Token, nil), http.StatusOK, &most)
+	if len(most.Games) != 2 || most.Games[0].GameID != games["Серп"] || most.Games[0].Plays != 3 || most.Games[1].Title != "Каркассон" {
+		t.Fatalf("most played = %+v", most)
+	}
+
+	var rates struct {
+		Games []play.WinRate `json:"games"`
+	}
+	expect(t, a.do(t, http.MethodGet, "/plays/stats/win-rate", alice.Token, nil), http.StatusOK, &rates)
+	if len(rates.Games) != 2 || rates.Games[0].GameID != games["Серп"] || rates.Games[0].Wins != 2 || rates.Games[1].Rate != 0.5 {
+		t.Fatalf("win rates = %+v", rates)
+	}
+
+	var index play.HIndex
+	expect(t, a.do(t, http.MethodGet, "/plays/stats/h-index", alice.Token, nil), http.StatusOK, &index)
+	if index.HIndex != 2 || len(index.Games) != 2 {
+		t.Fatalf("h-index = %+v, want 2", index)
+	}
+
+	// На полке позора — игры из коллекций, в которые ещё не играли
+	var c struct {
+		ID int64 `json:"id"`
+	}
+	expect(t, a.do(t, http.MethodPost, "/collections/", alice.Token, map[string]string{"name": "Полка"}), http.StatusCreated, &c)
+	for _, title := range []string{"Кодовые имена", "Серп"} {
+		expect(t, a.do(t, http.MethodPost, "/collections/"+itoa(c.ID)+"/games/"+itoa(games[title]), alice.Token, nil), http.StatusNoContent, nil)
+	}
+	// Игры из списка желаний ещё не куплены, их на полке нет
+	var wishlists struct {
+		Collections []collection.Collection `json:"collections"`
+	}
+	expect(t, a.do(t, http.MethodGet, "/collections/?type=wishlist", alice.Token, nil), http.StatusOK, &wishlists)
+	azul, err := a.gameSvc.CreateGame(t.Context(), game.Game{Title: "Азул"})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	expect(t, a.do(t, http.MethodPost, "/collections/"+itoa(wishlists.Collections[0].ID)+"/games/"+itoa(azul), alice.Token, nil), http.StatusNoContent, nil)
+
+	var shelf struct {
+		Games []play.ShelfGame `json:"games"`
+	}
+	expect(t, a.do(t, http.MethodGet, "/plays/stats/shelf-of-shame", alice.Token, nil), http.StatusOK, &shelf)
+	if len(shelf.Games) != 1 || shelf.Games[0].GameID != games["Кодовые имена"] {
+		t.Fatalf("shelf of shame = %+v", shelf)
+	}
+
+	expect(t, a.do(t, http.MethodDelete, "/plays/"+itoa(p.ID), alice.Token, nil), http.StatusNoContent, nil)
+	expect(t, a.do(t, http.MethodGet, "/plays/"+itoa(p.ID), bob.Token, nil), http.StatusNotFound, nil)
+
+	// Отказавшийся игрок остаётся в партии гостем, и она ему не видна
+	declined := logPlay(alice, "Манчкин", "2026-10-08", me(alice, true), me(bob, false))
+	expect(t, a.do(t, http.MethodGet, "/plays/stats/most-played", bob.Token, nil), http.StatusOK, &most)
+	if len(most.Games) != 1 || most.Games[0].GameID != games["Кодовые имена"] {
+		t.Fatalf("bob most played before confirm = %+v", most)
+	}
+	expect(t, a.do(t, http.MethodDelete, "/plays/pending/"+itoa(declined.ID), bob.Token, nil), http.StatusNoContent, nil)
+	expect(t, a.do(t, http.MethodGet, "/plays/"+itoa(declined.ID), bob.Token, nil), http.StatusNotFound, nil)
+	var got play.Play
+	expect(t, a.do(t, http.MethodGet, "/plays/"+itoa(declined.ID), alice.Token, nil), http.StatusOK, &got)
+	if len(got.Players) != 2 || got.Players[1].UserID != nil || got.Players[1].GuestName == nil || *got.Players[1].GuestName != "bob" {
+		t.Fatalf("declined play = %+v", got)
+	}
+
+	invalid := []map[string]any{
+		{"game_id": games["Серп"], "players": []map[string]any{}},
+		{"game_id": games["Серп"], "players": []map[string]any{{"user_id": alice.ID, "guest_name": "Аня"}}},
+		{"game_id": games["Серп"], "played_on": "01.10.2026", "players": []map[string]any{guest}},
+	}
+	for _, body := range invalid {
+		expect(t, a.do(t, http.MethodPost, "/plays/", alice.Token, body), http.StatusBadRequest, nil)
+	}
+	expect(t, a.do(t, http.MethodPost, "/plays/", alice.Token, map[string]any{"game_id": 999999, "players": []map[string]any{guest}}), http.StatusNotFound, nil)
+	expect(t, a.do(t, http.MethodPost, "/plays/", alice.Token, map[string]any{"game_id": games["Серп"], "players": []map[string]any{{"user_id": 999999}}}), http.StatusNotFound, nil)
+}
+
+func TestPlayKeepsDeletedPlayer(t *testing.T) {
+	a := newTestApp(t)
+	games := a.seedGames(t)
+	alice := a.registerUser(t, "alice")
+	bob := a.registerUser(t, "bob")
+
+	var p play.Play
+	body := map[string]any{"game_id": games["Серп"], "players": []map[string]any{{"user_id": alice.ID}, {"user_id": bob.ID, "winner": true}}}
+	expect(t, a.do(t, http.MethodPost, "/plays/", alice.Token, body), http.StatusCreated, &p)
+
+	// Удалённый игрок остаётся в партии гостем со своим именем
+	if _, err := a.db.Exec(t.Context(), "DELETE FROM users WHERE id = $1", bob.ID); err != nil {
+		t.Fatalf("delete user: %v", err)
+	}
+	var got play.Play
+	expect(t, a.do(t, http.MethodGet, "/plays/"+itoa(p.ID), alice.Token, nil), http.StatusOK, &got)
+	if len(got.Players) != 2 || got.Players[1].UserID != nil || got.Players[1].GuestName == nil || *got.Players[1].GuestName != "bob" || !got.Players[1].Winner {
+		t.Fatalf("players after delete = %+v", got.Players)
+	}
+}
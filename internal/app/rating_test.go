@@ -0,0 +1,120 @@
+package app
+
+import (
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/board-box/backend/internal/service/game"
+	"github.com/board-box/backend/internal/service/rating"
+)
+
+func TestRatings(t *testing.T) {
+	a := newTestApp(t)
+	games := a.seedGames(t)
+	alice := a.registerUser(t, "alice")
+	bob := a.registerUser(t, "bob")
+
+	rate := func(u testUser, title string, value int, review string) rating.Rating {
+		t.Helper()
+
+		var r rating.Rating
+		body := map[string]any{"rating": value, "review": review}
+		expect(t, a.do(t, http.MethodPut, "/games/"+itoa(games[title])+"/rating", u.Token, body), http.StatusOK, &r)
+		return r
+	}
+	getGame := func(title string) game.Game {
+		t.Helper()
+
+		var g game.Game
+		expect(t, a.do(t, http.MethodGet, "/games/"+itoa(games[title]), "", nil), http.StatusOK, &g)
+		return g
+	}
+
+	r := rate(alice, "Серп", 10, "  Шедевр ")
+	if r.Username != "alice" || r.Rating != 10 || r.Review == nil || *r.Review != "Шедевр" {
+		t.Fatalf("rating = %+v", r)
+	}
+	rate(bob, "Серп", 6, "")
+	rate(alice, "Каркассон", 9, "")
+	rate(bob, "Манчкин", 3, "Слишком долго")
+
+	g := getGame("Серп")
+	if g.RatingCount != 2 || g.RatingAvg == nil || *g.RatingAvg != 8 || math.Abs(g.RatingScore-71.0/12) > 1e-9 {
+		t.Fatalf("Серп ratings = %d, %v, %v", g.RatingCount, g.RatingAvg, g.RatingScore)
+	}
+	if g = getGame("Кодовые имена"); g.RatingCount != 0 || g.RatingAvg != nil || g.RatingScore != 5.5 {
+		t.Fatalf("unrated game = %d, %v, %v", g.RatingCount, g.RatingAvg, g.RatingScore)
+	}
+
+	// Правка заменяет оценку, а не добавляет новую
+	rate(alice, "Серп", 8, "Шедевр, но долго раскладывать")
+	rate(alice, "Серп", 8, "Шедевр, но долго раскладывать")
+	if g = getGame("Серп"); g.RatingCount != 2 || *g.RatingAvg != 7 {
+		t.Fatalf("Серп after edit = %d, %v", g.RatingCount, *g.RatingAvg)
+	}
+
+	var history struct {
+		Revisions []rating.Revision `json:"revisions"`
+	}
+	expect(t, a.do(t, http.MethodGet, "/games/"+itoa(games["Серп"])+"/rating/history", alice.Token, nil), http.StatusOK, &history)
+	if len(history.Revisions) != 2 || history.Revisions[0].Rating != 8 || history.Revisions[1].Rating != 10 {
+		t.Fatalf("history = %+v, want 8 then 10", history.Revisions)
+	}
+
+	var list struct {
+		Ratings []rating.Rating `json:"ratings"`
+		Total   int64           `json:"total"`
+	}
+	expect(t, a.do(t, http.MethodGet, "/games/"+itoa(games["Серп"])+"/ratings", "", nil), http.StatusOK, &list)
+	if list.Total != 2 || len(list.Ratings) != 2 || list.Ratings[0].Username != "alice" {
+		t.Fatalf("Серп ratings = %+v", list)
+	}
+
+	// Сортировки каталога проходят все страницы по курсору
+	assertOrder := func(sort string, want ...string) {
+		t.Helper()
+
+		var titles []string
+		cursor := ""
+		for page := 0; page < len(want)+1; page++ {
+			path := "/games/?limit=1&sort=" + sort
+			if cursor != "" {
+				path += "&cursor=" + cursor
+			}
+
+			var resp listGamesResponse
+			expect(t, a.do(t, http.MethodGet, path, "", nil), http.StatusOK, &resp)
+			for _, g := range resp.Games {
+				titles = append(titles, g.Title)
+			}
+			if cursor = resp.NextCursor; cursor == "" {
+				break
+			}
+		}
+		if !equalStrings(titles, want) {
+			t.Errorf("sort=%s titles = %v, want %v", sort, titles, want)
+		}
+	}
+	// Серп: 8 и 6, Каркассон: 9, Манчкин: 3. У одной девятки байесовская
+	// оценка 64/11 выше, чем у двух оценок со средним 7 — 69/12
+	assertOrder("score", "Каркассон", "Серп", "Кодовые имена", "Манчкин")
+	assertOrder("rating", "Каркассон", "Серп", "Манчкин", "Кодовые имена")
+	assertOrder("title", "Каркассон", "Кодовые имена", "Манчкин", "Серп")
+
+	var resp listGamesResponse
+	expect(t, a.do(t, http.MethodGet, "/games/?limit=1&sort=score", "", nil), http.StatusOK, &resp)
+	expect(t, a.do(t, http.MethodGet, "/games/?sort=rating&cursor="+resp.NextCursor, "", nil), http.StatusBadRequest, nil)
+	expect(t, a.do(t, http.MethodGet, "/games/?sort=popular", "", nil), http.StatusBadRequest, nil)
+
+	expect(t, a.do(t, http.MethodDelete, "/games/"+itoa(games["Серп"])+"/rating", bob.Token, nil), http.StatusNoContent, nil)
+	expect(t, a.do(t, http.MethodDelete, "/games/"+itoa(games["Серп"])+"/rating", bob.Token, nil), http.StatusNotFound, nil)
+	expect(t, a.do(t, http.MethodGet, "/games/"+itoa(games["Серп"])+"/rating", bob.Token, nil), http.StatusNotFound, nil)
+	if g = getGame("Серп"); g.RatingCount != 1 || *g.RatingAvg != 8 || math.Abs(g.RatingScore-63.0/11) > 1e-9 {
+		t.Fatalf("Серп after delete = %d, %v, %v", g.RatingCount, *g.RatingAvg, g.RatingScore)
+	}
+
+	expect(t, a.do(t, http.MethodPut, "/games/"+itoa(games["Серп"])+"/rating", alice.Token, map[string]any{"rating": 11}), http.StatusBadRequest, nil)
+	expect(t, a.do(t, http.MethodPut, "/games/999999/rating", alice.Token, map[string]any{"rating": 5}), http.StatusNotFound, nil)
+	expect(t, a.do(t, http.MethodPut, "/games/"+itoa(games["Серп"])+"/rating", "", map[string]any{"rating": 5}), http.StatusUnauthorized, nil)
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/board-box/backend/internal/service/collection"
+	"github.com/board-box/backend/internal/service/similar"
+)
+
+func TestSimilarGames(t *testing.T) {
+	a := newTestApp(t)
+	games := a.seedGames(t)
+	u := a.registerUser(t, "collector")
+
+	list := func(query, token string) []string {
+		t.Helper()
+
+		var resp struct {
+			Games []similar.Similar `json:"games"`
+		}
+		expect(t, a.do(t, http.MethodGet, "/games/"+itoa(games["Каркассон"])+"/similar"+query, token, nil), http.StatusOK, &resp)
+
+		titles := make([]string, 0, len(resp.Games))
+		for i, s := range resp.Games {
+			if s.Score <= 0 || s.Score > 1 || (i > 0 && s.Score > resp.Games[i-1].Score) {
+				t.Errorf("%s: scores are not ordered: %+v", query, resp.Games)
+			}
+			titles = append(titles, s.Game.Title)
+		}
+		return titles
+	}
+	// До пересчёта таблица пуста
+	if got := list("", ""); len(got) != 0 {
+		t.Fatalf("similar before refresh = %v", got)
+	}
+
+	n, err := a.similarSvc.Refresh(t.Context())
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if n != 12 {
+		t.Errorf("saved pairs = %d, want 12", n)
+	}
+
+	// Серп — тот же жанр, Манчкин ближе по сложности и времени, чем Кодовые имена
+	if got, want := list("", ""), []string{"Серп", "Манчкин", "Кодовые имена"}; !equalStrings(got, want) {
+		t.Errorf("similar = %v, want %v", got, want)
+	}
+	if got, want := list("?limit=1", ""), []string{"Серп"}; !equalStrings(got, want) {
+		t.Errorf("similar with limit = %v, want %v", got, want)
+	}
+	if got, want := list("?players=7", ""), []string{"Кодовые имена"}; !equalStrings(got, want) {
+		t.Errorf("similar for 7 players = %v, want %v", got, want)
+	}
+	if got, want := list("?max_time=60", ""), []string{"Манчкин", "Кодовые имена"}; !equalStrings(got, want) {
+		t.Errorf("similar up to 60 minutes = %v, want %v", got, want)
+	}
+
+	var c collection.Collection
+	expect(t, a.do(t, http.MethodPost, "/collections/", u.Token, map[string]string{"name": "Полка"}), http.StatusCreated, &c)
+	expect(t, a.do(t, http.MethodPost, "/collections/"+itoa(c.ID)+"/games/"+itoa(games["Серп"]), u.Token, nil), http.StatusNoContent, nil)
+
+	if got, want := list("?exclude_owned=true", u.Token), []string{"Манчкин", "Кодовые имена"}; !equalStrings(got, want) {
+		t.Errorf("similar without owned = %v, want %v", got, want)
+	}
+	expect(t, a.do(t, http.MethodGet, "/games/"+itoa(games["Каркассон"])+"/similar?exclude_owned=true", "", nil), http.StatusUnauthorized, nil)
+	expect(t, a.do(t, http.MethodGet, "/games/999999/similar", "", nil), http.StatusNotFound, nil)
+	expect(t, a.do(t, http.MethodGet, "/games/"+itoa(games["Каркассон"])+"/similar?limit=500", "", nil), http.StatusBadRequest, nil)
+}
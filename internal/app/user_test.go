@@ -0,0 +1,92 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAuthRefreshAndLogout(t *testing.T) {
+	a := newTestApp(t)
+	u := a.registerUser(t, "alice")
+
+	var info struct {
+		Email string   `json:"email"`
+		Roles []string `json:"roles"`
+	}
+	expect(t, a.do(t, http.MethodGet, "/user/info", u.Token, nil), http.StatusOK, &info)
+	if info.Email != u.Email {
+		t.Errorf("email = %q, want %q", info.Email, u.Email)
+	}
+	if !equalStrings(info.Roles, []string{"user"}) {
+		t.Errorf("roles = %v, want [user]", info.Roles)
+	}
+
+	var refreshed struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	rec := a.do(t, http.MethodPost, "/user/refresh", "", map[string]string{"refresh_token": u.RefreshToken})
+	expect(t, rec, http.StatusOK, &refreshed)
+
+	// Повторное использование старого refresh-токена отзывает сессию целиком
+	rec = a.do(t, http.MethodPost, "/user/refresh", "", map[string]string{"refresh_token": u.RefreshToken})
+	expect(t, rec, http.StatusUnauthorized, nil)
+	expect(t, a.do(t, http.MethodGet, "/user/info", refreshed.Token, nil), http.StatusUnauthorized, nil)
+}
+
+func TestLogoutRevokesAccessToken(t *testing.T) {
+	a := newTestApp(t)
+	u := a.registerUser(t, "bob")
+
+	expect(t, a.do(t, http.MethodPost, "/user/logout", u.Token, nil), http.StatusNoContent, nil)
+	expect(t, a.do(t, http.MethodGet, "/user/info", u.Token, nil), http.StatusUnauthorized, nil)
+
+	rec := a.do(t, http.MethodPost, "/user/refresh", "", map[string]string{"refresh_token": u.RefreshToken})
+	expect(t, rec, http.StatusUnauthorized, nil)
+}
+
+func TestLogoutAllDevices(t *testing.T) {
+	a := newTestApp(t)
+	phone := a.registerUser(t, "carol")
+	laptop := phone
+	a.login(t, &laptop, "password-carol")
+
+	expect(t, a.do(t, http.MethodPost, "/user/logout-all", laptop.Token, nil), http.StatusNoContent, nil)
+	expect(t, a.do(t, http.MethodGet, "/user/info", phone.Token, nil), http.StatusUnauthorized, nil)
+	expect(t, a.do(t, http.MethodGet, "/user/info", laptop.Token, nil), http.StatusUnauthorized, nil)
+}
+
+func TestAdminManagesRoles(t *testing.T) {
+	a := newTestApp(t)
+	admin := a.registerAdmin(t, "root")
+	u := a.registerUser(t, "dave")
+
+	path := "/admin/users/" + itoa(u.ID) + "/roles/moderator"
+	expect(t, a.do(t, http.MethodPut, path, u.Token, nil), http.StatusForbidden, nil)
+	expect(t, a.do(t, http.MethodPut, path, admin.Token, nil), http.StatusNoContent, nil)
+
+	var info struct {
+		Roles []string `json:"roles"`
+	}
+	expect(t, a.do(t, http.MethodGet, "/user/info", u.Token, nil), http.StatusOK, &info)
+	if !equalStrings(info.Roles, []string{"moderator", "user"}) {
+		t.Errorf("roles = %v, want [moderator user]", info.Roles)
+	}
+
+	expect(t, a.do(t, http.MethodPut, "/admin/users/"+itoa(u.ID)+"/roles/superuser", admin.Token, nil), http.StatusBadRequest, nil)
+}
+
+func TestRemoveRoleKeepsBaseRoleAndLastAdmin(t *testing.T) {
+	a := newTestApp(t)
+	admin := a.registerAdmin(t, "root")
+	u := a.registerUser(t, "erin")
+
+	expect(t, a.do(t, http.MethodDelete, "/admin/users/"+itoa(u.ID)+"/roles/user", admin.Token, nil), http.StatusConflict, nil)
+
+	self := "/admin/users/" + itoa(admin.ID) + "/roles/admin"
+	expect(t, a.do(t, http.MethodDelete, self, admin.Token, nil), http.StatusConflict, nil)
+
+	// Со вторым администратором роль можно отозвать
+	expect(t, a.do(t, http.MethodPut, "/admin/users/"+itoa(u.ID)+"/roles/admin", admin.Token, nil), http.StatusNoContent, nil)
+	expect(t, a.do(t, http.MethodDelete, self, admin.Token, nil), http.StatusNoContent, nil)
+}
@@ -1,36 +1,73 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"time"
 
 	jwt "github.com/golang-jwt/jwt/v5"
 )
 
 type Claims struct {
-	UserID int64 `json:"user_id"`
+	UserID    int64  `json:"user_id"`
+	SessionID string `json:"sid"`
+	Roles     []Role `json:"roles,omitempty"`
 	jwt.RegisteredClaims
 }
 
 type JWTManager struct {
-	SecretKey     string
-	TokenDuration time.Duration
+	SecretKey       string
+	TokenDuration   time.Duration
+	RefreshDuration time.Duration
 }
 
-func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
+func NewJWTManager(secretKey string, tokenDuration, refreshDuration time.Duration) *JWTManager {
 	return &JWTManager{
-		SecretKey:     secretKey,
-		TokenDuration: tokenDuration,
+		SecretKey:       secretKey,
+		TokenDuration:   tokenDuration,
+		RefreshDuration: refreshDuration,
 	}
 }
 
-func (j *JWTManager) GenerateToken(secretKey string, userID int64) (string, error) {
+// GenerateToken выпускает короткоживущий access-токен, привязанный к сессии.
+func (j *JWTManager) GenerateToken(secretKey string, userID int64, sessionID string, roles []Role) (string, error) {
+	now := time.Now()
 	claims := &Claims{
-		UserID: userID,
+		UserID:    userID,
+		SessionID: sessionID,
+		Roles:     roles,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ID:        RandomID(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.TokenDuration)),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(secretKey))
 }
+
+// GenerateRefreshToken возвращает случайный refresh-токен и его хэш для хранения в БД.
+func GenerateRefreshToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RandomID возвращает случайный идентификатор из 32 hex-символов.
+func RandomID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
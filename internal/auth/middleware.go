@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -8,21 +9,56 @@ import (
 	jwt "github.com/golang-jwt/jwt/v5"
 )
 
-func Middleware(secretKey string) gin.HandlerFunc {
+// RevocationChecker проверяет, не отозваны ли сессия или конкретный токен.
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, sessionID, tokenID string) (bool, error)
+}
+
+func Middleware(secretKey string, checker RevocationChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenStr := extractToken(c.Request)
 		claims := &Claims{}
 
 		token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
 			return []byte(secretKey), nil
-		})
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 		if err != nil || !token.Valid {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
 		}
 
+		revoked, err := checker.IsRevoked(c.Request.Context(), claims.SessionID, claims.ID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to check token"})
+			return
+		}
+		if revoked {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
 		c.Set("userID", claims.UserID)
+		c.Set("roles", claims.Roles)
+		c.Set("sessionID", claims.SessionID)
+		c.Set("tokenID", claims.ID)
+		if claims.ExpiresAt != nil {
+			c.Set("tokenExpiresAt", claims.ExpiresAt.Time)
+		}
+		c.Next()
+	}
+}
+
+// RequireRole пропускает пользователей, у которых есть хотя бы одна из ролей.
+// Должен идти после Middleware.
+func RequireRole(roles ...Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("roles")
+		have, _ := value.([]Role)
+		if !hasAnyRole(have, roles) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			return
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "secret"
+
+type revokedSessions map[string]bool
+
+func (r revokedSessions) IsRevoked(_ context.Context, sessionID, _ string) (bool, error) {
+	if sessionID == "broken" {
+		return false, errors.New("db is down")
+	}
+	return r[sessionID], nil
+}
+
+func newTestRouter(checker RevocationChecker, roles ...Role) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	handlers := []gin.HandlerFunc{Middleware(testSecret, checker)}
+	if len(roles) > 0 {
+		handlers = append(handlers, RequireRole(roles...))
+	}
+	handlers = append(handlers, func(c *gin.Context) {
+		c.String(http.StatusOK, "%d", c.MustGet("userID").(int64))
+	})
+	r.GET("/", handlers...)
+	return r
+}
+
+func serve(r http.Handler, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddleware(t *testing.T) {
+	m := NewJWTManager(testSecret, time.Minute, time.Hour)
+	sign := func(sessionID string) string {
+		token, err := m.GenerateToken(testSecret, 7, sessionID, []Role{RoleUser})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	expired := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+		UserID:           7,
+		SessionID:        "active",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute))},
+	})
+	expiredToken, _ := expired.SignedString([]byte(testSecret))
+
+	foreign, _ := NewJWTManager("other", time.Minute, time.Hour).GenerateToken("other", 7, "active", nil)
+
+	r := newTestRouter(revokedSessions{"revoked": true})
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{name: "valid", token: sign("active"), status: http.StatusOK},
+		{name: "no token", token: "", status: http.StatusUnauthorized},
+		{name: "garbage", token: "not-a-jwt", status: http.StatusUnauthorized},
+		{name: "expired", token: expiredToken, status: http.StatusUnauthorized},
+		{name: "wrong secret", token: foreign, status: http.StatusUnauthorized},
+		{name: "revoked session", token: sign("revoked"), status: http.StatusUnauthorized},
+		{name: "checker error", token: sign("broken"), status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := serve(r, tt.token); rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	m := NewJWTManager(testSecret, time.Minute, time.Hour)
+	r := newTestRouter(revokedSessions{}, RoleModerator, RoleAdmin)
+
+	tests := []struct {
+		roles  []Role
+		status int
+	}{
+		{roles: []Role{RoleUser}, status: http.StatusForbidden},
+		{roles: nil, status: http.StatusForbidden},
+		{roles: []Role{RoleUser, RoleModerator}, status: http.StatusOK},
+		{roles: []Role{RoleAdmin}, status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		token, err := m.GenerateToken(testSecret, 1, "s", tt.roles)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec := serve(r, token); rec.Code != tt.status {
+			t.Errorf("roles %v: status = %d, want %d", tt.roles, rec.Code, tt.status)
+		}
+	}
+}
+
+func TestGenerateRefreshToken(t *testing.T) {
+	token, hash, err := GenerateRefreshToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if HashToken(token) != hash {
+		t.Error("hash does not match token")
+	}
+
+	other, _, _ := GenerateRefreshToken()
+	if other == token {
+		t.Error("refresh tokens repeat")
+	}
+}
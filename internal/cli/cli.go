@@ -0,0 +1,155 @@
+// Package cli разбирает подкоманды бинарника: запуск сервера, миграции и
+// административные задачи. Все подкоманды собирают зависимости через
+// internal/app, поэтому работают с той же конфигурацией, что и сервер.
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// ErrUsage означает, что подкоманда вызвана с неверными аргументами.
+var ErrUsage = errors.New("invalid usage")
+
+// IO — потоки ввода-вывода подкоманды.
+type IO struct {
+	In  io.Reader
+	Out io.Writer
+	Err io.Writer
+}
+
+type command struct {
+	usage   string
+	summary string
+	run     func(ctx context.Context, stdio IO, args []string) error
+}
+
+var commands = map[string]command{
+	"serve": {
+		usage:   "serve",
+		summary: "start the HTTP server (default)",
+		run:     serve,
+	},
+	"migrate": {
+		usage:   "migrate <up|down|status|redo>",
+		summary: "run embedded database migrations",
+		run:     migrate,
+	},
+	"seed": {
+		usage:   "seed <file|->",
+		summary: "add games from a JSON array to the catalog",
+		run:     seed,
+	},
+	"import-games": {
+		usage:   "import-games [-format csv|jsonl|bgg] [-map <column=field,...>] [-dry-run] [-json] <file|->",
+		summary: "import games from CSV, JSON Lines or a BGG XML dump, upserting by external ID",
+		run:     importGames,
+	},
+	"refresh-similar": {
+		usage:   "refresh-similar",
+		summary: "recompute the similar games table now",
+		run:     refreshSimilar,
+	},
+	"create-admin": {
+		usage:   "create-admin -email <email> [-username <name>] [-password <password>]",
+		summary: "grant the admin role, registering the user if needed",
+		run:     createAdmin,
+	},
+	"reset-password": {
+		usage:   "reset-password -email <email> [-password <password>]",
+		summary: "set a new password and end all sessions of the user",
+		run:     resetPassword,
+	},
+	"export-user": {
+		usage:   "export-user <id>",
+		summary: "print all data of the user as JSON",
+		run:     exportUser,
+	},
+	"config": {
+		usage:   "config print",
+		summary: "print the effective configuration with secrets redacted",
+		run:     configCmd,
+	},
+}
+
+// Run выполняет подкоманду из args (без имени бинарника) и возвращает код
+// выхода. Без аргументов запускается сервер.
+func Run(ctx context.Context, args []string, stdio IO) int {
+	name := "serve"
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		printUsage(stdio.Out)
+		return exitOK
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stdio.Err, "unknown command %q\n\n", name)
+		printUsage(stdio.Err)
+		return exitUsage
+	}
+
+	if err := cmd.run(ctx, stdio, args); err != nil {
+		if errors.Is(err, ErrUsage) {
+			fmt.Fprintf(stdio.Err, "%v\nusage: server %s\n", err, cmd.usage)
+			return exitUsage
+		}
+		fmt.Fprintf(stdio.Err, "%s: %v\n", name, err)
+		return exitError
+	}
+
+	return exitOK
+}
+
+func printUsage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "Usage: server <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	for _, name := range names {
+		cmd := commands[name]
+		fmt.Fprintf(tw, "  %s\t%s\n", cmd.usage, cmd.summary)
+	}
+	_ = tw.Flush()
+}
+
+func usageErr(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrUsage, fmt.Sprintf(format, args...))
+}
+
+// readPassword берёт пароль из флага, а если он не задан — из первой строки
+// stdin, чтобы пароль не попадал в историю команд и список процессов.
+func readPassword(flagValue string, in io.Reader) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(in, 4096))
+	if err != nil {
+		return "", err
+	}
+
+	line, _, _ := strings.Cut(string(data), "\n")
+	return strings.TrimRight(line, "\r"), nil
+}
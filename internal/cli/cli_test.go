@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func run(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+
+	var out, errOut bytes.Buffer
+	code := Run(context.Background(), args, IO{In: strings.NewReader(""), Out: &out, Err: &errOut})
+	return code, out.String(), errOut.String()
+}
+
+func TestRunUsage(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		code int
+		err  string
+	}{
+		{name: "help", args: []string{"help"}, code: exitOK},
+		{name: "unknown command", args: []string{"bogus"}, code: exitUsage, err: `unknown command "bogus"`},
+		{name: "serve with arguments", args: []string{"serve", "now"}, code: exitUsage, err: "usage: server serve"},
+		{name: "migrate without command", args: []string{"migrate"}, code: exitUsage, err: "usage: server migrate"},
+		{name: "seed without file", args: []string{"seed"}, code: exitUsage, err: "usage: server seed"},
+		{name: "refresh-similar with arguments", args: []string{"refresh-similar", "now"}, code: exitUsage, err: "usage: server refresh-similar"},
+		{name: "create-admin without email", args: []string{"create-admin"}, code: exitUsage, err: "-email is required"},
+		{name: "create-admin unknown flag", args: []string{"create-admin", "-role", "x"}, code: exitUsage, err: "flag provided but not defined"},
+		{name: "reset-password extra args", args: []string{"reset-password", "-email", "a@b.c", "extra"}, code: exitUsage, err: "unexpected arguments: extra"},
+		{name: "export-user invalid id", args: []string{"export-user", "abc"}, code: exitUsage, err: `invalid user id "abc"`},
+		{name: "config without print", args: []string{"config"}, code: exitUsage, err: "usage: server config print"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, errOut := run(t, tt.args...)
+			if code != tt.code {
+				t.Fatalf("code = %d, want %d; stderr: %s", code, tt.code, errOut)
+			}
+			if !strings.Contains(errOut, tt.err) {
+				t.Fatalf("stderr %q does not contain %q", errOut, tt.err)
+			}
+		})
+	}
+}
+
+func TestHelpListsCommands(t *testing.T) {
+	_, out, _ := run(t, "--help")
+
+	for name := range commands {
+		if !strings.Contains(out, "  "+name) {
+			t.Errorf("help does not mention %q:\n%s", name, out)
+		}
+	}
+}
+
+func TestConfigPrintRedactsSecrets(t *testing.T) {
+	t.Setenv("PG_PASSWORD", "pg-secret")
+	t.Setenv("JWT_SECRET", "jwt-secret")
+	t.Setenv("LLM_API_KEY", "llm-secret")
+	t.Setenv("PG_HOST", "db.internal")
+
+	code, out, errOut := run(t, "config", "print")
+	if code != exitOK {
+		t.Fatalf("code = %d; stderr: %s", code, errOut)
+	}
+
+	for _, secret := range []string{"pg-secret", "jwt-secret", "llm-secret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("output leaks %q:\n%s", secret, out)
+		}
+	}
+	for _, line := range []string{"Postgres.Host", "db.internal", "Postgres.Password", "***"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("output does not contain %q:\n%s", line, out)
+		}
+	}
+}
+
+func TestReadPassword(t *testing.T) {
+	got, err := readPassword("", strings.NewReader("s3cret\r\nignored\n"))
+	if err != nil || got != "s3cret" {
+		t.Fatalf("readPassword() = %q, %v; want s3cret", got, err)
+	}
+
+	got, err = readPassword("from-flag", strings.NewReader("s3cret\n"))
+	if err != nil || got != "from-flag" {
+		t.Fatalf("readPassword() = %q, %v; want from-flag", got, err)
+	}
+}
+
+func TestImportGamesUsage(t *testing.T) {
+	tests := []struct {
+		args []string
+		err  string
+	}{
+		{args: []string{"import-games"}, err: "expected exactly one file"},
+		{args: []string{"import-games", "games.txt"}, err: "cannot detect format"},
+		{args: []string{"import-games", "-map", "Name", "games.csv"}, err: "invalid -map"},
+	}
+
+	for _, tt := range tests {
+		code, _, errOut := run(t, tt.args...)
+		if code != exitUsage || !strings.Contains(errOut, tt.err) {
+			t.Errorf("Run(%v) = %d, %q; want usage error containing %q", tt.args, code, errOut, tt.err)
+		}
+	}
+}
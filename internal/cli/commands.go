@@ -0,0 +1,309 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/board-box/backend/internal/app"
+	"github.com/board-box/backend/internal/config"
+	"github.com/board-box/backend/internal/service/gameimport"
+	"github.com/board-box/backend/internal/service/user"
+)
+
+func serve(ctx context.Context, _ IO, args []string) error {
+	if len(args) != 0 {
+		return usageErr("serve takes no arguments")
+	}
+
+	a, err := app.NewApp(ctx)
+	if err != nil {
+		return fmt.Errorf("could not create app: %w", err)
+	}
+
+	return a.Run()
+}
+
+func migrate(ctx context.Context, stdio IO, args []string) error {
+	if len(args) != 1 {
+		return usageErr("expected exactly one migrate command")
+	}
+
+	return app.Migrate(ctx, args[0], stdio.Out)
+}
+
+func seed(ctx context.Context, stdio IO, args []string) error {
+	if len(args) != 1 {
+		return usageErr("expected a file with games")
+	}
+
+	in := stdio.In
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	a, err := app.Open(ctx)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	n, err := a.Seed(ctx, in)
+	fmt.Fprintf(stdio.Out, "added %d games\n", n)
+	return err
+}
+
+func importGames(ctx context.Context, stdio IO, args []string) error {
+	fs := newFlagSet("import-games")
+	formatName := fs.String("format", "", "file format: csv, jsonl or bgg; detected by extension if omitted")
+	mapping := fs.String("map", "", "column to field mapping, e.g. Name=title,Weight=complexity_level; field \"-\" skips a column")
+	dryRun := fs.Bool("dry-run", false, "validate the file and show what would change without writing")
+	asJSON := fs.Bool("json", false, "print the full report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return usageErr("%v", err)
+	}
+	if fs.NArg() != 1 {
+		return usageErr("expected exactly one file")
+	}
+
+	path := fs.Arg(0)
+	format, err := gameimport.ParseFormat(*formatName, path)
+	if err != nil {
+		return usageErr("cannot detect format of %q, pass -format", path)
+	}
+
+	columns, err := gameimport.ParseMapping(*mapping)
+	if err != nil {
+		return usageErr("invalid -map %q", *mapping)
+	}
+
+	in := stdio.In
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	a, err := app.Open(ctx)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	report, err := a.GameImport().Import(ctx, in, gameimport.Options{Format: format, DryRun: *dryRun, Mapping: columns})
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdio.Out)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printImportReport(stdio.Out, report)
+	}
+
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of %d rows failed", report.Failed, report.Total)
+	}
+	return nil
+}
+
+// printImportReport выводит строки с ошибками и итог импорта.
+func printImportReport(w io.Writer, report gameimport.Report) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, row := range report.Rows {
+		if row.Action == gameimport.ActionError {
+			fmt.Fprintf(tw, "row %d\t%s\t%s\n", row.Row, row.Title, row.Error)
+		}
+	}
+	_ = tw.Flush()
+
+	if len(report.IgnoredColumns) > 0 {
+		fmt.Fprintf(w, "ignored columns: %s\n", strings.Join(report.IgnoredColumns, ", "))
+	}
+
+	prefix := ""
+	if report.DryRun {
+		prefix = "dry run: "
+	}
+	fmt.Fprintf(w, "%s%d rows, %d created, %d updated, %d failed\n",
+		prefix, report.Total, report.Created, report.Updated, report.Failed)
+}
+
+func createAdmin(ctx context.Context, stdio IO, args []string) error {
+	fs := newFlagSet("create-admin")
+	email := fs.String("email", "", "email of the user")
+	username := fs.String("username", "", "username for a new user, defaults to the part of email before @")
+	password := fs.String("password", "", "password for a new user, read from stdin if omitted")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *email == "" {
+		return usageErr("-email is required")
+	}
+
+	a, err := app.Open(ctx)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	name := *username
+	if name == "" {
+		name, _, _ = strings.Cut(*email, "@")
+	}
+
+	// Пароль нужен только новому пользователю, поэтому stdin читаем лишь когда
+	// пользователя с таким email ещё нет.
+	pass := *password
+	if pass == "" {
+		_, err = a.Users().FindByEmail(ctx, *email)
+		if errors.Is(err, user.ErrUserNotFound) {
+			fmt.Fprint(stdio.Err, "password: ")
+			pass, err = readPassword("", stdio.In)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	u, err := a.Users().CreateAdmin(ctx, name, *email, pass)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdio.Out, "user %d (%s) has roles: %v\n", u.ID, u.Email, u.Roles)
+	return nil
+}
+
+func resetPassword(ctx context.Context, stdio IO, args []string) error {
+	fs := newFlagSet("reset-password")
+	email := fs.String("email", "", "email of the user")
+	password := fs.String("password", "", "new password, read from stdin if omitted")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *email == "" {
+		return usageErr("-email is required")
+	}
+
+	pass, err := readPassword(*password, stdio.In)
+	if err != nil {
+		return err
+	}
+
+	a, err := app.Open(ctx)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	if err = a.Users().ResetPassword(ctx, *email, pass); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdio.Out, "password of %s has been reset, all sessions ended\n", *email)
+	return nil
+}
+
+func exportUser(ctx context.Context, stdio IO, args []string) error {
+	if len(args) != 1 {
+		return usageErr("expected a user id")
+	}
+
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return usageErr("invalid user id %q", args[0])
+	}
+
+	a, err := app.Open(ctx)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	return a.ExportUser(ctx, userID, stdio.Out)
+}
+
+func refreshSimilar(ctx context.Context, stdio IO, args []string) error {
+	if len(args) != 0 {
+		return usageErr("refresh-similar takes no arguments")
+	}
+
+	a, err := app.Open(ctx)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	n, err := a.Similar().Refresh(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdio.Out, "saved %d similar pairs\n", n)
+	return nil
+}
+
+func configCmd(_ context.Context, stdio IO, args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		return usageErr("expected \"config print\"")
+	}
+
+	cfg, err := config.New()
+	if err != nil {
+		return err
+	}
+
+	return printConfig(stdio.Out, cfg.Redacted())
+}
+
+// printConfig выводит поля конфигурации построчно: Section.Field value.
+func printConfig(w io.Writer, cfg config.Config) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	v := reflect.ValueOf(cfg)
+	for i := 0; i < v.NumField(); i++ {
+		section := v.Field(i)
+		for j := 0; j < section.NumField(); j++ {
+			name := v.Type().Field(i).Name + "." + section.Type().Field(j).Name
+			fmt.Fprintf(tw, "%s\t%v\n", name, section.Field(j).Interface())
+		}
+	}
+
+	return tw.Flush()
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return usageErr("%v", err)
+	}
+	if fs.NArg() != 0 {
+		return usageErr("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	return nil
+}
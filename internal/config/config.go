@@ -10,11 +10,12 @@ import (
 )
 
 type Config struct {
-	App        AppConfig
-	Postgres   PostgresConfig
-	HTTP       HTTPConfig
-	JWT        JWTConfig
-	ChatApiKey string
+	App      AppConfig
+	Postgres PostgresConfig
+	HTTP     HTTPConfig
+	JWT      JWTConfig
+	Chat     ChatConfig
+	Similar  SimilarConfig
 }
 
 type AppConfig struct {
@@ -22,6 +23,8 @@ type AppConfig struct {
 	Host    string
 	Version string
 	Env     string // dev/stage/prod
+	// AutoMigrate накатывает миграции при старте сервера
+	AutoMigrate bool
 }
 
 type PostgresConfig struct {
@@ -45,8 +48,26 @@ type HTTPConfig struct {
 }
 
 type JWTConfig struct {
-	SecretKey     string
-	TokenDuration time.Duration
+	SecretKey       string
+	TokenDuration   time.Duration
+	RefreshDuration time.Duration
+}
+
+// ChatConfig выбирает LLM-провайдера: openai — любой OpenAI-совместимый API
+// (OpenRouter, Ollama, llama.cpp, vLLM), stub — локальная заглушка.
+type ChatConfig struct {
+	Provider    string
+	BaseURL     string
+	Model       string
+	APIKey      string
+	Temperature float64
+	Timeout     time.Duration
+}
+
+// SimilarConfig задаёт фоновый пересчёт похожих игр; нулевой интервал
+// отключает его.
+type SimilarConfig struct {
+	RefreshInterval time.Duration
 }
 
 func New() (*Config, error) {
@@ -54,11 +75,17 @@ func New() (*Config, error) {
 
 	_ = godotenv.Load()
 
+	autoMigrate, err := strconv.ParseBool(getEnv("APP_AUTO_MIGRATE", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid APP_AUTO_MIGRATE: %w", err)
+	}
+
 	cfg.App = AppConfig{
-		Name:    getEnv("APP_NAME", "myapp"),
-		Version: getEnv("APP_VERSION", "1.0.0"),
-		Env:     getEnv("APP_ENV", "dev"),
-		Host:    getEnv("APP_HOST", "localhost"),
+		Name:        getEnv("APP_NAME", "myapp"),
+		Version:     getEnv("APP_VERSION", "1.0.0"),
+		Env:         getEnv("APP_ENV", "dev"),
+		Host:        getEnv("APP_HOST", "localhost"),
+		AutoMigrate: autoMigrate,
 	}
 
 	pgPort, err := strconv.Atoi(getEnv("PG_PORT", "5432"))
@@ -121,12 +148,49 @@ func New() (*Config, error) {
 		IdleTimeout:  idleTimeout,
 	}
 
+	tokenDuration, err := time.ParseDuration(getEnv("JWT_TOKEN_DURATION", "15m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_TOKEN_DURATION: %w", err)
+	}
+
+	refreshDuration, err := time.ParseDuration(getEnv("JWT_REFRESH_DURATION", "720h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_REFRESH_DURATION: %w", err)
+	}
+
 	cfg.JWT = JWTConfig{
-		SecretKey:     getEnv("JWT_SECRET", "secret"),
-		TokenDuration: time.Minute * 5,
+		SecretKey:       getEnv("JWT_SECRET", "secret"),
+		TokenDuration:   tokenDuration,
+		RefreshDuration: refreshDuration,
+	}
+
+	temperature, err := strconv.ParseFloat(getEnv("LLM_TEMPERATURE", "0.7"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LLM_TEMPERATURE: %w", err)
+	}
+
+	llmTimeout, err := time.ParseDuration(getEnv("LLM_TIMEOUT", "60s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LLM_TIMEOUT: %w", err)
 	}
 
-	cfg.ChatApiKey = getEnv("CHAT_API_KEY", "")
+	cfg.Chat = ChatConfig{
+		Provider:    getEnv("LLM_PROVIDER", "openai"),
+		BaseURL:     getEnv("LLM_BASE_URL", "https://openrouter.ai/api/v1"),
+		Model:       getEnv("LLM_MODEL", "meta-llama/llama-4-scout:free"),
+		APIKey:      getEnv("LLM_API_KEY", getEnv("CHAT_API_KEY", "")),
+		Temperature: temperature,
+		Timeout:     llmTimeout,
+	}
+
+	similarRefresh, err := time.ParseDuration(getEnv("SIMILAR_REFRESH_INTERVAL", "6h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SIMILAR_REFRESH_INTERVAL: %w", err)
+	}
+
+	cfg.Similar = SimilarConfig{
+		RefreshInterval: similarRefresh,
+	}
 
 	return &cfg, nil
 }
@@ -159,3 +223,19 @@ func (c *Config) ExternalAddr() string {
 	}
 	return fmt.Sprintf("%s:%d", c.App.Host, c.HTTP.Port)
 }
+
+// Redacted возвращает копию конфигурации со скрытыми секретами — для вывода
+// в консоль и логи.
+func (c Config) Redacted() Config {
+	c.Postgres.Password = redact(c.Postgres.Password)
+	c.JWT.SecretKey = redact(c.JWT.SecretKey)
+	c.Chat.APIKey = redact(c.Chat.APIKey)
+	return c
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return "***"
+}
@@ -0,0 +1,26 @@
+package config
+
+import "testing"
+
+func TestRedacted(t *testing.T) {
+	cfg := Config{
+		Postgres: PostgresConfig{User: "user", Password: "pg-secret"},
+		JWT:      JWTConfig{SecretKey: "jwt-secret"},
+		Chat:     ChatConfig{Model: "model"},
+	}
+
+	got := cfg.Redacted()
+
+	if got.Postgres.Password != "***" || got.JWT.SecretKey != "***" {
+		t.Fatalf("secrets are not redacted: %+v", got)
+	}
+	if got.Chat.APIKey != "" {
+		t.Fatalf("empty APIKey = %q, want it to stay empty", got.Chat.APIKey)
+	}
+	if got.Postgres.User != "user" || got.Chat.Model != "model" {
+		t.Fatalf("non-secret fields changed: %+v", got)
+	}
+	if cfg.Postgres.Password != "pg-secret" {
+		t.Fatal("Redacted modified the original config")
+	}
+}
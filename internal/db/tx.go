@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// TxManager выполняет несколько шагов, в том числе в разных сервисах, в одной
+// транзакции.
+type TxManager interface {
+	// WithTx вызывает fn в транзакции, которая передаётся через ctx. Если в ctx
+	// уже есть транзакция, fn выполняется в ней. Транзакция фиксируется, если
+	// fn вернула nil, и откатывается иначе.
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txKey struct{}
+
+// TxFromContext возвращает транзакцию, открытую WithTx.
+func TxFromContext(ctx context.Context) (pgx.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(pgx.Tx)
+	return tx, ok
+}
+
+// TxDB — DB, который выполняет запросы в транзакции из ctx, если она есть,
+// и в пуле иначе. Репозитории работают через него и ничего не знают о
+// транзакциях сервисов; их собственные транзакции внутри WithTx становятся
+// точками сохранения.
+type TxDB struct {
+	db DB
+}
+
+var (
+	_ DB        = (*TxDB)(nil)
+	_ TxManager = (*TxDB)(nil)
+)
+
+func NewTxDB(db DB) *TxDB {
+	return &TxDB{db: db}
+}
+
+func (t *TxDB) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := TxFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	return pgx.BeginFunc(ctx, t.db, func(tx pgx.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// Savepoint вызывает fn в точке сохранения внутри транзакции из ctx: ошибка
+// fn откатывает только сделанное в fn, и транзакцию можно продолжать. Без
+// транзакции в ctx fn вызывается как есть.
+func Savepoint(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, ok := TxFromContext(ctx)
+	if !ok {
+		return fn(ctx)
+	}
+
+	return pgx.BeginFunc(ctx, tx, func(sp pgx.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, sp))
+	})
+}
+
+func (t *TxDB) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.Exec(ctx, sql, args...)
+	}
+	return t.db.Exec(ctx, sql, args...)
+}
+
+func (t *TxDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.Query(ctx, sql, args...)
+	}
+	return t.db.Query(ctx, sql, args...)
+}
+
+func (t *TxDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.QueryRow(ctx, sql, args...)
+	}
+	return t.db.QueryRow(ctx, sql, args...)
+}
+
+func (t *TxDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.Begin(ctx)
+	}
+	return t.db.Begin(ctx)
+}
+
+// BeginTx внутри транзакции из ctx открывает точку сохранения; параметры
+// txOptions в этом случае не применяются.
+func (t *TxDB) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.Begin(ctx)
+	}
+	return t.db.BeginTx(ctx, txOptions)
+}
@@ -0,0 +1,197 @@
+// Package export пишет выгрузки в CSV или JSON построчно, не накапливая
+// записи в памяти: строки из базы сразу уходят клиенту.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+// utf8BOM в начале CSV нужен Excel, чтобы открыть кириллицу без настройки кодировки.
+const utf8BOM = "\ufeff"
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// ParseFormat разбирает формат выгрузки; по умолчанию — JSON.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case "":
+		return FormatJSON, nil
+	case FormatCSV, FormatJSON:
+		return f, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+func (f Format) ContentType() string {
+	if f == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/json; charset=utf-8"
+}
+
+// Filename добавляет к имени файла расширение формата.
+func (f Format) Filename(name string) string {
+	return name + "." + string(f)
+}
+
+// Start задаёт заголовки ответа с выгрузкой и возвращает Writer поверх w.
+// Данные уходят клиенту по мере заполнения буфера, поэтому, пока
+// w ничего не записано, ответ ещё можно заменить ошибкой, сняв заголовки
+// через ClearHeaders.
+func Start(w http.ResponseWriter, format Format, name string, columns []string) (*Writer, error) {
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", format.Filename(name)))
+	return NewWriter(w, format, columns)
+}
+
+// ClearHeaders снимает заголовки, заданные Start.
+func ClearHeaders(w http.ResponseWriter) {
+	w.Header().Del("Content-Type")
+	w.Header().Del("Content-Disposition")
+}
+
+// Writer пишет записи с фиксированным набором колонок. В CSV первая строка —
+// заголовок, в JSON выгрузка — массив объектов с ключами-колонками.
+type Writer struct {
+	format  Format
+	columns []string
+	buf     *bufio.Writer
+	csv     *csv.Writer
+	rows    int
+}
+
+// NewWriter сразу пишет заголовок, поэтому пустая выгрузка тоже корректна.
+func NewWriter(w io.Writer, format Format, columns []string) (*Writer, error) {
+	ew := &Writer{format: format, columns: columns, buf: bufio.NewWriter(w)}
+
+	switch format {
+	case FormatCSV:
+		ew.csv = csv.NewWriter(ew.buf)
+		if _, err := ew.buf.WriteString(utf8BOM); err != nil {
+			return nil, err
+		}
+		if err := ew.csv.Write(columns); err != nil {
+			return nil, err
+		}
+	case FormatJSON:
+		if _, err := ew.buf.WriteString("["); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	return ew, nil
+}
+
+// Write добавляет запись. Значения идут в порядке колонок; nil и nil-указатели
+// пишутся как пустая ячейка в CSV и null в JSON.
+func (w *Writer) Write(values ...any) error {
+	if len(values) != len(w.columns) {
+		return fmt.Errorf("export: got %d values for %d columns", len(values), len(w.columns))
+	}
+	w.rows++
+
+	if w.format == FormatCSV {
+		record := make([]string, len(values))
+		for i, v := range values {
+			record[i] = csvValue(v)
+		}
+		return w.csv.Write(record)
+	}
+
+	sep := ",\n"
+	if w.rows == 1 {
+		sep = "\n"
+	}
+	if _, err := w.buf.WriteString(sep + "{"); err != nil {
+		return err
+	}
+	// Ошибки записи в bufio.Writer запоминаются и вернутся из Close
+	for i, v := range values {
+		key, err := json.Marshal(w.columns[i])
+		if err != nil {
+			return err
+		}
+		value, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			_ = w.buf.WriteByte(',')
+		}
+		_, _ = w.buf.Write(key)
+		_ = w.buf.WriteByte(':')
+		_, _ = w.buf.Write(value)
+	}
+	return w.buf.WriteByte('}')
+}
+
+// Rows возвращает число записанных записей.
+func (w *Writer) Rows() int {
+	return w.rows
+}
+
+// Close дописывает конец выгрузки и сбрасывает буфер. Нижележащий io.Writer
+// не закрывается.
+func (w *Writer) Close() error {
+	if w.format == FormatCSV {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	} else {
+		end := "]\n"
+		if w.rows > 0 {
+			end = "\n]\n"
+		}
+		if _, err := w.buf.WriteString(end); err != nil {
+			return err
+		}
+	}
+	return w.buf.Flush()
+}
+
+func csvValue(v any) string {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return ""
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return ""
+	}
+
+	switch v := rv.Interface().(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(rv.Interface())
+}
@@ -0,0 +1,120 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriterCSV(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, FormatCSV, []string{"id", "title", "players", "level", "added_at"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	players := 4
+	added := time.Date(2026, 10, 17, 12, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	if err = w.Write(int64(1), "Каркассон, базовая", &players, 2.5, added); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Write(int64(2), "Серп", (*int)(nil), (*float64)(nil), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(buf.String(), utf8BOM) {
+		t.Fatalf("CSV does not start with BOM: %q", buf.String())
+	}
+
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(buf.String(), utf8BOM))).ReadAll()
+	if err != nil {
+		t.Fatalf("read CSV: %v", err)
+	}
+
+	want := [][]string{
+		{"id", "title", "players", "level", "added_at"},
+		{"1", "Каркассон, базовая", "4", "2.5", "2026-10-17T09:00:00Z"},
+		{"2", "Серп", "", "", ""},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Fatalf("records = %q, want %q", records, want)
+	}
+	if w.Rows() != 2 {
+		t.Fatalf("Rows() = %d, want 2", w.Rows())
+	}
+}
+
+func TestWriterJSON(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, FormatJSON, []string{"id", "title", "players"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	players := 4
+	_ = w.Write(int64(1), `Кодовые "имена"`, &players)
+	_ = w.Write(int64(2), "Серп", (*int)(nil))
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Порядок ключей совпадает с порядком колонок
+	if !strings.Contains(buf.String(), `{"id":1,"title":"Кодовые \"имена\"","players":4}`) {
+		t.Fatalf("unexpected JSON:\n%s", buf.String())
+	}
+
+	var got []map[string]any
+	if err = json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("decode JSON: %v\n%s", err, buf.String())
+	}
+	if len(got) != 2 || got[1]["players"] != nil || got[1]["title"] != "Серп" {
+		t.Fatalf("got = %v", got)
+	}
+}
+
+func TestWriterEmpty(t *testing.T) {
+	for _, format := range []Format{FormatCSV, FormatJSON} {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, format, []string{"id"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		want := utf8BOM + "id\n"
+		if format == FormatJSON {
+			want = "[]\n"
+		}
+		if buf.String() != want {
+			t.Errorf("%s: empty export = %q, want %q", format, buf.String(), want)
+		}
+	}
+}
+
+func TestWriterValueCount(t *testing.T) {
+	w, _ := NewWriter(&bytes.Buffer{}, FormatJSON, []string{"id", "title"})
+	if err := w.Write(1); err == nil {
+		t.Fatal("Write() accepted fewer values than columns")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]Format{"": FormatJSON, "CSV": FormatCSV, "json": FormatJSON} {
+		if got, err := ParseFormat(in); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("xlsx"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("ParseFormat(xlsx) error = %v, want ErrUnknownFormat", err)
+	}
+}
@@ -1,8 +1,11 @@
 package chat
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
+	"github.com/board-box/backend/internal/pagination"
 	chatSvc "github.com/board-box/backend/internal/service/chat"
 	"github.com/gin-gonic/gin"
 )
@@ -20,12 +23,20 @@ func (h *Handler) RegisterRoutes(r *gin.RouterGroup) {
 	g := r.Group("/chat")
 	g.Use(h.authMW)
 	g.POST("/", h.Chat)
+	g.POST("/stream", h.ChatStream)
+
+	g.GET("/conversations", h.ListConversations)
+	g.POST("/conversations", h.CreateConversation)
+	g.GET("/conversations/:id", h.GetConversation)
+	g.PATCH("/conversations/:id", h.RenameConversation)
+	g.DELETE("/conversations/:id", h.DeleteConversation)
 }
 
 // Chat godoc
 // @Summary Отправить сообщение в LLM
 // @Tags Chat
-// @Description Отправляет сообщение пользователя в языковую модель и возвращает ответ
+// @Description Отправляет сообщение пользователя в диалог и возвращает ответ модели. Без conversation_id создаётся новый диалог — только если модель ответила.
+// @Description Ассистент подбирает игры по каталогу BoardBox; ID упомянутых игр приходят в message.game_ids.
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer {token}"
@@ -34,6 +45,7 @@ func (h *Handler) RegisterRoutes(r *gin.RouterGroup) {
 // @Success 200 {object} ChatResponse "Ответ от LLM"
 // @Failure 400 {object} gin.H "Неверный запрос"
 // @Failure 401 {object} gin.H "Неавторизованный доступ"
+// @Failure 404 {object} gin.H "Диалог не найден"
 // @Failure 500 {object} gin.H "Внутренняя ошибка сервера"
 // @Router /chat [post]
 func (h *Handler) Chat(c *gin.Context) {
@@ -49,10 +61,301 @@ func (h *Handler) Chat(c *gin.Context) {
 		return
 	}
 
-	messages, err := h.service.Chat(c.Request.Context(), userID, req.Message)
+	conv, answer, err := h.service.Chat(c.Request.Context(), userID, req.ConversationID, req.Message)
 	if err != nil {
+		if errors.Is(err, chatSvc.ErrConversationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Диалог не найден"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ChatResponse{ConversationID: conv.ID, Message: answer})
+}
+
+// ChatStream godoc
+// @Summary Отправить сообщение в LLM с потоковым ответом
+// @Tags Chat
+// @Description Отвечает в формате Server-Sent Events. События: conversation — {conversation_id} в начале,
+// @Description delta — {content} для каждого фрагмента ответа, done — итоговый ChatResponse после сохранения,
+// @Description error — {error}, если ответ прервался. Ответ сохраняется в историю только целиком; новый диалог без ответа удаляется.
+// @Accept json
+// @Produce text/event-stream
+// @Param Authorization header string true "Bearer {token}"
+// @Param input body ChatRequest true "Входное сообщение"
+// @Security BearerAuth
+// @Success 200 {string} string "Поток событий"
+// @Failure 400 {object} gin.H "Неверный запрос"
+// @Failure 401 {object} gin.H "Неавторизованный доступ"
+// @Failure 404 {object} gin.H "Диалог не найден"
+// @Failure 500 {object} gin.H "Внутренняя ошибка сервера"
+// @Router /chat/stream [post]
+func (h *Handler) ChatStream(c *gin.Context) {
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверный идентификатор пользователя"})
+		return
+	}
+
+	var req ChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	stream := &sseObserver{c: c}
+	conv, answer, err := h.service.ChatStream(c.Request.Context(), userID, req.ConversationID, req.Message, stream)
+	if err != nil {
+		if !stream.started {
+			if errors.Is(err, chatSvc.ErrConversationNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Диалог не найден"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if c.Request.Context().Err() == nil {
+			_ = stream.send("error", gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	_ = stream.send("done", ChatResponse{ConversationID: conv.ID, Message: answer})
+}
+
+// sseObserver пересылает события потокового ответа клиенту.
+type sseObserver struct {
+	c       *gin.Context
+	started bool
+}
+
+func (o *sseObserver) Start(conv chatSvc.Conversation) error {
+	o.c.Header("Content-Type", "text/event-stream")
+	o.c.Header("Cache-Control", "no-cache")
+	o.c.Header("Connection", "keep-alive")
+	o.c.Header("X-Accel-Buffering", "no")
+	o.c.Status(http.StatusOK)
+	o.started = true
+
+	return o.send("conversation", gin.H{"conversation_id": conv.ID})
+}
+
+func (o *sseObserver) Delta(content string) error {
+	return o.send("delta", gin.H{"content": content})
+}
+
+func (o *sseObserver) send(event string, data any) error {
+	o.c.SSEvent(event, data)
+	o.c.Writer.Flush()
+	return o.c.Request.Context().Err()
+}
+
+// ListConversations godoc
+// @Summary Список диалогов
+// @Tags Chat
+// @Description Возвращает диалоги пользователя, последние активные — первыми
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param limit query int false "Размер страницы (1-100)"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Param offset query int false "Смещение (вместо курсора)"
+// @Security BearerAuth
+// @Success 200 {object} ListConversationsResponse
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /chat/conversations [get]
+func (h *Handler) ListConversations(c *gin.Context) {
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверный идентификатор пользователя"})
+		return
+	}
+
+	var page pagination.Params
+	if err := c.ShouldBindQuery(&page); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры пагинации"})
+		return
+	}
+
+	conversations, meta, err := h.service.ListConversations(c.Request.Context(), userID, page)
+	if err != nil {
+		if pagination.IsInvalid(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры пагинации"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить диалоги"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListConversationsResponse{Conversations: conversations, Meta: meta})
+}
+
+// CreateConversation godoc
+// @Summary Создать диалог
+// @Tags Chat
+// @Description Создаёт пустой диалог с ассистентом
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param input body ConversationRequest true "Название диалога"
+// @Security BearerAuth
+// @Success 201 {object} chatSvc.Conversation
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /chat/conversations [post]
+func (h *Handler) CreateConversation(c *gin.Context) {
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверный идентификатор пользователя"})
+		return
+	}
+
+	var req ConversationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	conv, err := h.service.CreateConversation(c.Request.Context(), userID, req.Title)
+	if err != nil {
+		if errors.Is(err, chatSvc.ErrEmptyTitle) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Название диалога не может быть пустым"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось создать диалог"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, conv)
+}
+
+// GetConversation godoc
+// @Summary Получить диалог
+// @Tags Chat
+// @Description Возвращает диалог со всеми сообщениями
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID диалога"
+// @Security BearerAuth
+// @Success 200 {object} chatSvc.Conversation
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /chat/conversations/{id} [get]
+func (h *Handler) GetConversation(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверный идентификатор пользователя"})
+		return
+	}
+
+	conv, err := h.service.GetConversation(c.Request.Context(), id, userID)
+	if err != nil {
+		if errors.Is(err, chatSvc.ErrConversationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Диалог не найден"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить диалог"})
+		return
+	}
+
+	c.JSON(http.StatusOK, conv)
+}
+
+// RenameConversation godoc
+// @Summary Переименовать диалог
+// @Tags Chat
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID диалога"
+// @Param input body ConversationRequest true "Новое название"
+// @Security BearerAuth
+// @Success 200 {object} chatSvc.Conversation
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /chat/conversations/{id} [patch]
+func (h *Handler) RenameConversation(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверный идентификатор пользователя"})
+		return
+	}
+
+	var req ConversationRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	conv, err := h.service.RenameConversation(c.Request.Context(), id, userID, req.Title)
+	if err != nil {
+		if errors.Is(err, chatSvc.ErrEmptyTitle) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Название диалога не может быть пустым"})
+			return
+		}
+		if errors.Is(err, chatSvc.ErrConversationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Диалог не найден"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось переименовать диалог"})
+		return
+	}
+
+	c.JSON(http.StatusOK, conv)
+}
+
+// DeleteConversation godoc
+// @Summary Удалить диалог
+// @Tags Chat
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID диалога"
+// @Security BearerAuth
+// @Success 204
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /chat/conversations/{id} [delete]
+func (h *Handler) DeleteConversation(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверный идентификатор пользователя"})
+		return
+	}
+
+	if err = h.service.DeleteConversation(c.Request.Context(), id, userID); err != nil {
+		if errors.Is(err, chatSvc.ErrConversationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Диалог не найден"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось удалить диалог"})
+		return
 	}
 
-	c.JSON(http.StatusOK, ChatResponse{Messages: messages})
+	c.Status(http.StatusNoContent)
 }
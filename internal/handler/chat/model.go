@@ -1,9 +1,25 @@
 package chat
 
+import (
+	"github.com/board-box/backend/internal/pagination"
+	chatSvc "github.com/board-box/backend/internal/service/chat"
+)
+
 type ChatRequest struct {
-	Message string `json:"message" binding:"required"`
+	ConversationID int64  `json:"conversation_id" example:"0"`
+	Message        string `json:"message" binding:"required"`
 }
 
 type ChatResponse struct {
-	Messages []string `json:"messages"`
+	ConversationID int64           `json:"conversation_id"`
+	Message        chatSvc.Message `json:"message"`
+}
+
+type ConversationRequest struct {
+	Title string `json:"title" binding:"required,max=255" example:"Игры на двоих"`
+}
+
+type ListConversationsResponse struct {
+	Conversations []chatSvc.Conversation `json:"conversations"`
+	pagination.Meta
 }
@@ -5,7 +5,10 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/board-box/backend/internal/export"
+	"github.com/board-box/backend/internal/pagination"
 	collectionSvc "github.com/board-box/backend/internal/service/collection"
+	gameSvc "github.com/board-box/backend/internal/service/game"
 	"github.com/gin-gonic/gin"
 )
 
@@ -24,22 +27,46 @@ func (h *Handler) RegisterRoutes(r *gin.RouterGroup) {
 	g.Use(h.authMW)
 
 	g.GET("/", h.ListCollections)
+	g.GET("/export", h.ExportCollections)
+	g.GET("/invites", h.ListInvites)
+	g.POST("/invites/:id/accept", h.AcceptInvite)
+	g.DELETE("/invites/:id", h.DeclineInvite)
 	g.GET("/:id", h.GetCollection)
+	g.GET("/:id/export", h.ExportCollection)
+	g.GET("/:id/games", h.ListCollectionGames)
 	g.POST("/", h.CreateCollection)
 	g.PUT("/:id", h.UpdateCollection)
 	g.DELETE("/:id", h.DeleteCollection)
 	g.POST("/:id/games/:game_id", h.AddGameToCollection)
 	g.DELETE("/:id/games/:game_id", h.RemoveGameFromCollection)
+	g.POST("/:id/share-slug", h.RotateShareSlug)
+	g.PUT("/:id/games/:game_id", h.UpdateEntry)
+	g.PUT("/:id/order", h.ReorderEntries)
+	g.POST("/:id/games/:game_id/move", h.MoveGame)
+	g.GET("/:id/members", h.ListMembers)
+	g.POST("/:id/members", h.InviteMember)
+	g.PATCH("/:id/members/:user_id", h.UpdateMemberRole)
+	g.DELETE("/:id/members/:user_id", h.RemoveMember)
+
+	// Открытые коллекции доступны без авторизации
+	shared := r.Group("/shared")
+	shared.GET("/collections/:slug", h.GetSharedCollection)
+	shared.GET("/users/:id/collections", h.ListPublicCollections)
 }
 
 // ListCollections godoc
 // @Summary Список коллекций пользователя
 // @Tags Collections
-// @Description Получить список всех коллекций текущего пользователя
+// @Description Получить страницу коллекций, в которых участвует текущий пользователь, в том числе чужих. Поле role — роль пользователя в коллекции, game_count — число игр, game_ids — первые десять из них; все игры — в /collections/{id}/games.
+// @Description Системные коллекции (owned, wishlist, want_to_play, previously_owned) создаются при регистрации.
 // @Produce json
 // @Param Authorization header string true "Bearer {token}"
+// @Param type query string false "Только коллекции этого вида" Enums(custom, owned, wishlist, want_to_play, previously_owned)
+// @Param limit query int false "Размер страницы (1-100)"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Param offset query int false "Смещение (вместо курсора)"
 // @Security BearerAuth
-// @Success 200 {array} collectionSvc.Collection
+// @Success 200 {object} ListCollectionsResponse
 // @Failure 400 {object} gin.H
 // @Failure 401 {object} gin.H
 // @Failure 500 {object} gin.H
@@ -51,18 +78,29 @@ func (h *Handler) ListCollections(c *gin.Context) {
 		return
 	}
 
-	collections, err := h.service.ListCollections(c.Request.Context(), userID)
+	var query ListCollectionsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры запроса"})
+		return
+	}
+
+	filter := collectionSvc.ListFilter{Type: collectionSvc.Type(query.Type)}
+	collections, meta, err := h.service.ListCollections(c.Request.Context(), userID, filter, query.Params)
 	if err != nil {
+		if pagination.IsInvalid(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры пагинации"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить коллекции"})
 		return
 	}
-	c.JSON(http.StatusOK, collections)
+	c.JSON(http.StatusOK, ListCollectionsResponse{Collections: collections, Meta: meta})
 }
 
 // GetCollection godoc
 // @Summary Получить коллекцию по ID
 // @Tags Collections
-// @Description Получить коллекцию по её идентификатору
+// @Description Получить коллекцию по её идентификатору. Записи entries идут по порядку коллекции и содержат данные экземпляра и игры.
 // @Produce json
 // @Param Authorization header string true "Bearer {token}"
 // @Param id path int true "ID коллекции"
@@ -98,10 +136,63 @@ func (h *Handler) GetCollection(c *gin.Context) {
 	c.JSON(http.StatusOK, collection)
 }
 
+// ListCollectionGames godoc
+// @Summary Игры коллекции
+// @Tags Collections
+// @Description Получить страницу ID игр коллекции по порядку коллекции
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID коллекции"
+// @Param limit query int false "Размер страницы (1-100)"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Param offset query int false "Смещение (вместо курсора)"
+// @Security BearerAuth
+// @Success 200 {object} ListCollectionGamesResponse
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /collections/{id}/games [get]
+func (h *Handler) ListCollectionGames(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var page pagination.Params
+	if err = c.ShouldBindQuery(&page); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры пагинации"})
+		return
+	}
+
+	gameIDs, meta, err := h.service.ListCollectionGameIDs(c.Request.Context(), id, userID, page)
+	if err != nil {
+		if errors.Is(err, collectionSvc.ErrCollectionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Коллекция не найдена"})
+			return
+		}
+		if pagination.IsInvalid(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры пагинации"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить игры коллекции"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListCollectionGamesResponse{GameIDs: gameIDs, Meta: meta})
+}
+
 // CreateCollection godoc
 // @Summary Создать новую коллекцию
 // @Tags Collections
-// @Description Создать новую коллекцию для текущего пользователя
+// @Description Создать новую коллекцию для текущего пользователя. Открытой коллекции (unlisted или public) сразу выдаётся ссылка share_slug.
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer {token}"
@@ -127,6 +218,10 @@ func (h *Handler) CreateCollection(c *gin.Context) {
 
 	collection, err := h.service.CreateCollection(c.Request.Context(), userID, convertCreateReqToDTO(req))
 	if err != nil {
+		if errors.Is(err, collectionSvc.ErrInvalidVisibility) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Видимость должна быть private, unlisted или public"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось создать коллекцию"})
 		return
 	}
@@ -137,7 +232,7 @@ func (h *Handler) CreateCollection(c *gin.Context) {
 // UpdateCollection godoc
 // @Summary Обновить коллекцию
 // @Tags Collections
-// @Description Обновить данные коллекции
+// @Description Обновить данные коллекции; только для владельцев. При первом открытии коллекции для других (unlisted или public) ей выдаётся ссылка share_slug.
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer {token}"
@@ -180,6 +275,10 @@ func (h *Handler) UpdateCollection(c *gin.Context) {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Нет прав для изменения коллекции"})
 			return
 		}
+		if errors.Is(err, collectionSvc.ErrInvalidVisibility) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Видимость должна быть private, unlisted или public"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось обновить коллекцию"})
 		return
 	}
@@ -190,7 +289,7 @@ func (h *Handler) UpdateCollection(c *gin.Context) {
 // DeleteCollection godoc
 // @Summary Удалить коллекцию
 // @Tags Collections
-// @Description Удалить коллекцию по её ID
+// @Description Удалить коллекцию по её ID; только для владельцев. Системные коллекции удалить нельзя.
 // @Produce json
 // @Param Authorization header string true "Bearer {token}"
 // @Param id path int true "ID коллекции"
@@ -224,6 +323,10 @@ func (h *Handler) DeleteCollection(c *gin.Context) {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Нет прав для удаления коллекции"})
 			return
 		}
+		if errors.Is(err, collectionSvc.ErrSystemCollection) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Системную коллекцию нельзя удалить"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось удалить коллекцию"})
 		return
 	}
@@ -234,7 +337,7 @@ func (h *Handler) DeleteCollection(c *gin.Context) {
 // AddGameToCollection godoc
 // @Summary Добавить игру в коллекцию
 // @Tags Collections
-// @Description Добавляет игру в коллекцию пользователя
+// @Description Добавляет игру в коллекцию. Нужна роль editor или owner.
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer {token}"
@@ -277,6 +380,10 @@ func (h *Handler) AddGameToCollection(c *gin.Context) {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Нет прав на изменение коллекции"})
 			return
 		}
+		if errors.Is(err, gameSvc.ErrGameNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Игра не найдена"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось добавить игру в коллекцию"})
 		return
 	}
@@ -284,10 +391,211 @@ func (h *Handler) AddGameToCollection(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// UpdateEntry godoc
+// @Summary Изменить запись коллекции
+// @Tags Collections
+// @Description Заменяет данные экземпляра игры в коллекции: заметку, состояние, дату и цену покупки, кому одолжена, дополнения, а в списке желаний — приоритет. Нужна роль editor или owner.
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID коллекции"
+// @Param game_id path int true "ID игры"
+// @Param input body UpdateEntryRequest true "Данные экземпляра"
+// @Security BearerAuth
+// @Success 200 {object} collectionSvc.Entry
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /collections/{id}/games/{game_id} [put]
+func (h *Handler) UpdateEntry(c *gin.Context) {
+	collectionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат id"})
+		return
+	}
+
+	gameID, err := strconv.ParseInt(c.Param("game_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат game_id"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req UpdateEntryRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	entry, err := convertUpdateEntryReqToDTO(gameID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Дата покупки должна быть в формате YYYY-MM-DD"})
+		return
+	}
+
+	entry, err = h.service.UpdateEntry(c.Request.Context(), collectionID, userID, entry)
+	if err != nil {
+		if errors.Is(err, collectionSvc.ErrCollectionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Коллекция не найдена"})
+			return
+		}
+		if errors.Is(err, collectionSvc.ErrEntryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Игры нет в коллекции"})
+			return
+		}
+		if errors.Is(err, collectionSvc.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Нет прав на изменение коллекции"})
+			return
+		}
+		if errors.Is(err, collectionSvc.ErrInvalidEntry) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось изменить запись коллекции"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// ReorderEntries godoc
+// @Summary Изменить порядок игр
+// @Tags Collections
+// @Description Расставляет игры коллекции в порядке game_ids. Список должен содержать каждую игру коллекции ровно один раз. Нужна роль editor или owner.
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID коллекции"
+// @Param input body ReorderRequest true "Новый порядок игр"
+// @Security BearerAuth
+// @Success 200 {object} collectionSvc.Collection
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /collections/{id}/order [put]
+func (h *Handler) ReorderEntries(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req ReorderRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	collection, err := h.service.ReorderEntries(c.Request.Context(), id, userID, req.GameIDs)
+	if err != nil {
+		if errors.Is(err, collectionSvc.ErrCollectionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Коллекция не найдена"})
+			return
+		}
+		if errors.Is(err, collectionSvc.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Нет прав на изменение коллекции"})
+			return
+		}
+		if errors.Is(err, collectionSvc.ErrInvalidOrder) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Укажите каждую игру коллекции ровно один раз"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось изменить порядок игр"})
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// MoveGame godoc
+// @Summary Перенести игру в другую коллекцию
+// @Tags Collections
+// @Description Переносит игру вместе с данными экземпляра в конец другой коллекции в одной транзакции, например из списка желаний в свою коллекцию.
+// @Description Цель — to_collection_id или to_type, вид своей системной коллекции. Нужна роль editor или owner в обеих коллекциях.
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID коллекции, из которой переносится игра"
+// @Param game_id path int true "ID игры"
+// @Param input body MoveGameRequest true "Куда перенести"
+// @Security BearerAuth
+// @Success 200 {object} collectionSvc.Collection
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /collections/{id}/games/{game_id}/move [post]
+func (h *Handler) MoveGame(c *gin.Context) {
+	collectionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат id"})
+		return
+	}
+
+	gameID, err := strconv.ParseInt(c.Param("game_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат game_id"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req MoveGameRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	to := collectionSvc.MoveTarget{CollectionID: req.ToCollectionID, Type: collectionSvc.Type(req.ToType)}
+	collection, err := h.service.MoveGame(c.Request.Context(), collectionID, gameID, userID, to)
+	if err != nil {
+		switch {
+		case errors.Is(err, collectionSvc.ErrCollectionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Коллекция не найдена"})
+		case errors.Is(err, collectionSvc.ErrEntryNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Игры нет в коллекции"})
+		case errors.Is(err, collectionSvc.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Нет прав на изменение коллекции"})
+		case errors.Is(err, collectionSvc.ErrInvalidType):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Укажите to_collection_id или вид системной коллекции to_type"})
+		case errors.Is(err, collectionSvc.ErrSameCollection):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Игра уже в этой коллекции"})
+		case errors.Is(err, collectionSvc.ErrAlreadyInTarget):
+			c.JSON(http.StatusConflict, gin.H{"error": "Игра уже есть в целевой коллекции"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось перенести игру"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
 // RemoveGameFromCollection godoc
 // @Summary Удалить игру из коллекции
 // @Tags Collections
-// @Description Удаляет игру из коллекции пользователя
+// @Description Удаляет игру из коллекции. Нужна роль editor или owner.
 // @Produce json
 // @Param Authorization header string true "Bearer {token}"
 // @Param id path int true "ID коллекции"
@@ -335,3 +643,504 @@ func (h *Handler) RemoveGameFromCollection(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// ExportCollections godoc
+// @Summary Выгрузка всех коллекций
+// @Tags Collections
+// @Description Выгружает игры всех коллекций пользователя в CSV или JSON: одна строка на пару коллекция-игра. Пустые коллекции в выгрузку не попадают.
+// @Produce json
+// @Produce text/csv
+// @Param Authorization header string true "Bearer {token}"
+// @Param format query string false "Формат: json (по умолчанию) или csv"
+// @Security BearerAuth
+// @Success 200 {array} ExportRow
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /collections/export [get]
+func (h *Handler) ExportCollections(c *gin.Context) {
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	h.export(c, userID, 0, "collections")
+}
+
+// ExportCollection godoc
+// @Summary Выгрузка коллекции
+// @Tags Collections
+// @Description Выгружает игры коллекции в CSV или JSON по порядку коллекции.
+// @Produce json
+// @Produce text/csv
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID коллекции"
+// @Param format query string false "Формат: json (по умолчанию) или csv"
+// @Security BearerAuth
+// @Success 200 {array} ExportRow
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /collections/{id}/export [get]
+func (h *Handler) ExportCollection(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	h.export(c, userID, id, "collection-"+strconv.FormatInt(id, 10))
+}
+
+// export отправляет выгрузку потоком: строки из базы пишутся в ответ сразу.
+func (h *Handler) export(c *gin.Context, userID, collectionID int64, filename string) {
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Формат выгрузки должен быть csv или json"})
+		return
+	}
+
+	ew, err := export.Start(c.Writer, format, filename, exportColumns)
+	if err == nil {
+		err = h.service.Export(c.Request.Context(), userID, collectionID, func(row collectionSvc.ExportRow) error {
+			return ew.Write(exportValues(row)...)
+		})
+	}
+	if err == nil {
+		err = ew.Close()
+	}
+	if err == nil {
+		return
+	}
+
+	// Если часть выгрузки уже отправлена, статус не поменять — обрываем ответ
+	if c.Writer.Written() {
+		_ = c.Error(err)
+		c.Abort()
+		return
+	}
+
+	export.ClearHeaders(c.Writer)
+	if errors.Is(err, collectionSvc.ErrCollectionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Коллекция не найдена"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось выгрузить коллекции"})
+}
+
+// RotateShareSlug godoc
+// @Summary Сменить ссылку на коллекцию
+// @Tags Collections
+// @Description Выдаёт коллекции новую ссылку share_slug, старая сразу перестаёт работать. Только для владельцев. Ссылка открывает коллекцию, только если она не личная.
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID коллекции"
+// @Security BearerAuth
+// @Success 200 {object} collectionSvc.Collection
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /collections/{id}/share-slug [post]
+func (h *Handler) RotateShareSlug(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	collection, err := h.service.RotateShareSlug(c.Request.Context(), id, userID)
+	if err != nil {
+		if errors.Is(err, collectionSvc.ErrCollectionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Коллекция не найдена"})
+			return
+		}
+		if errors.Is(err, collectionSvc.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Нет прав для изменения коллекции"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось сменить ссылку"})
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// GetSharedCollection godoc
+// @Summary Коллекция по ссылке
+// @Tags Shared
+// @Description Коллекция, открытая владельцем по ссылке (unlisted или public), с полными данными игр по порядку коллекции. Авторизация не нужна.
+// @Produce json
+// @Param slug path string true "Адрес ссылки share_slug"
+// @Success 200 {object} collectionSvc.SharedCollection
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /shared/collections/{slug} [get]
+func (h *Handler) GetSharedCollection(c *gin.Context) {
+	collection, err := h.service.GetSharedCollection(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		if errors.Is(err, collectionSvc.ErrCollectionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Коллекция не найдена"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить коллекцию"})
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// ListPublicCollections godoc
+// @Summary Публичные коллекции пользователя
+// @Tags Shared
+// @Description Страница публичных коллекций пользователя. Коллекции, доступные только по ссылке (unlisted), в список не попадают. Авторизация не нужна.
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param limit query int false "Размер страницы (1-100)"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Param offset query int false "Смещение (вместо курсора)"
+// @Success 200 {object} ListCollectionsResponse
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /shared/users/{id}/collections [get]
+func (h *Handler) ListPublicCollections(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	var page pagination.Params
+	if err = c.ShouldBindQuery(&page); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры пагинации"})
+		return
+	}
+
+	collections, meta, err := h.service.ListPublicCollections(c.Request.Context(), userID, page)
+	if err != nil {
+		if pagination.IsInvalid(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры пагинации"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить коллекции"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListCollectionsResponse{Collections: collections, Meta: meta})
+}
+
+// ListMembers godoc
+// @Summary Участники коллекции
+// @Tags Collections
+// @Description Участники коллекции и приглашённые, которые ещё не приняли приглашение (accepted_at пуст). Доступно любому участнику.
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID коллекции"
+// @Security BearerAuth
+// @Success 200 {object} ListMembersResponse
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /collections/{id}/members [get]
+func (h *Handler) ListMembers(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	members, err := h.service.ListMembers(c.Request.Context(), id, userID)
+	if err != nil {
+		memberError(c, err, "Не удалось получить участников")
+		return
+	}
+
+	c.JSON(http.StatusOK, ListMembersResponse{Members: members})
+}
+
+// InviteMember godoc
+// @Summary Пригласить в коллекцию
+// @Tags Collections
+// @Description Приглашает пользователя по username или email. Участником он станет, когда примет приглашение. Только для владельцев.
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID коллекции"
+// @Param input body InviteMemberRequest true "Кого и с какой ролью пригласить"
+// @Security BearerAuth
+// @Success 201 {object} collectionSvc.Member
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /collections/{id}/members [post]
+func (h *Handler) InviteMember(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req InviteMemberRequest
+	if err = c.ShouldBindJSON(&req); err != nil || (req.Username == "") == (req.Email == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Укажите username или email пользователя"})
+		return
+	}
+
+	invitee := collectionSvc.Invitee{Username: req.Username, Email: req.Email}
+	member, err := h.service.InviteMember(c.Request.Context(), id, userID, invitee, collectionSvc.Role(req.Role))
+	if err != nil {
+		memberError(c, err, "Не удалось пригласить пользователя")
+		return
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+// UpdateMemberRole godoc
+// @Summary Изменить роль участника
+// @Tags Collections
+// @Description Меняет роль участника или приглашённого. Только для владельцев; роль создателя коллекции не меняется.
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID коллекции"
+// @Param user_id path int true "ID участника"
+// @Param input body UpdateMemberRequest true "Новая роль"
+// @Security BearerAuth
+// @Success 200 {object} collectionSvc.Member
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /collections/{id}/members/{user_id} [patch]
+func (h *Handler) UpdateMemberRole(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	memberID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат user_id"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req UpdateMemberRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Роль должна быть viewer, editor или owner"})
+		return
+	}
+
+	member, err := h.service.UpdateMemberRole(c.Request.Context(), id, userID, memberID, collectionSvc.Role(req.Role))
+	if err != nil {
+		memberError(c, err, "Не удалось изменить роль")
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveMember godoc
+// @Summary Исключить участника
+// @Tags Collections
+// @Description Исключает участника или отзывает приглашение; только для владельцев. Любой участник может выйти из коллекции, указав свой ID. Создателя коллекции исключить нельзя.
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID коллекции"
+// @Param user_id path int true "ID участника"
+// @Security BearerAuth
+// @Success 204
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /collections/{id}/members/{user_id} [delete]
+func (h *Handler) RemoveMember(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	memberID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат user_id"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err = h.service.RemoveMember(c.Request.Context(), id, userID, memberID); err != nil {
+		memberError(c, err, "Не удалось исключить участника")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListInvites godoc
+// @Summary Приглашения в коллекции
+// @Tags Collections
+// @Description Непринятые приглашения текущего пользователя, новые первыми
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Security BearerAuth
+// @Success 200 {object} ListInvitesResponse
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /collections/invites [get]
+func (h *Handler) ListInvites(c *gin.Context) {
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	invites, err := h.service.ListInvites(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить приглашения"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListInvitesResponse{Invites: invites})
+}
+
+// AcceptInvite godoc
+// @Summary Принять приглашение
+// @Tags Collections
+// @Description Принимает приглашение в коллекцию и возвращает её
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID коллекции"
+// @Security BearerAuth
+// @Success 200 {object} collectionSvc.Collection
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /collections/invites/{id}/accept [post]
+func (h *Handler) AcceptInvite(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	collection, err := h.service.AcceptInvite(c.Request.Context(), id, userID)
+	if err != nil {
+		memberError(c, err, "Не удалось принять приглашение")
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// DeclineInvite godoc
+// @Summary Отклонить приглашение
+// @Tags Collections
+// @Description Отклоняет приглашение в коллекцию
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID коллекции"
+// @Security BearerAuth
+// @Success 204
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /collections/invites/{id} [delete]
+func (h *Handler) DeclineInvite(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err = h.service.DeclineInvite(c.Request.Context(), id, userID); err != nil {
+		memberError(c, err, "Не удалось отклонить приглашение")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// memberError отвечает на ошибку операций с участниками; fallback — текст
+// для неожиданных ошибок.
+func memberError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, collectionSvc.ErrCollectionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Коллекция не найдена"})
+	case errors.Is(err, collectionSvc.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Пользователь не найден"})
+	case errors.Is(err, collectionSvc.ErrMemberNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Участник не найден"})
+	case errors.Is(err, collectionSvc.ErrInviteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Приглашение не найдено"})
+	case errors.Is(err, collectionSvc.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Нет прав на управление участниками"})
+	case errors.Is(err, collectionSvc.ErrCreator):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Создателя коллекции нельзя исключить или лишить роли владельца"})
+	case errors.Is(err, collectionSvc.ErrAlreadyMember):
+		c.JSON(http.StatusConflict, gin.H{"error": "Пользователь уже участник или приглашён"})
+	case errors.Is(err, collectionSvc.ErrInvalidRole):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Роль должна быть viewer, editor или owner"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
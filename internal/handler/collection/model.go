@@ -1,25 +1,160 @@
 package collection
 
-import collectionSvc "github.com/board-box/backend/internal/service/collection"
+import (
+	"time"
+
+	"github.com/board-box/backend/internal/pagination"
+	collectionSvc "github.com/board-box/backend/internal/service/collection"
+)
 
 type CreateCollectionRequest struct {
 	Name string `json:"name" example:"my collection"`
+	// Visibility — private (по умолчанию), unlisted или public
+	Visibility string `json:"visibility" binding:"omitempty,oneof=private unlisted public" example:"private"`
 }
 
 type UpdateCollectionRequest struct {
 	Name   string `json:"name" example:"my collection"`
 	Pinned bool   `json:"pinned" example:"false"`
+	// Visibility — private, unlisted или public; если не указана, не меняется
+	Visibility string `json:"visibility" binding:"omitempty,oneof=private unlisted public" example:"unlisted"`
+}
+
+// InviteMemberRequest — кого пригласить: нужно указать username или email.
+type InviteMemberRequest struct {
+	Username string `json:"username" example:"alice"`
+	Email    string `json:"email" binding:"omitempty,email" example:"alice@example.com"`
+	// Role — viewer (по умолчанию), editor или owner
+	Role string `json:"role" binding:"omitempty,oneof=viewer editor owner" example:"editor"`
+}
+
+type UpdateMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=viewer editor owner" example:"viewer"`
+}
+
+type ListMembersResponse struct {
+	Members []collectionSvc.Member `json:"members"`
+}
+
+type ListInvitesResponse struct {
+	Invites []collectionSvc.Invite `json:"invites"`
+}
+
+// UpdateEntryRequest заменяет данные экземпляра игры целиком: незаданные
+// поля очищаются.
+type UpdateEntryRequest struct {
+	Note *string `json:"note" example:"Не хватает одного жетона"`
+	// Condition — new, like_new, good, fair или poor
+	Condition *string `json:"condition" binding:"omitempty,oneof=new like_new good fair poor" example:"good"`
+	// PurchaseDate — дата покупки в формате YYYY-MM-DD
+	PurchaseDate *string  `json:"purchase_date" binding:"omitempty,datetime=2006-01-02" example:"2024-05-01"`
+	Price        *float64 `json:"price" binding:"omitempty,gte=0" example:"2490"`
+	LentOut      bool     `json:"lent_out" example:"true"`
+	LentTo       *string  `json:"lent_to" binding:"omitempty,max=255" example:"Миша"`
+	// Expansions — названия дополнений, которые есть вместе с игрой
+	Expansions []string `json:"expansions" example:"Охотники и собиратели"`
+	// Priority — приоритет в списке желаний от 1 (купить в первую очередь) до 5
+	Priority *int `json:"priority" binding:"omitempty,min=1,max=5" example:"1"`
+}
+
+// MoveGameRequest — куда перенести игру: нужно указать to_collection_id или to_type.
+type MoveGameRequest struct {
+	ToCollectionID int64 `json:"to_collection_id" binding:"omitempty,min=1" example:"12"`
+	// ToType — вид своей системной коллекции: owned, wishlist, want_to_play или previously_owned
+	ToType string `json:"to_type" binding:"omitempty,oneof=owned wishlist want_to_play previously_owned" example:"owned"`
+}
+
+// ReorderRequest — игры коллекции в новом порядке, каждая ровно один раз.
+type ReorderRequest struct {
+	GameIDs []int64 `json:"game_ids" example:"3,1,2"`
+}
+
+type ListCollectionsQuery struct {
+	pagination.Params
+	Type string `form:"type" binding:"omitempty,oneof=custom owned wishlist want_to_play previously_owned"`
+}
+
+type ListCollectionsResponse struct {
+	Collections []collectionSvc.Collection `json:"collections"`
+	pagination.Meta
+}
+
+type ListCollectionGamesResponse struct {
+	GameIDs []int64 `json:"game_ids"`
+	pagination.Meta
 }
 
 func convertCreateReqToDTO(req CreateCollectionRequest) collectionSvc.Collection {
 	return collectionSvc.Collection{
-		Name: req.Name,
+		Name:       req.Name,
+		Visibility: collectionSvc.Visibility(req.Visibility),
 	}
 }
 
 func convertUpdateReqToDTO(req UpdateCollectionRequest) collectionSvc.Collection {
 	return collectionSvc.Collection{
-		Name:   req.Name,
-		Pinned: req.Pinned,
+		Name:       req.Name,
+		Pinned:     req.Pinned,
+		Visibility: collectionSvc.Visibility(req.Visibility),
+	}
+}
+
+// ExportRow описывает строку выгрузки коллекций для документации; в CSV
+// колонки идут в том же порядке.
+type ExportRow struct {
+	CollectionID    int64    `json:"collection_id"`
+	Collection      string   `json:"collection"`
+	AddedAt         string   `json:"added_at" example:"2026-10-17T12:00:00Z"`
+	GameID          int64    `json:"game_id"`
+	Title           string   `json:"title"`
+	Genre           string   `json:"genre"`
+	Person          string   `json:"person"`
+	AvgTime         string   `json:"avg_time"`
+	Age             string   `json:"age"`
+	Difficulty      string   `json:"complexity"`
+	Image           string   `json:"image"`
+	MinPlayers      *int     `json:"min_players"`
+	MaxPlayers      *int     `json:"max_players"`
+	MinPlayTime     *int     `json:"min_play_time"`
+	MaxPlayTime     *int     `json:"max_play_time"`
+	MinAge          *int     `json:"min_age"`
+	DifficultyLevel *float64 `json:"complexity_level"`
+	ExternalID      *string  `json:"external_id"`
+}
+
+var exportColumns = []string{
+	"collection_id", "collection", "added_at", "game_id", "title", "genre", "person", "avg_time", "age", "complexity", "image",
+	"min_players", "max_players", "min_play_time", "max_play_time", "min_age", "complexity_level", "external_id",
+}
+
+func exportValues(row collectionSvc.ExportRow) []any {
+	g := row.Game
+	return []any{
+		row.CollectionID, row.CollectionName, row.AddedAt, g.ID, g.Title, g.Genre, g.Person, g.AvgTime, g.Age, g.Difficulty, g.Image,
+		g.MinPlayers, g.MaxPlayers, g.MinPlayTime, g.MaxPlayTime, g.MinAge, g.DifficultyLevel, g.ExternalID,
+	}
+}
+
+func convertUpdateEntryReqToDTO(gameID int64, req UpdateEntryRequest) (collectionSvc.Entry, error) {
+	entry := collectionSvc.Entry{
+		GameID:     gameID,
+		Note:       req.Note,
+		Price:      req.Price,
+		LentOut:    req.LentOut,
+		LentTo:     req.LentTo,
+		Expansions: req.Expansions,
+		Priority:   req.Priority,
+	}
+	if req.Condition != nil {
+		condition := collectionSvc.Condition(*req.Condition)
+		entry.Condition = &condition
+	}
+	if req.PurchaseDate != nil {
+		date, err := time.Parse(time.DateOnly, *req.PurchaseDate)
+		if err != nil {
+			return collectionSvc.Entry{}, err
+		}
+		entry.PurchaseDate = &date
 	}
+	return entry, nil
 }
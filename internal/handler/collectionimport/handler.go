@@ -0,0 +1,118 @@
+package collectionimport
+
+import (
+	"errors"
+	"net/http"
+
+	importSvc "github.com/board-box/backend/internal/service/collectionimport"
+	"github.com/gin-gonic/gin"
+)
+
+// maxUploadSize ограничивает размер загружаемого файла.
+const maxUploadSize = 8 << 20
+
+type Handler struct {
+	service *importSvc.Service
+	authMW  func(c *gin.Context)
+}
+
+func New(service *importSvc.Service, authMW func(c *gin.Context)) *Handler {
+	return &Handler{service: service, authMW: authMW}
+}
+
+func (h *Handler) RegisterRoutes(r *gin.RouterGroup) {
+	g := r.Group("/collections", h.authMW)
+	g.POST("/import", h.Import)
+}
+
+// Import godoc
+// @Summary Импорт коллекции из другого приложения
+// @Tags Collections
+// @Description Сопоставляет игры из выгрузки коллекции BoardGameGeek (CSV или XML API2) или BoardBox (CSV или JSON) с каталогом: по ID игры, внешнему ID и названию, в том числе похожему. Без confirm возвращает отчёт с найденными (matched), неоднозначными (ambiguous) и ненайденными (unmatched) записями и ничего не сохраняет. С confirm=true создаёт коллекцию из найденных игр; для неоднозначных и ненайденных записей игру можно выбрать в choose, остальные пропускаются.
+// @Accept multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param file formData file true "Файл с коллекцией"
+// @Param format formData string false "Формат: csv, json или bgg; по умолчанию по расширению файла"
+// @Param name formData string false "Название коллекции; по умолчанию из файла"
+// @Param confirm formData bool false "Создать коллекцию"
+// @Param choose formData string false "Выбор игр для записей: номер записи=ID игры, например 3=17,5=42"
+// @Security BearerAuth
+// @Success 200 {object} importSvc.Report "Отчёт без сохранения"
+// @Success 201 {object} importSvc.Report "Коллекция создана"
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 413 {object} gin.H
+// @Failure 422 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /collections/import [post]
+func (h *Handler) Import(c *gin.Context) {
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadSize)
+
+	var req ImportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Файл слишком большой"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры импорта"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Файл обязателен"})
+		return
+	}
+
+	format, err := importSvc.ParseFormat(req.Format, fileHeader.Filename)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестный формат файла, укажите csv, json или bgg"})
+		return
+	}
+
+	choices, err := importSvc.ParseChoices(req.Choose)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный выбор игр"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось прочитать файл"})
+		return
+	}
+	defer file.Close()
+
+	report, err := h.service.Import(c.Request.Context(), userID, file, importSvc.Options{
+		Format:   format,
+		Name:     req.Name,
+		Filename: fileHeader.Filename,
+		Confirm:  req.Confirm,
+		Choices:  choices,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, importSvc.ErrInvalidFile) || errors.Is(err, importSvc.ErrInvalidChoice):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, importSvc.ErrNothingMatched):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Ни одна игра из файла не найдена в каталоге"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось импортировать коллекцию"})
+		}
+		return
+	}
+
+	if report.Confirmed {
+		c.JSON(http.StatusCreated, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,11 @@
+package collectionimport
+
+type ImportRequest struct {
+	// Format — csv, json или bgg; по умолчанию определяется по расширению файла
+	Format string `form:"format"`
+	Name   string `form:"name" binding:"max=255"`
+	// Confirm создаёт коллекцию; без него возвращается только отчёт
+	Confirm bool `form:"confirm"`
+	// Choose — игры для неоднозначных и ненайденных записей: "3=17,5=42"
+	Choose string `form:"choose"`
+}
@@ -5,16 +5,20 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/board-box/backend/internal/export"
+	"github.com/board-box/backend/internal/pagination"
 	gameSvc "github.com/board-box/backend/internal/service/game"
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
 	service *gameSvc.Service
+	authMW  func(c *gin.Context)
+	adminMW func(c *gin.Context)
 }
 
-func New(service *gameSvc.Service) *Handler {
-	return &Handler{service}
+func New(service *gameSvc.Service, authMW, adminMW func(c *gin.Context)) *Handler {
+	return &Handler{service: service, authMW: authMW, adminMW: adminMW}
 }
 
 func (h *Handler) RegisterRoutes(r *gin.RouterGroup) {
@@ -22,23 +26,68 @@ func (h *Handler) RegisterRoutes(r *gin.RouterGroup) {
 	g.GET("/", h.ListGames)
 	g.GET("/:id", h.GetGame)
 	g.POST("/by-ids", h.GetGamesByIDs)
+
+	admin := g.Group("", h.authMW, h.adminMW)
+	admin.POST("/", h.CreateGame)
+	admin.PUT("/:id", h.UpdateGame)
+	admin.PATCH("/:id", h.PatchGame)
+	admin.DELETE("/:id", h.DeleteGame)
+
+	r.Group("/admin/games", h.authMW, h.adminMW).GET("/export", h.ExportGames)
 }
 
 // ListGames godoc
 // @Summary Список игр
 // @Tags Games
-// @Description Получить список всех настольных игр
+// @Description Поиск и фильтрация настольных игр с подсчётом фасетов. Параметры-списки можно передавать несколько раз.
+// @Description Ключ фасета совпадает с параметром, в который можно передать его значение; complexity_level — округлённая сложность для complexity_min/complexity_max.
+// @Description Параметры age, person, avg_time и complexity устарели: они сравнивают строки целиком, вместо них используйте player_age, players, max_time и complexity_min/complexity_max.
 // @Produce json
-// @Success 200 {array} gameSvc.Game
+// @Param q query string false "Полнотекстовый поиск по названию и описанию"
+// @Param genre query []string false "Жанр" collectionFormat(multi)
+// @Param players query int false "Количество игроков, для которого подходит игра"
+// @Param max_time query int false "Максимальное время партии в минутах"
+// @Param player_age query int false "Возраст самого младшего игрока"
+// @Param complexity_min query number false "Минимальная сложность (1-5)"
+// @Param complexity_max query number false "Максимальная сложность (1-5)"
+// @Param age query []string false "Устарело: возраст, точное значение" collectionFormat(multi)
+// @Param person query []string false "Устарело: количество игроков, точное значение" collectionFormat(multi)
+// @Param avg_time query []string false "Устарело: время партии, точное значение" collectionFormat(multi)
+// @Param complexity query []string false "Устарело: сложность, точное значение" collectionFormat(multi)
+// @Param sort query string false "Сортировка: title (по умолчанию), rating, rating_count, score" Enums(title, rating, rating_count, score)
+// @Param limit query int false "Размер страницы (1-100)"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Param offset query int false "Смещение (вместо курсора)"
+// @Success 200 {object} ListGamesResponse
+// @Failure 400 {object} gin.H
 // @Failure 500 {object} gin.H
 // @Router /games/ [get]
 func (h *Handler) ListGames(c *gin.Context) {
-	games, err := h.service.ListGames(c.Request.Context())
+	var req ListGamesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры фильтра"})
+		return
+	}
+
+	filter := convertListReqToFilter(req)
+
+	games, meta, err := h.service.ListGames(c.Request.Context(), filter, req.Params)
 	if err != nil {
+		if pagination.IsInvalid(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры пагинации"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить игры"})
 		return
 	}
-	c.JSON(http.StatusOK, games)
+
+	facets, err := h.service.ListFacets(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить фасеты"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListGamesResponse{Games: games, Facets: facets, Meta: meta})
 }
 
 // GetGame godoc
@@ -102,3 +151,220 @@ func (h *Handler) GetGamesByIDs(c *gin.Context) {
 
 	c.JSON(http.StatusOK, games)
 }
+
+// CreateGame godoc
+// @Summary Добавить игру
+// @Tags Games
+// @Description Добавляет игру в каталог. Доступно только администраторам.
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param input body GameRequest true "Данные игры"
+// @Security BearerAuth
+// @Success 201 {object} gameSvc.Game
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /games/ [post]
+func (h *Handler) CreateGame(c *gin.Context) {
+	var req GameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	id, err := h.service.CreateGame(c.Request.Context(), convertGameReqToDTO(req))
+	if err != nil {
+		if errors.Is(err, gameSvc.ErrInvalidGame) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось создать игру"})
+		return
+	}
+
+	game, err := h.service.GetGame(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить игру"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, game)
+}
+
+// UpdateGame godoc
+// @Summary Обновить игру
+// @Tags Games
+// @Description Полностью заменяет данные игры. Доступно только администраторам.
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID игры"
+// @Param input body GameRequest true "Данные игры"
+// @Security BearerAuth
+// @Success 200 {object} gameSvc.Game
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /games/{id} [put]
+func (h *Handler) UpdateGame(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID должен быть int"})
+		return
+	}
+
+	var req GameRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	game := convertGameReqToDTO(req)
+	game.ID = id
+
+	if err = h.service.UpdateGame(c.Request.Context(), game); err != nil {
+		h.writeUpdateError(c, err)
+		return
+	}
+
+	game, err = h.service.GetGame(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить игру"})
+		return
+	}
+
+	c.JSON(http.StatusOK, game)
+}
+
+// PatchGame godoc
+// @Summary Частично обновить игру
+// @Tags Games
+// @Description Обновляет только переданные поля игры. Доступно только администраторам.
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID игры"
+// @Param input body PatchGameRequest true "Изменяемые поля"
+// @Security BearerAuth
+// @Success 200 {object} gameSvc.Game
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /games/{id} [patch]
+func (h *Handler) PatchGame(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID должен быть int"})
+		return
+	}
+
+	var req PatchGameRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	game, err := h.service.PatchGame(c.Request.Context(), id, convertPatchReqToDTO(req))
+	if err != nil {
+		h.writeUpdateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, game)
+}
+
+// DeleteGame godoc
+// @Summary Удалить игру
+// @Tags Games
+// @Description Удаляет игру из каталога. Доступно только администраторам.
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID игры"
+// @Security BearerAuth
+// @Success 204
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /games/{id} [delete]
+func (h *Handler) DeleteGame(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID должен быть int"})
+		return
+	}
+
+	if err = h.service.DeleteGame(c.Request.Context(), id); err != nil {
+		if errors.Is(err, gameSvc.ErrGameNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Игра не найдена"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось удалить игру"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) writeUpdateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gameSvc.ErrInvalidGame):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, gameSvc.ErrGameNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Игра не найдена"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось обновить игру"})
+	}
+}
+
+// ExportGames godoc
+// @Summary Выгрузка каталога
+// @Tags Games
+// @Description Выгружает весь каталог игр в CSV или JSON потоком, не загружая таблицу в память. Колонки совпадают с полями игры, поэтому файл можно снова загрузить через импорт. Только для администраторов.
+// @Produce json
+// @Produce text/csv
+// @Param Authorization header string true "Bearer {token}"
+// @Param format query string false "Формат: json (по умолчанию) или csv"
+// @Success 200 {array} gameSvc.Game
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/games/export [get]
+func (h *Handler) ExportGames(c *gin.Context) {
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Формат выгрузки должен быть csv или json"})
+		return
+	}
+
+	ew, err := export.Start(c.Writer, format, "games", exportColumns)
+	if err == nil {
+		err = h.service.ExportGames(c.Request.Context(), func(g gameSvc.Game) error {
+			return ew.Write(exportValues(g)...)
+		})
+	}
+	if err == nil {
+		err = ew.Close()
+	}
+	if err == nil {
+		return
+	}
+
+	// Если часть выгрузки уже отправлена, статус не поменять — обрываем ответ
+	if c.Writer.Written() {
+		_ = c.Error(err)
+		c.Abort()
+		return
+	}
+
+	export.ClearHeaders(c.Writer)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось выгрузить каталог"})
+}
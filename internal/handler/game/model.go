@@ -1,5 +1,140 @@
 package handler
 
+import (
+	"github.com/board-box/backend/internal/pagination"
+	gameSvc "github.com/board-box/backend/internal/service/game"
+)
+
 type GetGamesByIDsRequest struct {
 	IDs []int64 `json:"ids" binding:"required,min=1"`
 }
+
+type ListGamesRequest struct {
+	pagination.Params
+	Query         string   `form:"q"`
+	Genres        []string `form:"genre"`
+	Players       int      `form:"players" binding:"omitempty,min=1"`
+	MaxTime       int      `form:"max_time" binding:"omitempty,min=1"`
+	PlayerAge     int      `form:"player_age" binding:"omitempty,min=1"`
+	ComplexityMin float64  `form:"complexity_min" binding:"omitempty,min=1,max=5"`
+	ComplexityMax float64  `form:"complexity_max" binding:"omitempty,min=1,max=5"`
+	Sort          string   `form:"sort" binding:"omitempty,oneof=title rating rating_count score"`
+
+	// Устаревшие фильтры по точному значению строк
+	Ages         []string `form:"age"`
+	Persons      []string `form:"person"`
+	AvgTimes     []string `form:"avg_time"`
+	Difficulties []string `form:"complexity"`
+}
+
+type ListGamesResponse struct {
+	Games  []gameSvc.Game `json:"games"`
+	Facets gameSvc.Facets `json:"facets"`
+	pagination.Meta
+}
+
+func convertListReqToFilter(req ListGamesRequest) gameSvc.ListFilter {
+	return gameSvc.ListFilter{
+		Query:         req.Query,
+		Genres:        req.Genres,
+		Players:       req.Players,
+		MaxTime:       req.MaxTime,
+		PlayerAge:     req.PlayerAge,
+		ComplexityMin: req.ComplexityMin,
+		ComplexityMax: req.ComplexityMax,
+		Sort:          gameSvc.Sort(req.Sort),
+		Ages:          req.Ages,
+		Persons:       req.Persons,
+		AvgTimes:      req.AvgTimes,
+		Difficulties:  req.Difficulties,
+	}
+}
+
+type GameRequest struct {
+	Title           string   `json:"title" binding:"required,max=255" example:"Каркассон"`
+	Description     string   `json:"description" example:"Стратегия о строительстве средневековых городов"`
+	Genre           string   `json:"genre" binding:"max=100" example:"Стратегия"`
+	Age             string   `json:"age" binding:"max=100" example:"7+"`
+	Person          string   `json:"person" binding:"max=100" example:"2-5"`
+	AvgTime         string   `json:"avg_time" binding:"max=100" example:"30-45 мин"`
+	Difficulty      string   `json:"complexity" binding:"max=100" example:"Средняя"`
+	Image           string   `json:"image" binding:"max=2048"`
+	Rules           string   `json:"rules" binding:"max=2048"`
+	MinPlayers      *int     `json:"min_players" binding:"omitempty,min=1" example:"2"`
+	MaxPlayers      *int     `json:"max_players" binding:"omitempty,min=1" example:"5"`
+	MinPlayTime     *int     `json:"min_play_time" binding:"omitempty,min=1" example:"30"`
+	MaxPlayTime     *int     `json:"max_play_time" binding:"omitempty,min=1" example:"45"`
+	MinAge          *int     `json:"min_age" binding:"omitempty,min=0,max=99" example:"7"`
+	DifficultyLevel *float64 `json:"complexity_level" binding:"omitempty,min=1,max=5" example:"2.5"`
+}
+
+type PatchGameRequest struct {
+	Title           *string  `json:"title" binding:"omitempty,min=1,max=255"`
+	Description     *string  `json:"description"`
+	Genre           *string  `json:"genre" binding:"omitempty,max=100"`
+	Age             *string  `json:"age" binding:"omitempty,max=100"`
+	Person          *string  `json:"person" binding:"omitempty,max=100"`
+	AvgTime         *string  `json:"avg_time" binding:"omitempty,max=100"`
+	Difficulty      *string  `json:"complexity" binding:"omitempty,max=100"`
+	Image           *string  `json:"image" binding:"omitempty,max=2048"`
+	Rules           *string  `json:"rules" binding:"omitempty,max=2048"`
+	MinPlayers      *int     `json:"min_players" binding:"omitempty,min=1"`
+	MaxPlayers      *int     `json:"max_players" binding:"omitempty,min=1"`
+	MinPlayTime     *int     `json:"min_play_time" binding:"omitempty,min=1"`
+	MaxPlayTime     *int     `json:"max_play_time" binding:"omitempty,min=1"`
+	MinAge          *int     `json:"min_age" binding:"omitempty,min=0,max=99"`
+	DifficultyLevel *float64 `json:"complexity_level" binding:"omitempty,min=1,max=5"`
+}
+
+func convertGameReqToDTO(req GameRequest) gameSvc.Game {
+	return gameSvc.Game{
+		Title:           req.Title,
+		Description:     req.Description,
+		Genre:           req.Genre,
+		Age:             req.Age,
+		Person:          req.Person,
+		AvgTime:         req.AvgTime,
+		Difficulty:      req.Difficulty,
+		Image:           req.Image,
+		Rules:           req.Rules,
+		MinPlayers:      req.MinPlayers,
+		MaxPlayers:      req.MaxPlayers,
+		MinPlayTime:     req.MinPlayTime,
+		MaxPlayTime:     req.MaxPlayTime,
+		MinAge:          req.MinAge,
+		DifficultyLevel: req.DifficultyLevel,
+	}
+}
+
+func convertPatchReqToDTO(req PatchGameRequest) gameSvc.GamePatch {
+	return gameSvc.GamePatch{
+		Title:           req.Title,
+		Description:     req.Description,
+		Genre:           req.Genre,
+		Age:             req.Age,
+		Person:          req.Person,
+		AvgTime:         req.AvgTime,
+		Difficulty:      req.Difficulty,
+		Image:           req.Image,
+		Rules:           req.Rules,
+		MinPlayers:      req.MinPlayers,
+		MaxPlayers:      req.MaxPlayers,
+		MinPlayTime:     req.MinPlayTime,
+		MaxPlayTime:     req.MaxPlayTime,
+		MinAge:          req.MinAge,
+		DifficultyLevel: req.DifficultyLevel,
+	}
+}
+
+// exportColumns совпадают с JSON-полями gameSvc.Game, которые понимает импорт.
+var exportColumns = []string{
+	"id", "title", "description", "genre", "age", "person", "avg_time", "complexity", "image", "rules",
+	"min_players", "max_players", "min_play_time", "max_play_time", "min_age", "complexity_level", "external_id",
+}
+
+func exportValues(g gameSvc.Game) []any {
+	return []any{
+		g.ID, g.Title, g.Description, g.Genre, g.Age, g.Person, g.AvgTime, g.Difficulty, g.Image, g.Rules,
+		g.MinPlayers, g.MaxPlayers, g.MinPlayTime, g.MaxPlayTime, g.MinAge, g.DifficultyLevel, g.ExternalID,
+	}
+}
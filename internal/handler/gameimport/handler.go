@@ -0,0 +1,101 @@
+package gameimport
+
+import (
+	"errors"
+	"net/http"
+
+	importSvc "github.com/board-box/backend/internal/service/gameimport"
+	"github.com/gin-gonic/gin"
+)
+
+// maxUploadSize ограничивает размер загружаемого файла.
+const maxUploadSize = 32 << 20
+
+type Handler struct {
+	service *importSvc.Service
+	authMW  func(c *gin.Context)
+	adminMW func(c *gin.Context)
+}
+
+func New(service *importSvc.Service, authMW, adminMW func(c *gin.Context)) *Handler {
+	return &Handler{service: service, authMW: authMW, adminMW: adminMW}
+}
+
+func (h *Handler) RegisterRoutes(r *gin.RouterGroup) {
+	g := r.Group("/admin/games", h.authMW, h.adminMW)
+	g.POST("/import", h.Import)
+}
+
+// Import godoc
+// @Summary Импорт каталога игр из файла
+// @Tags Games
+// @Description Загружает игры из CSV, JSON Lines или выгрузки BoardGameGeek XML API2. Игры с внешним ID (колонка external_id или bgg_id) обновляются, если уже были импортированы. Строки с ошибками пропускаются и перечисляются в отчёте. С dry_run=true файл только проверяется. Только для администраторов.
+// @Accept multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param file formData file true "Файл с играми"
+// @Param format formData string false "Формат: csv, jsonl или bgg; по умолчанию по расширению файла"
+// @Param dry_run formData bool false "Только проверить файл"
+// @Param map formData string false "Сопоставление колонок с полями, например Name=title,Weight=complexity_level"
+// @Success 200 {object} importSvc.Report
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 413 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/games/import [post]
+func (h *Handler) Import(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadSize)
+
+	var req ImportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Файл слишком большой"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры импорта"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Файл обязателен"})
+		return
+	}
+
+	format, err := importSvc.ParseFormat(req.Format, fileHeader.Filename)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестный формат файла, укажите csv, jsonl или bgg"})
+		return
+	}
+
+	mapping, err := importSvc.ParseMapping(req.Map)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверное сопоставление колонок"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось прочитать файл"})
+		return
+	}
+	defer file.Close()
+
+	report, err := h.service.Import(c.Request.Context(), file, importSvc.Options{
+		Format:  format,
+		DryRun:  req.DryRun,
+		Mapping: mapping,
+	})
+	if err != nil {
+		if errors.Is(err, importSvc.ErrInvalidFile) || errors.Is(err, importSvc.ErrInvalidMapping) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось импортировать игры"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
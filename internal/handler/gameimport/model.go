@@ -0,0 +1,9 @@
+package gameimport
+
+type ImportRequest struct {
+	// Format — csv, jsonl или bgg; по умолчанию определяется по расширению файла
+	Format string `form:"format"`
+	DryRun bool   `form:"dry_run"`
+	// Map — сопоставление колонок с полями игры: "Name=title,Weight=complexity_level"
+	Map string `form:"map"`
+}
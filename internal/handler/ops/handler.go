@@ -0,0 +1,38 @@
+package ops
+
+import (
+	"net/http"
+
+	"github.com/board-box/backend/internal/db"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Handler struct {
+	pool    *pgxpool.Pool
+	authMW  func(c *gin.Context)
+	adminMW func(c *gin.Context)
+}
+
+func New(pool *pgxpool.Pool, authMW, adminMW func(c *gin.Context)) *Handler {
+	return &Handler{pool: pool, authMW: authMW, adminMW: adminMW}
+}
+
+func (h *Handler) RegisterRoutes(r *gin.RouterGroup) {
+	g := r.Group("/admin/ops", h.authMW, h.adminMW)
+	g.GET("/db", h.DBStats)
+}
+
+// DBStats godoc
+// @Summary Состояние пула соединений с базой
+// @Tags Ops
+// @Description Возвращает статистику пула соединений Postgres. Только для администраторов.
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} db.Stats
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Router /admin/ops/db [get]
+func (h *Handler) DBStats(c *gin.Context) {
+	c.JSON(http.StatusOK, db.PoolStats(h.pool))
+}
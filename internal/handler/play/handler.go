@@ -0,0 +1,435 @@
+package play
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/board-box/backend/internal/pagination"
+	gameSvc "github.com/board-box/backend/internal/service/game"
+	playSvc "github.com/board-box/backend/internal/service/play"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *playSvc.Service
+	authMW  func(c *gin.Context)
+}
+
+func New(service *playSvc.Service, authMW func(c *gin.Context)) *Handler {
+	return &Handler{service, authMW}
+}
+
+func (h *Handler) RegisterRoutes(r *gin.RouterGroup) {
+	g := r.Group("/plays")
+	g.Use(h.authMW)
+	g.GET("/", h.ListPlays)
+	g.POST("/", h.LogPlay)
+	g.GET("/:id", h.GetPlay)
+	g.DELETE("/:id", h.DeletePlay)
+
+	g.GET("/pending", h.ListPending)
+	g.POST("/pending/:id/confirm", h.ConfirmPlay)
+	g.DELETE("/pending/:id", h.DeclinePlay)
+
+	g.GET("/stats/most-played", h.MostPlayed)
+	g.GET("/stats/win-rate", h.WinRates)
+	g.GET("/stats/shelf-of-shame", h.ShelfOfShame)
+	g.GET("/stats/h-index", h.HIndex)
+}
+
+// LogPlay godoc
+// @Summary Записать партию
+// @Tags Plays
+// @Description Записывает партию в игру из каталога. Игроки — зарегистрированные пользователи (user_id) или гости (guest_name); победителей может быть несколько.
+// @Description Партия видна тому, кто её записал, и зарегистрированным игрокам. Другим пользователям она попадёт в список и статистику, только когда они подтвердят участие.
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param input body LogPlayRequest true "Партия"
+// @Security BearerAuth
+// @Success 201 {object} playSvc.Play
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /plays [post]
+func (h *Handler) LogPlay(c *gin.Context) {
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req LogPlayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	p, err := convertLogPlayReqToDTO(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Дата партии должна быть в формате YYYY-MM-DD"})
+		return
+	}
+
+	p, err = h.service.LogPlay(c.Request.Context(), userID, p)
+	if err != nil {
+		switch {
+		case errors.Is(err, playSvc.ErrInvalidPlay):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gameSvc.ErrGameNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Игра не найдена"})
+		case errors.Is(err, playSvc.ErrPlayerNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Игрок не найден"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось записать партию"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, p)
+}
+
+// ListPlays godoc
+// @Summary Партии пользователя
+// @Tags Plays
+// @Description Партии, которые пользователь записал или в которых подтвердил участие, новые первыми
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param game_id query int false "Только партии в эту игру"
+// @Param limit query int false "Размер страницы (1-100)"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Param offset query int false "Смещение (вместо курсора)"
+// @Security BearerAuth
+// @Success 200 {object} ListPlaysResponse
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /plays [get]
+func (h *Handler) ListPlays(c *gin.Context) {
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var query ListPlaysQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры запроса"})
+		return
+	}
+
+	filter := playSvc.ListFilter{GameID: query.GameID}
+	plays, meta, err := h.service.ListPlays(c.Request.Context(), userID, filter, query.Params)
+	if err != nil {
+		if pagination.IsInvalid(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры пагинации"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить партии"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListPlaysResponse{Plays: plays, Meta: meta})
+}
+
+// GetPlay godoc
+// @Summary Получить партию
+// @Tags Plays
+// @Description Партия с игроками; доступна тому, кто её записал, и зарегистрированным игрокам
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID партии"
+// @Security BearerAuth
+// @Success 200 {object} playSvc.Play
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /plays/{id} [get]
+func (h *Handler) GetPlay(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	p, err := h.service.GetPlay(c.Request.Context(), id, userID)
+	if err != nil {
+		if errors.Is(err, playSvc.ErrPlayNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Партия не найдена"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить партию"})
+		return
+	}
+
+	c.JSON(http.StatusOK, p)
+}
+
+// DeletePlay godoc
+// @Summary Удалить партию
+// @Tags Plays
+// @Description Удаляет партию; удалить её может только тот, кто записал
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID партии"
+// @Security BearerAuth
+// @Success 204
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /plays/{id} [delete]
+func (h *Handler) DeletePlay(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err = h.service.DeletePlay(c.Request.Context(), id, userID); err != nil {
+		if errors.Is(err, playSvc.ErrPlayNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Партия не найдена"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось удалить партию"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListPending godoc
+// @Summary Неподтверждённые партии
+// @Tags Plays
+// @Description Партии, где другие пользователи указали текущего игроком, а он ещё не подтвердил участие; новые первыми
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Security BearerAuth
+// @Success 200 {object} ListPendingResponse
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /plays/pending [get]
+func (h *Handler) ListPending(c *gin.Context) {
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	plays, err := h.service.ListPending(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить партии"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListPendingResponse{Plays: plays})
+}
+
+// ConfirmPlay godoc
+// @Summary Подтвердить участие в партии
+// @Tags Plays
+// @Description Подтверждает, что текущий пользователь играл в партии; после этого она учитывается в его списке и статистике
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID партии"
+// @Security BearerAuth
+// @Success 204
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /plays/pending/{id}/confirm [post]
+func (h *Handler) ConfirmPlay(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err = h.service.ConfirmPlay(c.Request.Context(), id, userID); err != nil {
+		if errors.Is(err, playSvc.ErrPlayNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Партия не найдена"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось подтвердить участие"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// DeclinePlay godoc
+// @Summary Отказаться от участия в партии
+// @Tags Plays
+// @Description Отвязывает текущего пользователя от партии: его место остаётся гостем с его именем, а партия перестаёт быть ему видна
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID партии"
+// @Security BearerAuth
+// @Success 204
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /plays/pending/{id} [delete]
+func (h *Handler) DeclinePlay(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err = h.service.DeclinePlay(c.Request.Context(), id, userID); err != nil {
+		if errors.Is(err, playSvc.ErrPlayNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Партия не найдена"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось отказаться от участия"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// MostPlayed godoc
+// @Summary Самые частые игры
+// @Tags Plays
+// @Description Игры, в которые пользователь играл чаще всего. Считаются партии, которые он записал или в которых подтвердил участие.
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param limit query int false "Сколько игр вернуть (1-100, по умолчанию 10)"
+// @Security BearerAuth
+// @Success 200 {object} MostPlayedResponse
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /plays/stats/most-played [get]
+func (h *Handler) MostPlayed(c *gin.Context) {
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var query MostPlayedQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit должен быть от 1 до 100"})
+		return
+	}
+
+	games, err := h.service.MostPlayed(c.Request.Context(), userID, query.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось посчитать статистику"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MostPlayedResponse{Games: games})
+}
+
+// WinRates godoc
+// @Summary Доля побед по играм
+// @Tags Plays
+// @Description Доля побед пользователя в каждой игре среди партий, где он был игроком; лучшие первыми
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Security BearerAuth
+// @Success 200 {object} WinRatesResponse
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /plays/stats/win-rate [get]
+func (h *Handler) WinRates(c *gin.Context) {
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	rates, err := h.service.WinRates(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось посчитать статистику"})
+		return
+	}
+
+	c.JSON(http.StatusOK, WinRatesResponse{Games: rates})
+}
+
+// ShelfOfShame godoc
+// @Summary Полка позора
+// @Tags Plays
+// @Description Игры из «Моей коллекции» и обычных коллекций пользователя, в которые он ещё ни разу не играл; дольше всех лежащие — первыми. Списки желаний и проданные игры не учитываются
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Security BearerAuth
+// @Success 200 {object} ShelfOfShameResponse
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /plays/stats/shelf-of-shame [get]
+func (h *Handler) ShelfOfShame(c *gin.Context) {
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	games, err := h.service.ShelfOfShame(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось посчитать статистику"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ShelfOfShameResponse{Games: games})
+}
+
+// HIndex godoc
+// @Summary h-индекс игрока
+// @Tags Plays
+// @Description Наибольшее h, при котором у пользователя есть h игр, сыгранных хотя бы h раз, и сами эти игры
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Security BearerAuth
+// @Success 200 {object} playSvc.HIndex
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /plays/stats/h-index [get]
+func (h *Handler) HIndex(c *gin.Context) {
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	index, err := h.service.HIndex(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось посчитать статистику"})
+		return
+	}
+
+	c.JSON(http.StatusOK, index)
+}
@@ -0,0 +1,83 @@
+package play
+
+import (
+	"time"
+
+	"github.com/board-box/backend/internal/pagination"
+	playSvc "github.com/board-box/backend/internal/service/play"
+)
+
+type LogPlayRequest struct {
+	GameID int64 `json:"game_id" binding:"required,min=1" example:"1"`
+	// PlayedOn — дата партии в формате YYYY-MM-DD, по умолчанию сегодня
+	PlayedOn        string          `json:"played_on" binding:"omitempty,datetime=2006-01-02" example:"2026-10-17"`
+	DurationMinutes *int            `json:"duration_minutes" binding:"omitempty,min=1" example:"45"`
+	Location        *string         `json:"location" binding:"omitempty,max=255" example:"Антикафе «Ход конём»"`
+	Note            *string         `json:"note" example:"Первая партия с дополнением"`
+	Players         []PlayerRequest `json:"players" binding:"required,min=1,max=50,dive"`
+}
+
+// PlayerRequest — игрок: зарегистрированный пользователь (user_id) или гость
+// (guest_name).
+type PlayerRequest struct {
+	UserID    *int64   `json:"user_id" binding:"omitempty,min=1" example:"2"`
+	GuestName *string  `json:"guest_name" binding:"omitempty,max=255" example:"Аня"`
+	Score     *float64 `json:"score" example:"42"`
+	Winner    bool     `json:"winner" example:"true"`
+}
+
+type ListPlaysQuery struct {
+	pagination.Params
+	GameID int64 `form:"game_id" binding:"omitempty,min=1"`
+}
+
+type ListPlaysResponse struct {
+	Plays []playSvc.Play `json:"plays"`
+	pagination.Meta
+}
+
+type MostPlayedQuery struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type ListPendingResponse struct {
+	Plays []playSvc.Play `json:"plays"`
+}
+
+type MostPlayedResponse struct {
+	Games []playSvc.GameStat `json:"games"`
+}
+
+type WinRatesResponse struct {
+	Games []playSvc.WinRate `json:"games"`
+}
+
+type ShelfOfShameResponse struct {
+	Games []playSvc.ShelfGame `json:"games"`
+}
+
+func convertLogPlayReqToDTO(req LogPlayRequest) (playSvc.Play, error) {
+	p := playSvc.Play{
+		GameID:          req.GameID,
+		DurationMinutes: req.DurationMinutes,
+		Location:        req.Location,
+		Note:            req.Note,
+		Players:         make([]playSvc.Player, 0, len(req.Players)),
+	}
+	if req.PlayedOn != "" {
+		date, err := time.Parse(time.DateOnly, req.PlayedOn)
+		if err != nil {
+			return playSvc.Play{}, err
+		}
+		p.PlayedOn = date
+	}
+	for _, player := range req.Players {
+		p.Players = append(p.Players, playSvc.Player{
+			UserID:    player.UserID,
+			GuestName: player.GuestName,
+			Score:     player.Score,
+			Winner:    player.Winner,
+		})
+	}
+	return p, nil
+}
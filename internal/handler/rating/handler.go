@@ -0,0 +1,237 @@
+package rating
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/board-box/backend/internal/pagination"
+	gameSvc "github.com/board-box/backend/internal/service/game"
+	ratingSvc "github.com/board-box/backend/internal/service/rating"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *ratingSvc.Service
+	authMW  func(c *gin.Context)
+}
+
+func New(service *ratingSvc.Service, authMW func(c *gin.Context)) *Handler {
+	return &Handler{service, authMW}
+}
+
+func (h *Handler) RegisterRoutes(r *gin.RouterGroup) {
+	g := r.Group("/games")
+	g.GET("/:id/ratings", h.ListRatings)
+
+	auth := g.Group("/:id/rating", h.authMW)
+	auth.GET("", h.GetRating)
+	auth.PUT("", h.Rate)
+	auth.DELETE("", h.DeleteRating)
+	auth.GET("/history", h.History)
+}
+
+// Rate godoc
+// @Summary Оценить игру
+// @Tags Ratings
+// @Description Ставит оценку от 1 до 10 с необязательным отзывом или заменяет свою прежнюю оценку. Каждая правка сохраняется в истории.
+// @Description Средняя, число оценок и байесовская оценка игры пересчитываются сразу.
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID игры"
+// @Param input body RateRequest true "Оценка"
+// @Security BearerAuth
+// @Success 200 {object} ratingSvc.Rating
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /games/{id}/rating [put]
+func (h *Handler) Rate(c *gin.Context) {
+	gameID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req RateRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Оценка должна быть от 1 до 10"})
+		return
+	}
+
+	rating, err := h.service.Rate(c.Request.Context(), userID, gameID, req.Rating, req.Review)
+	if err != nil {
+		ratingError(c, err, "Не удалось сохранить оценку")
+		return
+	}
+
+	c.JSON(http.StatusOK, rating)
+}
+
+// GetRating godoc
+// @Summary Своя оценка игры
+// @Tags Ratings
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID игры"
+// @Security BearerAuth
+// @Success 200 {object} ratingSvc.Rating
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /games/{id}/rating [get]
+func (h *Handler) GetRating(c *gin.Context) {
+	gameID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	rating, err := h.service.GetRating(c.Request.Context(), userID, gameID)
+	if err != nil {
+		ratingError(c, err, "Не удалось получить оценку")
+		return
+	}
+
+	c.JSON(http.StatusOK, rating)
+}
+
+// DeleteRating godoc
+// @Summary Удалить оценку
+// @Tags Ratings
+// @Description Удаляет свою оценку игры вместе с историей правок
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID игры"
+// @Security BearerAuth
+// @Success 204
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /games/{id}/rating [delete]
+func (h *Handler) DeleteRating(c *gin.Context) {
+	gameID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err = h.service.DeleteRating(c.Request.Context(), userID, gameID); err != nil {
+		ratingError(c, err, "Не удалось удалить оценку")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// History godoc
+// @Summary История своей оценки
+// @Tags Ratings
+// @Description Все сохранённые версии своей оценки и отзыва, новые первыми
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID игры"
+// @Security BearerAuth
+// @Success 200 {object} HistoryResponse
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /games/{id}/rating/history [get]
+func (h *Handler) History(c *gin.Context) {
+	gameID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	revisions, err := h.service.History(c.Request.Context(), userID, gameID)
+	if err != nil {
+		ratingError(c, err, "Не удалось получить историю оценки")
+		return
+	}
+
+	c.JSON(http.StatusOK, HistoryResponse{Revisions: revisions})
+}
+
+// ListRatings godoc
+// @Summary Оценки и отзывы игры
+// @Tags Ratings
+// @Description Оценки игры от всех пользователей, недавно изменённые первыми
+// @Produce json
+// @Param id path int true "ID игры"
+// @Param limit query int false "Размер страницы (1-100)"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Param offset query int false "Смещение (вместо курсора)"
+// @Success 200 {object} ListRatingsResponse
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /games/{id}/ratings [get]
+func (h *Handler) ListRatings(c *gin.Context) {
+	gameID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	var page pagination.Params
+	if err = c.ShouldBindQuery(&page); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры пагинации"})
+		return
+	}
+
+	ratings, meta, err := h.service.ListRatings(c.Request.Context(), gameID, page)
+	if err != nil {
+		if pagination.IsInvalid(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры пагинации"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить оценки"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListRatingsResponse{Ratings: ratings, Meta: meta})
+}
+
+// ratingError отвечает на ошибку сервиса оценок; неизвестные ошибки
+// становятся 500 с сообщением fallback.
+func ratingError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ratingSvc.ErrInvalidRating):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, gameSvc.ErrGameNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Игра не найдена"})
+	case errors.Is(err, ratingSvc.ErrRatingNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Оценка не найдена"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,20 @@
+package rating
+
+import (
+	"github.com/board-box/backend/internal/pagination"
+	ratingSvc "github.com/board-box/backend/internal/service/rating"
+)
+
+type RateRequest struct {
+	Rating int     `json:"rating" binding:"required,min=1,max=10" example:"8"`
+	Review *string `json:"review" example:"Быстрая и азартная, хорошо идёт вдвоём"`
+}
+
+type ListRatingsResponse struct {
+	Ratings []ratingSvc.Rating `json:"ratings"`
+	pagination.Meta
+}
+
+type HistoryResponse struct {
+	Revisions []ratingSvc.Revision `json:"revisions"`
+}
@@ -0,0 +1,90 @@
+package similar
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	gameSvc "github.com/board-box/backend/internal/service/game"
+	similarSvc "github.com/board-box/backend/internal/service/similar"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *similarSvc.Service
+	authMW  func(c *gin.Context)
+}
+
+func New(service *similarSvc.Service, authMW func(c *gin.Context)) *Handler {
+	return &Handler{service, authMW}
+}
+
+func (h *Handler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/games/:id/similar", h.ListSimilar)
+}
+
+// ListSimilar godoc
+// @Summary Похожие игры
+// @Tags Games
+// @Description Игры, похожие по жанрам, сложности, числу игроков, времени партии и описанию, самые похожие первыми.
+// @Description Похожесть пересчитывается фоновой задачей, поэтому новые игры появляются в списке после очередного пересчёта.
+// @Description С exclude_owned=true нужен токен: из списка убираются игры из коллекций пользователя.
+// @Produce json
+// @Param Authorization header string false "Bearer {token}, обязателен при exclude_owned=true"
+// @Param id path int true "ID игры"
+// @Param limit query int false "Сколько игр вернуть (1-50, по умолчанию 10)"
+// @Param exclude_owned query bool false "Убрать игры, которые уже есть в моих коллекциях"
+// @Param players query int false "Подходит для такого числа игроков"
+// @Param max_time query int false "Партия не дольше, минут"
+// @Success 200 {object} ListSimilarResponse
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /games/{id}/similar [get]
+func (h *Handler) ListSimilar(c *gin.Context) {
+	gameID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	var query SimilarQuery
+	if err = c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры запроса"})
+		return
+	}
+
+	filter := similarSvc.Filter{
+		Players: query.Players,
+		MaxTime: query.MaxTime,
+		Limit:   query.Limit,
+	}
+	if query.ExcludeOwned {
+		h.authMW(c)
+		if c.IsAborted() {
+			return
+		}
+		userID, ok := c.MustGet("userID").(int64)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		filter.ExcludeUserID = userID
+	}
+
+	games, err := h.service.Similar(c.Request.Context(), gameID, filter)
+	if err != nil {
+		if errors.Is(err, gameSvc.ErrGameNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Игра не найдена"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить похожие игры"})
+		return
+	}
+	if games == nil {
+		games = []similarSvc.Similar{}
+	}
+
+	c.JSON(http.StatusOK, ListSimilarResponse{Games: games})
+}
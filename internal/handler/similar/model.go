@@ -0,0 +1,14 @@
+package similar
+
+import similarSvc "github.com/board-box/backend/internal/service/similar"
+
+type SimilarQuery struct {
+	Limit        int  `form:"limit" binding:"omitempty,min=1,max=50"`
+	ExcludeOwned bool `form:"exclude_owned"`
+	Players      int  `form:"players" binding:"omitempty,min=1"`
+	MaxTime      int  `form:"max_time" binding:"omitempty,min=1"`
+}
+
+type ListSimilarResponse struct {
+	Games []similarSvc.Similar `json:"games"`
+}
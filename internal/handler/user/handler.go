@@ -3,7 +3,9 @@ package user
 import (
 	"errors"
 	"net/http"
+	"strconv"
 
+	"github.com/board-box/backend/internal/auth"
 	userSvc "github.com/board-box/backend/internal/service/user"
 	"github.com/gin-gonic/gin"
 )
@@ -11,19 +13,27 @@ import (
 type Handler struct {
 	service *userSvc.Service
 	authMW  func(c *gin.Context)
+	adminMW func(c *gin.Context)
 }
 
-func New(service *userSvc.Service, authMW func(c *gin.Context)) *Handler {
-	return &Handler{service: service, authMW: authMW}
+func New(service *userSvc.Service, authMW, adminMW func(c *gin.Context)) *Handler {
+	return &Handler{service: service, authMW: authMW, adminMW: adminMW}
 }
 
 func (h *Handler) RegisterRoutes(r *gin.RouterGroup) {
+	admin := r.Group("/admin/users", h.authMW, h.adminMW)
+	admin.PUT("/:id/roles/:role", h.AddRole)
+	admin.DELETE("/:id/roles/:role", h.RemoveRole)
+
 	g := r.Group("/user")
 	g.POST("/register", h.Register)
 	g.POST("/login", h.Login)
+	g.POST("/refresh", h.Refresh)
 
 	g.Use(h.authMW)
 	g.GET("/info", h.Info)
+	g.POST("/logout", h.Logout)
+	g.POST("/logout-all", h.LogoutAll)
 }
 
 // Register godoc
@@ -56,11 +66,11 @@ func (h *Handler) Register(c *gin.Context) {
 // Login godoc
 // @Summary Авторизация пользователя
 // @Tags Users
-// @Description Авторизует пользователя и возвращает JWT токен
+// @Description Авторизует пользователя, открывает сессию и возвращает access- и refresh-токены
 // @Accept json
 // @Produce json
 // @Param input body LoginRequest true "Данные для входа"
-// @Success 200 {object} map[string]string "token"
+// @Success 200 {object} TokenResponse
 // @Failure 400 {object} gin.H
 // @Failure 401 {object} gin.H
 // @Failure 500 {object} gin.H
@@ -72,15 +82,94 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := h.service.Login(c.Request.Context(), req.Email, req.Password)
+	tokens, err := h.service.Login(c.Request.Context(), req.Email, req.Password, c.Request.UserAgent())
 	if err != nil {
 		if errors.Is(err, userSvc.ErrUnauthorized) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid email or password"})
+			return
 		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to login"})
+		return
+	}
+
+	c.JSON(http.StatusOK, convertTokensToResponse(tokens))
+}
+
+// Refresh godoc
+// @Summary Обновить токены
+// @Tags Users
+// @Description Обменивает refresh-токен на новую пару токенов. Старый refresh-токен перестаёт действовать.
+// @Accept json
+// @Produce json
+// @Param input body RefreshRequest true "Refresh-токен"
+// @Success 200 {object} TokenResponse
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /user/refresh [post]
+func (h *Handler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	tokens, err := h.service.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, userSvc.ErrUnauthorized) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to refresh token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, convertTokensToResponse(tokens))
+}
+
+// Logout godoc
+// @Summary Выйти
+// @Tags Users
+// @Description Завершает текущую сессию и отзывает access-токен
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer {token}"
+// @Success 204
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /user/logout [post]
+func (h *Handler) Logout(c *gin.Context) {
+	err := h.service.Logout(c.Request.Context(), c.GetString("sessionID"), c.GetString("tokenID"), c.GetTime("tokenExpiresAt"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to logout"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// LogoutAll godoc
+// @Summary Выйти на всех устройствах
+// @Tags Users
+// @Description Завершает все сессии текущего пользователя
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer {token}"
+// @Success 204
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /user/logout-all [post]
+func (h *Handler) LogoutAll(c *gin.Context) {
+	userID, ok := c.MustGet("userID").(int64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверный идентификатор пользователя"})
+		return
+	}
+
+	if err := h.service.LogoutAll(c.Request.Context(), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to logout"})
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.Status(http.StatusNoContent)
 }
 
 // Info godoc
@@ -114,5 +203,91 @@ func (h *Handler) Info(c *gin.Context) {
 	c.JSON(http.StatusOK, InfoResponse{
 		Username: info.Username,
 		Email:    info.Email,
+		Roles:    info.Roles,
 	})
 }
+
+// AddRole godoc
+// @Summary Выдать роль пользователю
+// @Tags Users
+// @Description Выдаёт пользователю роль user, moderator или admin. Доступно только администраторам.
+// @Security BearerAuth
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID пользователя"
+// @Param role path string true "Роль" Enums(user, moderator, admin)
+// @Success 204
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/users/{id}/roles/{role} [put]
+func (h *Handler) AddRole(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	err = h.service.AddRole(c.Request.Context(), userID, auth.Role(c.Param("role")))
+	if err != nil {
+		if errors.Is(err, userSvc.ErrInvalidRole) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестная роль"})
+			return
+		}
+		if errors.Is(err, userSvc.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Пользователь не найден"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось выдать роль"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveRole godoc
+// @Summary Отозвать роль у пользователя
+// @Tags Users
+// @Description Отзывает роль у пользователя. Доступно только администраторам.
+// @Description Базовую роль user отозвать нельзя, как и роль admin у последнего администратора.
+// @Security BearerAuth
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param id path int true "ID пользователя"
+// @Param role path string true "Роль" Enums(user, moderator, admin)
+// @Success 204
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/users/{id}/roles/{role} [delete]
+func (h *Handler) RemoveRole(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	err = h.service.RemoveRole(c.Request.Context(), userID, auth.Role(c.Param("role")))
+	if err != nil {
+		if errors.Is(err, userSvc.ErrInvalidRole) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестная роль"})
+			return
+		}
+		if errors.Is(err, userSvc.ErrBaseRole) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Базовую роль нельзя отозвать"})
+			return
+		}
+		if errors.Is(err, userSvc.ErrLastAdmin) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Нельзя отозвать роль у последнего администратора"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось отозвать роль"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -1,5 +1,10 @@
 package user
 
+import (
+	"github.com/board-box/backend/internal/auth"
+	userSvc "github.com/board-box/backend/internal/service/user"
+)
+
 type RegisterRequest struct {
 	Username string `json:"username" example:"username"`
 	Email    string `json:"email" example:"user@example.com"`
@@ -11,7 +16,26 @@ type LoginRequest struct {
 	Password string `json:"password" example:"securepassword"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type TokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in" example:"900"`
+}
+
 type InfoResponse struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
+	Username string      `json:"username"`
+	Email    string      `json:"email"`
+	Roles    []auth.Role `json:"roles"`
+}
+
+func convertTokensToResponse(tokens userSvc.Tokens) TokenResponse {
+	return TokenResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    int64(tokens.ExpiresIn.Seconds()),
+	}
 }
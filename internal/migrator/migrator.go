@@ -0,0 +1,108 @@
+// Package migrator накатывает встроенные миграции из migrations/ через goose.
+package migrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/board-box/backend/migrations"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+const (
+	CommandUp     = "up"
+	CommandDown   = "down"
+	CommandStatus = "status"
+	CommandRedo   = "redo"
+)
+
+var ErrUnknownCommand = errors.New("unknown migrate command")
+
+// Commands — поддерживаемые команды в порядке вывода в справке.
+var Commands = []string{CommandUp, CommandDown, CommandStatus, CommandRedo}
+
+// Up накатывает все новые миграции.
+func Up(ctx context.Context, pool *pgxpool.Pool, out io.Writer) error {
+	return Run(ctx, pool, CommandUp, out)
+}
+
+// Run выполняет команду goose. Изменяющие команды берут advisory lock в
+// Postgres, поэтому несколько реплик, стартующих одновременно, накатывают
+// миграции по очереди, а не наперегонки.
+func Run(ctx context.Context, pool *pgxpool.Pool, command string, out io.Writer) error {
+	db := stdlib.OpenDBFromPool(pool)
+	defer db.Close()
+
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return err
+	}
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations.FS, goose.WithSessionLocker(locker))
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case CommandUp:
+		results, err := provider.Up(ctx)
+		printResults(out, results...)
+		return err
+	case CommandDown:
+		result, err := provider.Down(ctx)
+		printResults(out, result)
+		return err
+	case CommandRedo:
+		result, err := provider.Down(ctx)
+		printResults(out, result)
+		if err != nil {
+			return err
+		}
+		result, err = provider.UpByOne(ctx)
+		printResults(out, result)
+		return err
+	case CommandStatus:
+		statuses, err := provider.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printStatus(out, statuses)
+		return nil
+	}
+
+	return fmt.Errorf("%w %q", ErrUnknownCommand, command)
+}
+
+func printResults(out io.Writer, results ...*goose.MigrationResult) {
+	applied := 0
+	for _, r := range results {
+		if r == nil || r.Source == nil {
+			continue
+		}
+		applied++
+		fmt.Fprintf(out, "%-4s %s (%s)\n", r.Direction, r.Source.Path, r.Duration.Round(time.Millisecond))
+	}
+	if applied == 0 {
+		fmt.Fprintln(out, "no migrations to apply")
+	}
+}
+
+func printStatus(out io.Writer, statuses []*goose.MigrationStatus) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "APPLIED AT\tMIGRATION")
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.State == goose.StateApplied {
+			appliedAt = s.AppliedAt.Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", appliedAt, s.Source.Path)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,104 @@
+package migrator_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/board-box/backend/internal/migrator"
+	"github.com/board-box/backend/internal/testdb"
+	"github.com/board-box/backend/migrations"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(testdb.Main(m))
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	entries, err := migrations.FS.ReadDir(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+}
+
+// Все миграции должны откатываться и накатываться заново.
+func TestMigrationsRoundTrip(t *testing.T) {
+	pool := testdb.Pool(t)
+	ctx := context.Background()
+
+	db := stdlib.OpenDBFromPool(pool)
+	defer db.Close()
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = provider.DownTo(ctx, 0); err != nil {
+		t.Fatalf("down: %v", err)
+	}
+	if _, err = provider.Up(ctx); err != nil {
+		t.Fatalf("up again: %v", err)
+	}
+}
+
+func TestRunCommands(t *testing.T) {
+	pool := testdb.Pool(t)
+	ctx := context.Background()
+
+	var out bytes.Buffer
+	if err := migrator.Run(ctx, pool, migrator.CommandStatus, &out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "pending") {
+		t.Errorf("pending migrations after setup:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := migrator.Run(ctx, pool, migrator.CommandRedo, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "down") || !strings.Contains(out.String(), "up") {
+		t.Errorf("redo output:\n%s", out.String())
+	}
+
+	if err := migrator.Run(ctx, pool, "sideways", &out); !errors.Is(err, migrator.ErrUnknownCommand) {
+		t.Errorf("err = %v, want migrator.ErrUnknownCommand", err)
+	}
+}
+
+// Реплики, стартующие одновременно, не должны мешать друг другу.
+func TestConcurrentUp(t *testing.T) {
+	pool := testdb.Pool(t)
+
+	if err := migrator.Run(context.Background(), pool, migrator.CommandDown, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- migrator.Up(context.Background(), pool, &bytes.Buffer{})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("concurrent up: %v", err)
+		}
+	}
+}
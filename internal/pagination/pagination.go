@@ -0,0 +1,84 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var (
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrCursorWithOffset = errors.New("cursor and offset are mutually exclusive")
+)
+
+// Params — общий контракт пагинации для всех списков.
+// По умолчанию используется курсор; offset включает постраничный режим
+// и не может передаваться вместе с курсором.
+type Params struct {
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// Meta возвращается вместе со страницей. NextCursor пуст на последней странице.
+type Meta struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      int64  `json:"total"`
+}
+
+func (p Params) Validate() error {
+	if p.Cursor != "" && p.Offset > 0 {
+		return ErrCursorWithOffset
+	}
+	return nil
+}
+
+// IsInvalid сообщает, что ошибка вызвана некорректными параметрами пагинации.
+func IsInvalid(err error) bool {
+	return errors.Is(err, ErrInvalidCursor) || errors.Is(err, ErrCursorWithOffset)
+}
+
+func (p Params) PageLimit() int {
+	if p.Limit <= 0 {
+		return DefaultLimit
+	}
+	if p.Limit > MaxLimit {
+		return MaxLimit
+	}
+	return p.Limit
+}
+
+// Encode упаковывает ключ сортировки последнего элемента в непрозрачную строку.
+func Encode(key any) string {
+	data, err := json.Marshal(key)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode распаковывает курсор, полученный от Encode.
+func Decode(cursor string, key any) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if err = json.Unmarshal(data, key); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+// Trim отрезает лишний элемент, запрошенный для проверки наличия следующей
+// страницы, и сообщает, есть ли она.
+func Trim[T any](items []T, limit int) ([]T, bool) {
+	if len(items) > limit {
+		return items[:limit], true
+	}
+	return items, false
+}
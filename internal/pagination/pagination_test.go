@@ -0,0 +1,66 @@
+package pagination
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	type key struct {
+		Title string `json:"t"`
+		ID    int64  `json:"id"`
+	}
+
+	in := key{Title: "Каркассон", ID: 42}
+	var out key
+	if err := Decode(Encode(in), &out); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if out != in {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+
+	for _, bad := range []string{"not base64!", Encode("a string, not an object")} {
+		if err := Decode(bad, &out); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("Decode(%q) = %v, want ErrInvalidCursor", bad, err)
+		}
+	}
+}
+
+func TestPageLimit(t *testing.T) {
+	tests := []struct {
+		limit, want int
+	}{
+		{0, DefaultLimit},
+		{-1, DefaultLimit},
+		{5, 5},
+		{MaxLimit + 1, MaxLimit},
+	}
+	for _, tt := range tests {
+		if got := (Params{Limit: tt.limit}).PageLimit(); got != tt.want {
+			t.Errorf("PageLimit(%d) = %d, want %d", tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	err := Params{Cursor: "abc", Offset: 10}.Validate()
+	if !errors.Is(err, ErrCursorWithOffset) || !IsInvalid(err) {
+		t.Errorf("Validate = %v, want ErrCursorWithOffset", err)
+	}
+	if err := (Params{Offset: 10}).Validate(); err != nil {
+		t.Errorf("offset only: %v", err)
+	}
+}
+
+func TestTrim(t *testing.T) {
+	items, more := Trim([]int{1, 2, 3}, 2)
+	if len(items) != 2 || !more {
+		t.Errorf("Trim(3 items, 2) = %v, %v", items, more)
+	}
+
+	items, more = Trim([]int{1, 2}, 2)
+	if len(items) != 2 || more {
+		t.Errorf("Trim(2 items, 2) = %v, %v", items, more)
+	}
+}
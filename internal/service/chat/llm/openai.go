@@ -0,0 +1,252 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Верхняя граница числа вызовов инструментов в одном ответе
+const maxToolCalls = 16
+
+// ErrTimeout — модель не ответила за Options.Timeout.
+var ErrTimeout = errors.New("llm: response timed out")
+
+// OpenAI работает с любым OpenAI-совместимым API chat/completions:
+// OpenRouter, OpenAI, Ollama, llama.cpp server, vLLM.
+type OpenAI struct {
+	opts       Options
+	httpClient *http.Client
+}
+
+type request struct {
+	Model       string     `json:"model"`
+	Messages    []Message  `json:"messages"`
+	Tools       []toolSpec `json:"tools,omitempty"`
+	Temperature float64    `json:"temperature"`
+	Stream      bool       `json:"stream,omitempty"`
+}
+
+type toolSpec struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string          `json:"name"`
+		Description string          `json:"description"`
+		Parameters  json.RawMessage `json:"parameters"`
+	} `json:"function"`
+}
+
+type response struct {
+	Choices []struct {
+		Message Message `json:"message"`
+	} `json:"choices"`
+}
+
+type streamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content   string `json:"content"`
+			ToolCalls []struct {
+				Index    int          `json:"index"`
+				ID       string       `json:"id"`
+				Type     string       `json:"type"`
+				Function FunctionCall `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"delta"`
+	} `json:"choices"`
+}
+
+func NewOpenAI(opts Options) *OpenAI {
+	opts.BaseURL = strings.TrimRight(opts.BaseURL, "/")
+	return &OpenAI{
+		opts: opts,
+		// Без Timeout у клиента: он оборвал бы и долгий поток. Сроки задаются
+		// через ctx в Complete и Stream.
+		httpClient: &http.Client{},
+	}
+}
+
+func (p *OpenAI) Complete(ctx context.Context, messages []Message, tools []Tool) (Message, error) {
+	if p.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, p.opts.Timeout, ErrTimeout)
+		defer cancel()
+	}
+
+	resp, err := p.send(ctx, p.newRequest(messages, tools, false))
+	if err != nil {
+		return Message{}, timeoutCause(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Message{}, timeoutCause(ctx, err)
+	}
+
+	var result response
+	if err := json.Unmarshal(respBytes, &result); err != nil {
+		return Message{}, err
+	}
+
+	if len(result.Choices) == 0 {
+		return Message{}, fmt.Errorf("API error: empty response")
+	}
+
+	return result.Choices[0].Message, nil
+}
+
+// Stream ждёт каждую часть ответа не дольше Options.Timeout: поток, который
+// идёт долго, но без пауз, не обрывается.
+func (p *OpenAI) Stream(ctx context.Context, messages []Message, tools []Tool, onDelta func(string) error) (Message, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	// idle обрывает запрос, если модель молчит дольше Timeout
+	idle := func() {}
+	if p.opts.Timeout > 0 {
+		timer := time.AfterFunc(p.opts.Timeout, func() { cancel(ErrTimeout) })
+		defer timer.Stop()
+		idle = func() { timer.Reset(p.opts.Timeout) }
+	}
+
+	resp, err := p.send(ctx, p.newRequest(messages, tools, true))
+	if err != nil {
+		return Message{}, timeoutCause(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	var content strings.Builder
+	// Вызовы инструментов приходят кусками: имя и аргументы дописываются по index
+	var toolCalls []ToolCall
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		idle()
+		line := scanner.Text()
+		// Пустые строки разделяют события, строки с ":" — комментарии (keep-alive)
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk streamChunk
+		if err = json.Unmarshal([]byte(data), &chunk); err != nil {
+			return Message{}, err
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+
+		for _, tc := range chunk.Choices[0].Delta.ToolCalls {
+			if tc.Index < 0 || tc.Index >= maxToolCalls {
+				return Message{}, fmt.Errorf("llm: tool call index %d out of range", tc.Index)
+			}
+			for len(toolCalls) <= tc.Index {
+				toolCalls = append(toolCalls, ToolCall{Type: "function"})
+			}
+			call := &toolCalls[tc.Index]
+			if tc.ID != "" {
+				call.ID = tc.ID
+			}
+			call.Function.Name += tc.Function.Name
+			call.Function.Arguments += tc.Function.Arguments
+		}
+
+		delta := chunk.Choices[0].Delta.Content
+		if delta == "" {
+			continue
+		}
+		content.WriteString(delta)
+		if err = onDelta(delta); err != nil {
+			return Message{}, err
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return Message{}, timeoutCause(ctx, err)
+	}
+	if err = ctx.Err(); err != nil {
+		return Message{}, timeoutCause(ctx, err)
+	}
+
+	return Message{Role: "assistant", Content: content.String(), ToolCalls: toolCalls}, nil
+}
+
+// timeoutCause заменяет ошибку на ErrTimeout, если запрос оборвал таймаут.
+func timeoutCause(ctx context.Context, err error) error {
+	if errors.Is(context.Cause(ctx), ErrTimeout) {
+		return ErrTimeout
+	}
+	return err
+}
+
+func (p *OpenAI) newRequest(messages []Message, tools []Tool, stream bool) request {
+	req := request{
+		Model:       p.opts.Model,
+		Messages:    messages,
+		Temperature: p.opts.Temperature,
+		Stream:      stream,
+	}
+
+	for _, t := range tools {
+		spec := toolSpec{Type: "function"}
+		spec.Function.Name = t.Name
+		spec.Function.Description = t.Description
+		spec.Function.Parameters = t.Parameters
+		req.Tools = append(req.Tools, spec)
+	}
+
+	return req
+}
+
+func (p *OpenAI) send(ctx context.Context, reqBody request) (*http.Response, error) {
+	bodyBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.opts.BaseURL+"/chat/completions", bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if p.opts.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.opts.APIKey)
+	}
+	if reqBody.Stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+	if p.opts.SiteURL != "" {
+		req.Header.Set("HTTP-Referer", p.opts.SiteURL)
+	}
+	if p.opts.SiteTitle != "" {
+		req.Header.Set("X-Title", p.opts.SiteTitle)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		respBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error: %s", string(respBytes))
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,138 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T, handler func(w http.ResponseWriter, req request)) *OpenAI {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer key" {
+			t.Errorf("Authorization = %q", got)
+		}
+
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		handler(w, req)
+	}))
+	t.Cleanup(srv.Close)
+
+	return NewOpenAI(Options{BaseURL: srv.URL + "/", Model: "test-model", APIKey: "key"})
+}
+
+func TestOpenAIComplete(t *testing.T) {
+	p := newTestServer(t, func(w http.ResponseWriter, req request) {
+		if req.Model != "test-model" || req.Stream {
+			t.Errorf("unexpected request: %+v", req)
+		}
+		if len(req.Tools) != 1 || req.Tools[0].Type != "function" || req.Tools[0].Function.Name != "get_game" {
+			t.Errorf("tools = %+v", req.Tools)
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":null,
+			"tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_game","arguments":"{\"id\":7}"}}]}}]}`)
+	})
+
+	tools := []Tool{{Name: "get_game", Parameters: json.RawMessage(`{"type":"object"}`)}}
+	msg, err := p.Complete(context.Background(), []Message{{Role: "user", Content: "?"}}, tools)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msg.ToolCalls) != 1 || msg.ToolCalls[0].Function.Arguments != `{"id":7}` {
+		t.Errorf("tool calls = %+v", msg.ToolCalls)
+	}
+}
+
+func TestOpenAIStream(t *testing.T) {
+	chunks := []string{
+		`{"choices":[{"delta":{"role":"assistant","content":"При"}}]}`,
+		`{"choices":[{"delta":{"content":"вет"}}]}`,
+		`{"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"search_","arguments":"{\"qu"}}]}}]}`,
+		`{"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"name":"games","arguments":"ery\":\"кот\"}"}}]}}]}`,
+	}
+
+	p := newTestServer(t, func(w http.ResponseWriter, req request) {
+		if !req.Stream {
+			t.Error("stream flag is not set")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, c := range chunks {
+			fmt.Fprintf(w, "data: %s\n\n", c)
+		}
+		fmt.Fprint(w, ": keep-alive\n\ndata: [DONE]\n\n")
+	})
+
+	var deltas []string
+	msg, err := p.Stream(context.Background(), []Message{{Role: "user", Content: "?"}}, nil, func(d string) error {
+		deltas = append(deltas, d)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.Content != "Привет" || strings.Join(deltas, "|") != "При|вет" {
+		t.Errorf("content = %q, deltas = %v", msg.Content, deltas)
+	}
+	if len(msg.ToolCalls) != 1 {
+		t.Fatalf("tool calls = %+v", msg.ToolCalls)
+	}
+	call := msg.ToolCalls[0]
+	if call.ID != "call_1" || call.Function.Name != "search_games" || call.Function.Arguments != `{"query":"кот"}` {
+		t.Errorf("tool call = %+v", call)
+	}
+}
+
+func TestOpenAIError(t *testing.T) {
+	p := newTestServer(t, func(w http.ResponseWriter, _ request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"error":"rate limited"}`)
+	})
+
+	if _, err := p.Complete(context.Background(), nil, nil); err == nil || !strings.Contains(err.Error(), "rate limited") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestOpenAIStreamTimeout(t *testing.T) {
+	const timeout = 100 * time.Millisecond
+
+	stream := func(t *testing.T, pause time.Duration, parts int) error {
+		t.Helper()
+
+		p := newTestServer(t, func(w http.ResponseWriter, _ request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			for range parts {
+				fmt.Fprint(w, `data: {"choices":[{"delta":{"content":"."}}]}`+"\n\n")
+				w.(http.Flusher).Flush()
+				time.Sleep(pause)
+			}
+			fmt.Fprint(w, "data: [DONE]\n\n")
+		})
+		p.opts.Timeout = timeout
+
+		_, err := p.Stream(context.Background(), nil, nil, func(string) error { return nil })
+		return err
+	}
+
+	// Поток дольше таймаута, но без долгих пауз, не обрывается
+	if err := stream(t, timeout/4, 8); err != nil {
+		t.Errorf("steady stream error = %v", err)
+	}
+	if err := stream(t, 3*timeout, 1); !errors.Is(err, ErrTimeout) {
+		t.Errorf("stalled stream error = %v, want ErrTimeout", err)
+	}
+}
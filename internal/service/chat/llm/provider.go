@@ -0,0 +1,72 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	ProviderOpenAI = "openai"
+	ProviderStub   = "stub"
+)
+
+type Message struct {
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+}
+
+// Tool описывает функцию, которую модель может вызвать.
+// Parameters — JSON Schema аргументов.
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  json.RawMessage
+}
+
+type ToolCall struct {
+	ID       string       `json:"id"`
+	Type     string       `json:"type"`
+	Function FunctionCall `json:"function"`
+}
+
+type FunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// Provider — бэкенд языковой модели.
+type Provider interface {
+	// Complete возвращает ответ модели целиком. Если переданы tools, вместо
+	// текста модель может вернуть вызовы инструментов в Message.ToolCalls.
+	Complete(ctx context.Context, messages []Message, tools []Tool) (Message, error)
+	// Stream передаёт текст ответа по частям в onDelta и возвращает сообщение
+	// целиком. Ошибка из onDelta прерывает генерацию.
+	Stream(ctx context.Context, messages []Message, tools []Tool, onDelta func(string) error) (Message, error)
+}
+
+type Options struct {
+	BaseURL     string
+	Model       string
+	APIKey      string
+	Temperature float64
+	// Timeout ограничивает ответ модели: у Complete — весь запрос, у Stream —
+	// ожидание заголовков и каждой следующей части, а не всю генерацию.
+	Timeout   time.Duration
+	SiteURL   string
+	SiteTitle string
+}
+
+// New создаёт провайдера по имени из конфигурации.
+func New(name string, opts Options) (Provider, error) {
+	switch name {
+	case ProviderOpenAI:
+		return NewOpenAI(opts), nil
+	case ProviderStub:
+		return NewStub(), nil
+	}
+	return nil, fmt.Errorf("unknown llm provider %q", name)
+}
@@ -0,0 +1,89 @@
+package llm
+
+import (
+	"context"
+	"strings"
+)
+
+// Stub — детерминированный провайдер без сети для тестов и локальной
+// разработки. Отвечает эхом последнего сообщения пользователя.
+//
+// Инструменты вызываются командой в сообщении: "/search_games {"query":"кот"}"
+// вызывает search_games с указанными аргументами, а после ответа инструмента
+// Stub возвращает его результат как текст.
+type Stub struct{}
+
+func NewStub() *Stub {
+	return &Stub{}
+}
+
+func (s *Stub) Complete(ctx context.Context, messages []Message, tools []Tool) (Message, error) {
+	if err := ctx.Err(); err != nil {
+		return Message{}, err
+	}
+	return stubAnswer(messages, tools), nil
+}
+
+// Stream отдаёт текст ответа по словам.
+func (s *Stub) Stream(ctx context.Context, messages []Message, tools []Tool, onDelta func(string) error) (Message, error) {
+	answer := stubAnswer(messages, tools)
+	if answer.Content == "" {
+		return answer, ctx.Err()
+	}
+
+	for _, word := range strings.SplitAfter(answer.Content, " ") {
+		if err := ctx.Err(); err != nil {
+			return Message{}, err
+		}
+		if err := onDelta(word); err != nil {
+			return Message{}, err
+		}
+	}
+	return answer, nil
+}
+
+func stubAnswer(messages []Message, tools []Tool) Message {
+	if len(messages) == 0 {
+		return Message{Role: "assistant", Content: "Чем могу помочь?"}
+	}
+
+	last := messages[len(messages)-1]
+	switch last.Role {
+	case "tool":
+		var results []string
+		for i := len(messages) - 1; i >= 0 && messages[i].Role == "tool"; i-- {
+			results = append([]string{messages[i].Content}, results...)
+		}
+		return Message{Role: "assistant", Content: "Нашёл в каталоге: " + strings.Join(results, " ")}
+	case "user":
+		if call, ok := stubToolCall(last.Content, tools); ok {
+			return Message{Role: "assistant", ToolCalls: []ToolCall{call}}
+		}
+		return Message{Role: "assistant", Content: "Вы спросили: " + last.Content}
+	}
+
+	return Message{Role: "assistant", Content: "Чем могу помочь?"}
+}
+
+func stubToolCall(content string, tools []Tool) (ToolCall, bool) {
+	if !strings.HasPrefix(content, "/") {
+		return ToolCall{}, false
+	}
+
+	name, args, _ := strings.Cut(strings.TrimPrefix(content, "/"), " ")
+	for _, t := range tools {
+		if t.Name != name {
+			continue
+		}
+		if strings.TrimSpace(args) == "" {
+			args = "{}"
+		}
+		return ToolCall{
+			ID:       "call_" + name,
+			Type:     "function",
+			Function: FunctionCall{Name: name, Arguments: args},
+		}, true
+	}
+
+	return ToolCall{}, false
+}
@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestStubEcho(t *testing.T) {
+	var deltas []string
+	msg, err := NewStub().Stream(context.Background(), []Message{{Role: "user", Content: "Во что поиграть?"}}, nil, func(d string) error {
+		deltas = append(deltas, d)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.Content != "Вы спросили: Во что поиграть?" {
+		t.Errorf("content = %q", msg.Content)
+	}
+	if strings.Join(deltas, "") != msg.Content || len(deltas) < 2 {
+		t.Errorf("deltas = %q", deltas)
+	}
+}
+
+func TestStubToolCall(t *testing.T) {
+	tools := []Tool{{Name: "search_games", Parameters: json.RawMessage(`{}`)}}
+	history := []Message{{Role: "user", Content: `/search_games {"query":"кот"}`}}
+
+	msg, err := NewStub().Complete(context.Background(), history, tools)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msg.ToolCalls) != 1 || msg.ToolCalls[0].Function.Arguments != `{"query":"кот"}` {
+		t.Fatalf("tool calls = %+v", msg.ToolCalls)
+	}
+
+	// Без предложенного инструмента команда считается обычным текстом
+	msg, _ = NewStub().Complete(context.Background(), history, nil)
+	if len(msg.ToolCalls) != 0 {
+		t.Errorf("unexpected tool call without tools: %+v", msg.ToolCalls)
+	}
+
+	history = append(history,
+		Message{Role: "assistant", ToolCalls: msg.ToolCalls},
+		Message{Role: "tool", Content: `[{"title":"Котики"}]`, ToolCallID: "call_search_games"},
+	)
+	msg, _ = NewStub().Complete(context.Background(), history, tools)
+	if !strings.Contains(msg.Content, "Котики") {
+		t.Errorf("answer after tool = %q", msg.Content)
+	}
+}
+
+func TestStubCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := NewStub().Complete(ctx, []Message{{Role: "user", Content: "?"}}, nil); err == nil {
+		t.Error("expected context error")
+	}
+}
@@ -0,0 +1,28 @@
+package chat
+
+import "time"
+
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+type Conversation struct {
+	ID        int64     `json:"id" db:"id"`
+	UserID    int64     `json:"user_id" db:"user_id"`
+	Title     string    `json:"title" db:"title"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	Messages  []Message `json:"messages,omitempty"`
+}
+
+type Message struct {
+	ID      int64  `json:"id" db:"id"`
+	Role    string `json:"role" db:"role"`
+	Content string `json:"content" db:"content"`
+	// GameIDs — игры каталога, на которые ссылается ответ ассистента
+	GameIDs   []int64   `json:"game_ids,omitempty" db:"game_ids"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
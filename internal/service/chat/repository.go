@@ -0,0 +1,319 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/board-box/backend/internal/db"
+	"github.com/board-box/backend/internal/pagination"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	pgx "github.com/jackc/pgx/v5"
+)
+
+const (
+	conversationTableName = "chat_conversation"
+	messageTableName      = "chat_message"
+)
+
+var (
+	psql = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+
+	ErrConversationNotFound = errors.New("conversation not found")
+)
+
+// Repository — хранилище диалогов с ассистентом.
+type Repository interface {
+	ListConversations(ctx context.Context, userID int64, page pagination.Params) ([]Conversation, pagination.Meta, error)
+	GetConversation(ctx context.Context, conversationID, userID int64) (Conversation, error)
+	CreateConversation(ctx context.Context, userID int64, title string) (Conversation, error)
+	CreateConversationWithMessages(ctx context.Context, userID int64, title string, messages ...Message) (Conversation, []Message, error)
+	RenameConversation(ctx context.Context, conversationID, userID int64, title string) (Conversation, error)
+	DeleteConversation(ctx context.Context, conversationID, userID int64) error
+
+	ListMessages(ctx context.Context, conversationID int64) ([]Message, error)
+	LastMessages(ctx context.Context, conversationID int64, limit int) ([]Message, error)
+	SaveMessages(ctx context.Context, conversationID int64, messages ...Message) ([]Message, error)
+}
+
+type repository struct {
+	db db.DB
+}
+
+func NewRepository(db db.DB) Repository {
+	return &repository{db: db}
+}
+
+type conversationCursor struct {
+	UpdatedAt time.Time `json:"u"`
+	ID        int64     `json:"id"`
+}
+
+func (r *repository) ListConversations(ctx context.Context, userID int64, page pagination.Params) ([]Conversation, pagination.Meta, error) {
+	limit := page.PageLimit()
+
+	builder := psql.
+		Select("id", "user_id", "title", "created_at", "updated_at").
+		From(conversationTableName).
+		Where(squirrel.Eq{"user_id": userID}).
+		OrderBy("updated_at DESC", "id DESC").
+		Limit(uint64(limit + 1))
+
+	if page.Cursor != "" {
+		var cursor conversationCursor
+		if err := pagination.Decode(page.Cursor, &cursor); err != nil {
+			return nil, pagination.Meta{}, err
+		}
+		builder = builder.Where("(updated_at, id) < (?, ?)", cursor.UpdatedAt, cursor.ID)
+	}
+	if page.Offset > 0 {
+		builder = builder.Offset(uint64(page.Offset))
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	var conversations []Conversation
+	if err = pgxscan.Select(ctx, r.db, &conversations, query, args...); err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	meta := pagination.Meta{}
+	conversations, hasMore := pagination.Trim(conversations, limit)
+	if hasMore {
+		last := conversations[len(conversations)-1]
+		meta.NextCursor = pagination.Encode(conversationCursor{UpdatedAt: last.UpdatedAt, ID: last.ID})
+	}
+
+	query, args, err = psql.
+		Select("COUNT(*)").
+		From(conversationTableName).
+		Where(squirrel.Eq{"user_id": userID}).
+		ToSql()
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	if err = r.db.QueryRow(ctx, query, args...).Scan(&meta.Total); err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	return conversations, meta, nil
+}
+
+func (r *repository) GetConversation(ctx context.Context, conversationID, userID int64) (Conversation, error) {
+	query, args, err := psql.
+		Select("id", "user_id", "title", "created_at", "updated_at").
+		From(conversationTableName).
+		Where(squirrel.Eq{"id": conversationID, "user_id": userID}).
+		ToSql()
+	if err != nil {
+		return Conversation{}, err
+	}
+
+	var c Conversation
+	err = pgxscan.Get(ctx, r.db, &c, query, args...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Conversation{}, ErrConversationNotFound
+		}
+		return Conversation{}, err
+	}
+
+	return c, nil
+}
+
+func (r *repository) CreateConversation(ctx context.Context, userID int64, title string) (Conversation, error) {
+	query, args, err := psql.
+		Insert(conversationTableName).
+		Columns("user_id", "title").
+		Values(userID, title).
+		Suffix("RETURNING id, user_id, title, created_at, updated_at").
+		ToSql()
+	if err != nil {
+		return Conversation{}, err
+	}
+
+	var c Conversation
+	if err = pgxscan.Get(ctx, r.db, &c, query, args...); err != nil {
+		return Conversation{}, err
+	}
+
+	return c, nil
+}
+
+func (r *repository) RenameConversation(ctx context.Context, conversationID, userID int64, title string) (Conversation, error) {
+	query, args, err := psql.
+		Update(conversationTableName).
+		Set("title", title).
+		Set("updated_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{"id": conversationID, "user_id": userID}).
+		Suffix("RETURNING id, user_id, title, created_at, updated_at").
+		ToSql()
+	if err != nil {
+		return Conversation{}, err
+	}
+
+	var c Conversation
+	err = pgxscan.Get(ctx, r.db, &c, query, args...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Conversation{}, ErrConversationNotFound
+		}
+		return Conversation{}, err
+	}
+
+	return c, nil
+}
+
+func (r *repository) DeleteConversation(ctx context.Context, conversationID, userID int64) error {
+	query, args, err := psql.
+		Delete(conversationTableName).
+		Where(squirrel.Eq{"id": conversationID, "user_id": userID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrConversationNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) ListMessages(ctx context.Context, conversationID int64) ([]Message, error) {
+	query, args, err := psql.
+		Select("id", "role", "content", "game_ids", "created_at").
+		From(messageTableName).
+		Where(squirrel.Eq{"conversation_id": conversationID}).
+		OrderBy("id ASC").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []Message
+	if err = pgxscan.Select(ctx, r.db, &messages, query, args...); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// LastMessages возвращает не больше limit последних сообщений в хронологическом порядке.
+func (r *repository) LastMessages(ctx context.Context, conversationID int64, limit int) ([]Message, error) {
+	query, args, err := psql.
+		Select("id", "role", "content", "game_ids", "created_at").
+		From(messageTableName).
+		Where(squirrel.Eq{"conversation_id": conversationID}).
+		OrderBy("id DESC").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []Message
+	if err = pgxscan.Select(ctx, r.db, &messages, query, args...); err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+
+	return messages, nil
+}
+
+// SaveMessages сохраняет сообщения и поднимает диалог наверх списка.
+// CreateConversationWithMessages создаёт диалог сразу с первыми сообщениями
+// в одной транзакции, чтобы не оставалось пустых диалогов.
+func (r *repository) CreateConversationWithMessages(ctx context.Context, userID int64, title string, messages ...Message) (Conversation, []Message, error) {
+	query, args, err := psql.
+		Insert(conversationTableName).
+		Columns("user_id", "title").
+		Values(userID, title).
+		Suffix("RETURNING id, user_id, title, created_at, updated_at").
+		ToSql()
+	if err != nil {
+		return Conversation{}, nil, err
+	}
+
+	var (
+		c     Conversation
+		saved []Message
+	)
+	err = pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := pgxscan.Get(ctx, tx, &c, query, args...); err != nil {
+			return err
+		}
+
+		var err error
+		saved, err = insertMessages(ctx, tx, c.ID, messages)
+		return err
+	})
+	if err != nil {
+		return Conversation{}, nil, err
+	}
+
+	return c, saved, nil
+}
+
+func (r *repository) SaveMessages(ctx context.Context, conversationID int64, messages ...Message) ([]Message, error) {
+	var saved []Message
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var err error
+		if saved, err = insertMessages(ctx, tx, conversationID, messages); err != nil {
+			return err
+		}
+
+		query, args, err := psql.
+			Update(conversationTableName).
+			Set("updated_at", squirrel.Expr("NOW()")).
+			Where(squirrel.Eq{"id": conversationID}).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, query, args...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+func insertMessages(ctx context.Context, tx pgx.Tx, conversationID int64, messages []Message) ([]Message, error) {
+	saved := make([]Message, 0, len(messages))
+	for _, m := range messages {
+		query, args, err := psql.
+			Insert(messageTableName).
+			Columns("conversation_id", "role", "content", "game_ids").
+			Values(conversationID, m.Role, m.Content, m.GameIDs).
+			Suffix("RETURNING id, role, content, game_ids, created_at").
+			ToSql()
+		if err != nil {
+			return nil, err
+		}
+
+		var msg Message
+		if err = pgxscan.Get(ctx, tx, &msg, query, args...); err != nil {
+			return nil, err
+		}
+		saved = append(saved, msg)
+	}
+
+	return saved, nil
+}
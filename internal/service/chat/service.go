@@ -2,37 +2,292 @@ package chat
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
 
-	"github.com/samber/lo"
+	"github.com/board-box/backend/internal/pagination"
+	"github.com/board-box/backend/internal/service/chat/llm"
+	"github.com/board-box/backend/internal/service/collection"
+	"github.com/board-box/backend/internal/service/game"
 )
 
+const (
+	systemPrompt = "Ты эксперт по настольным играм и сможешь подобрать нужную игру по запросу. " +
+		"Советуй только игры из каталога BoardBox: ищи их инструментами search_games и get_game, " +
+		"а list_my_collections покажет, что у пользователя уже есть. Называй игры точно так, как в каталоге. " +
+		"Отвечай коротко и по делу без схем и списков."
+
+	// Окно истории, которое уходит в модель: не больше historyMessageLimit
+	// последних сообщений и не больше historyTokenBudget токенов (оценка).
+	historyMessageLimit = 20
+	historyTokenBudget  = 3000
+
+	maxTitleLen = 60
+)
+
+var (
+	ErrEmptyTitle = errors.New("conversation title cannot be empty")
+	errToolLoop   = errors.New("model did not answer after tool calls")
+)
+
+// GameCatalog — то, что инструментам ассистента нужно от каталога игр.
+type GameCatalog interface {
+	ListGames(ctx context.Context, filter game.ListFilter, page pagination.Params) ([]game.Game, pagination.Meta, error)
+	GetGame(ctx context.Context, id int64) (game.Game, error)
+	GetGames(ctx context.Context, ids []int64) ([]game.Game, error)
+}
+
+// CollectionLister — то, что инструментам ассистента нужно от коллекций.
+type CollectionLister interface {
+	ListCollections(ctx context.Context, userID int64, filter collection.ListFilter, page pagination.Params) ([]collection.Collection, pagination.Meta, error)
+}
+
 type Service struct {
-	client  *client
-	history map[int64][]message
+	repo          Repository
+	provider      llm.Provider
+	gameSvc       GameCatalog
+	collectionSvc CollectionLister
 }
 
-func NewService(apiKey string) *Service {
+func NewService(repo Repository, provider llm.Provider, gameSvc GameCatalog, collectionSvc CollectionLister) *Service {
 	return &Service{
-		client:  newClient(apiKey),
-		history: make(map[int64][]message),
+		repo:          repo,
+		provider:      provider,
+		gameSvc:       gameSvc,
+		collectionSvc: collectionSvc,
+	}
+}
+
+func (s *Service) ListConversations(ctx context.Context, userID int64, page pagination.Params) ([]Conversation, pagination.Meta, error) {
+	if err := page.Validate(); err != nil {
+		return nil, pagination.Meta{}, err
+	}
+	return s.repo.ListConversations(ctx, userID, page)
+}
+
+// GetConversation возвращает диалог вместе со всеми сообщениями.
+func (s *Service) GetConversation(ctx context.Context, conversationID, userID int64) (Conversation, error) {
+	c, err := s.repo.GetConversation(ctx, conversationID, userID)
+	if err != nil {
+		return Conversation{}, err
+	}
+
+	c.Messages, err = s.repo.ListMessages(ctx, c.ID)
+	if err != nil {
+		return Conversation{}, err
+	}
+
+	return c, nil
+}
+
+func (s *Service) CreateConversation(ctx context.Context, userID int64, title string) (Conversation, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return Conversation{}, ErrEmptyTitle
+	}
+	return s.repo.CreateConversation(ctx, userID, title)
+}
+
+func (s *Service) RenameConversation(ctx context.Context, conversationID, userID int64, title string) (Conversation, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return Conversation{}, ErrEmptyTitle
+	}
+	return s.repo.RenameConversation(ctx, conversationID, userID, title)
+}
+
+func (s *Service) DeleteConversation(ctx context.Context, conversationID, userID int64) error {
+	return s.repo.DeleteConversation(ctx, conversationID, userID)
+}
+
+// Chat отправляет сообщение в диалог и возвращает ответ модели. Если
+// conversationID равен нулю, создаётся новый диалог с названием по сообщению;
+// он сохраняется вместе с первыми сообщениями, только если модель ответила.
+func (s *Service) Chat(ctx context.Context, userID, conversationID int64, msg string) (Conversation, Message, error) {
+	conv, history, err := s.openConversation(ctx, userID, conversationID, msg)
+	if err != nil {
+		return Conversation{}, Message{}, err
+	}
+
+	userMsg := Message{Role: RoleUser, Content: msg}
+	answer, err := s.generate(ctx, userID, buildPrompt(history, userMsg), nil)
+	if err != nil {
+		return Conversation{}, Message{}, err
+	}
+
+	var saved []Message
+	if conv.ID == 0 {
+		conv, saved, err = s.repo.CreateConversationWithMessages(ctx, userID, conv.Title, userMsg, answer)
+	} else {
+		saved, err = s.repo.SaveMessages(ctx, conv.ID, userMsg, answer)
+	}
+	if err != nil {
+		return Conversation{}, Message{}, err
+	}
+
+	return conv, saved[len(saved)-1], nil
+}
+
+// StreamObserver получает события потокового ответа.
+type StreamObserver interface {
+	// Start вызывается, когда диалог открыт и модель начала отвечать.
+	Start(conv Conversation) error
+	// Delta вызывается для каждого фрагмента ответа.
+	Delta(content string) error
+}
+
+// ChatStream работает как Chat, но отдаёт ответ по частям. Сообщения
+// сохраняются только после того, как ответ получен полностью; при отмене
+// ctx (клиент отключился) в истории ничего не остаётся. Новый диалог
+// создаётся сразу, чтобы клиент получил его ID в начале потока, и удаляется,
+// если ответа не получилось.
+func (s *Service) ChatStream(ctx context.Context, userID, conversationID int64, msg string, observer StreamObserver) (Conversation, Message, error) {
+	conv, history, err := s.openConversation(ctx, userID, conversationID, msg)
+	if err != nil {
+		return Conversation{}, Message{}, err
+	}
+	if conv.ID == 0 {
+		if conv, err = s.repo.CreateConversation(ctx, userID, conv.Title); err != nil {
+			return Conversation{}, Message{}, err
+		}
+	}
+
+	userMsg := Message{Role: RoleUser, Content: msg}
+	saved, err := func() ([]Message, error) {
+		if err := observer.Start(conv); err != nil {
+			return nil, err
+		}
+
+		answer, err := s.generate(ctx, userID, buildPrompt(history, userMsg), observer.Delta)
+		if err != nil {
+			return nil, err
+		}
+
+		// Клиент мог отключиться, но полный ответ уже получен — сохраняем его
+		return s.repo.SaveMessages(context.WithoutCancel(ctx), conv.ID, userMsg, answer)
+	}()
+	if err != nil {
+		if conversationID == 0 {
+			_ = s.repo.DeleteConversation(context.WithoutCancel(ctx), conv.ID, userID)
+		}
+		return Conversation{}, Message{}, err
 	}
+
+	return conv, saved[len(saved)-1], nil
 }
 
-func (s *Service) Chat(_ context.Context, userID int64, msg string) ([]string, error) {
-	if _, ok := s.history[userID]; !ok {
-		s.history[userID] = []message{
-			{Role: "system", Content: "Ты эксперт по настольным играм и сможешь подобрать нужную игру по запросу. Отвечай коротко и по делу без схем и списков."},
+// generate запрашивает ответ модели, выполняя вызовы инструментов, пока модель
+// не ответит текстом. Если onDelta не nil, текст отдаётся по частям, и в
+// ответ попадает весь отданный текст, включая раунды с вызовами инструментов.
+// В ответ попадают ID игр каталога, на которые он ссылается.
+func (s *Service) generate(ctx context.Context, userID int64, prompt []llm.Message, onDelta func(string) error) (Message, error) {
+	var (
+		results  []toolResult
+		streamed strings.Builder
+	)
+	for round := 0; round <= maxToolRounds; round++ {
+		// В последнем раунде инструменты не предлагаются, чтобы модель ответила текстом
+		tools := chatTools
+		if round == maxToolRounds {
+			tools = nil
+		}
+
+		var (
+			answer llm.Message
+			err    error
+		)
+		if onDelta == nil {
+			answer, err = s.provider.Complete(ctx, prompt, tools)
+		} else {
+			answer, err = s.provider.Stream(ctx, prompt, tools, onDelta)
 		}
+		if err != nil {
+			return Message{}, err
+		}
+
+		if len(answer.ToolCalls) == 0 {
+			content := streamed.String() + answer.Content
+			return Message{
+				Role:    RoleAssistant,
+				Content: content,
+				GameIDs: gameReferences(content, results),
+			}, nil
+		}
+		if onDelta != nil {
+			// Клиент уже получил этот текст, он должен остаться и в истории
+			streamed.WriteString(answer.Content)
+		}
+
+		prompt = append(prompt, llm.Message{Role: RoleAssistant, Content: answer.Content, ToolCalls: answer.ToolCalls})
+		for _, call := range answer.ToolCalls {
+			result, err := s.runTool(ctx, userID, call)
+			if err != nil {
+				return Message{}, err
+			}
+			results = append(results, result)
+			prompt = append(prompt, llm.Message{Role: RoleTool, Content: result.content, ToolCallID: call.ID})
+		}
+	}
+
+	return Message{}, errToolLoop
+}
+
+// openConversation возвращает диалог и окно его истории. Для нового диалога
+// (conversationID равен нулю) возвращает несохранённый диалог без ID.
+func (s *Service) openConversation(ctx context.Context, userID, conversationID int64, msg string) (Conversation, []Message, error) {
+	if conversationID == 0 {
+		return Conversation{UserID: userID, Title: titleFromMessage(msg)}, nil, nil
 	}
-	s.history[userID] = append(s.history[userID], message{Role: "user", Content: msg})
 
-	answer, err := s.client.chat(s.history[userID])
+	conv, err := s.repo.GetConversation(ctx, conversationID, userID)
 	if err != nil {
-		return nil, err
+		return Conversation{}, nil, err
 	}
-	s.history[userID] = append(s.history[userID], answer)
 
-	return lo.Map(s.history[userID][1:], func(msg message, index int) string {
-		return msg.Content
-	}), nil
+	history, err := s.repo.LastMessages(ctx, conv.ID, historyMessageLimit)
+	if err != nil {
+		return Conversation{}, nil, err
+	}
+
+	return conv, history, nil
+}
+
+// buildPrompt собирает системный промпт, окно истории и новое сообщение.
+// Старые сообщения отбрасываются, пока окно не уложится в бюджет токенов.
+func buildPrompt(history []Message, next Message) []llm.Message {
+	budget := historyTokenBudget - estimateTokens(systemPrompt) - estimateTokens(next.Content)
+
+	start := len(history)
+	for start > 0 {
+		cost := estimateTokens(history[start-1].Content)
+		if cost > budget {
+			break
+		}
+		budget -= cost
+		start--
+	}
+
+	prompt := make([]llm.Message, 0, len(history)-start+2)
+	prompt = append(prompt, llm.Message{Role: RoleSystem, Content: systemPrompt})
+	for _, m := range history[start:] {
+		prompt = append(prompt, llm.Message{Role: m.Role, Content: m.Content})
+	}
+	prompt = append(prompt, llm.Message{Role: next.Role, Content: next.Content})
+
+	return prompt
+}
+
+// estimateTokens грубо оценивает число токенов: около четырёх символов на токен
+// плюс служебные токены на сообщение.
+func estimateTokens(s string) int {
+	return utf8.RuneCountInString(s)/4 + 4
+}
+
+func titleFromMessage(msg string) string {
+	title := strings.Join(strings.Fields(msg), " ")
+	if utf8.RuneCountInString(title) <= maxTitleLen {
+		return title
+	}
+	return string([]rune(title)[:maxTitleLen]) + "…"
 }
@@ -0,0 +1,326 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/board-box/backend/internal/pagination"
+	"github.com/board-box/backend/internal/service/chat/llm"
+	"github.com/board-box/backend/internal/service/collection"
+	"github.com/board-box/backend/internal/service/game"
+)
+
+// memoryRepo — Repository в памяти для тестов сервиса.
+type memoryRepo struct {
+	conversations map[int64]Conversation
+	messages      map[int64][]Message
+	nextID        int64
+}
+
+func newMemoryRepo() *memoryRepo {
+	return &memoryRepo{conversations: map[int64]Conversation{}, messages: map[int64][]Message{}}
+}
+
+func (r *memoryRepo) ListConversations(_ context.Context, userID int64, _ pagination.Params) ([]Conversation, pagination.Meta, error) {
+	var out []Conversation
+	for _, c := range r.conversations {
+		if c.UserID == userID {
+			out = append(out, c)
+		}
+	}
+	return out, pagination.Meta{Total: int64(len(out))}, nil
+}
+
+func (r *memoryRepo) GetConversation(_ context.Context, conversationID, userID int64) (Conversation, error) {
+	c, ok := r.conversations[conversationID]
+	if !ok || c.UserID != userID {
+		return Conversation{}, ErrConversationNotFound
+	}
+	return c, nil
+}
+
+func (r *memoryRepo) CreateConversation(_ context.Context, userID int64, title string) (Conversation, error) {
+	r.nextID++
+	c := Conversation{ID: r.nextID, UserID: userID, Title: title}
+	r.conversations[c.ID] = c
+	return c, nil
+}
+
+func (r *memoryRepo) CreateConversationWithMessages(ctx context.Context, userID int64, title string, messages ...Message) (Conversation, []Message, error) {
+	c, _ := r.CreateConversation(ctx, userID, title)
+	saved, _ := r.SaveMessages(ctx, c.ID, messages...)
+	return c, saved, nil
+}
+
+func (r *memoryRepo) RenameConversation(ctx context.Context, conversationID, userID int64, title string) (Conversation, error) {
+	c, err := r.GetConversation(ctx, conversationID, userID)
+	if err != nil {
+		return Conversation{}, err
+	}
+	c.Title = title
+	r.conversations[c.ID] = c
+	return c, nil
+}
+
+func (r *memoryRepo) DeleteConversation(ctx context.Context, conversationID, userID int64) error {
+	if _, err := r.GetConversation(ctx, conversationID, userID); err != nil {
+		return err
+	}
+	delete(r.conversations, conversationID)
+	return nil
+}
+
+func (r *memoryRepo) ListMessages(_ context.Context, conversationID int64) ([]Message, error) {
+	return r.messages[conversationID], nil
+}
+
+func (r *memoryRepo) LastMessages(_ context.Context, conversationID int64, limit int) ([]Message, error) {
+	msgs := r.messages[conversationID]
+	return msgs[max(0, len(msgs)-limit):], nil
+}
+
+func (r *memoryRepo) SaveMessages(_ context.Context, conversationID int64, messages ...Message) ([]Message, error) {
+	for i := range messages {
+		r.nextID++
+		messages[i].ID = r.nextID
+	}
+	r.messages[conversationID] = append(r.messages[conversationID], messages...)
+	return messages, nil
+}
+
+type fakeCatalog struct {
+	games []game.Game
+}
+
+func (c fakeCatalog) ListGames(_ context.Context, filter game.ListFilter, _ pagination.Params) ([]game.Game, pagination.Meta, error) {
+	var out []game.Game
+	for _, g := range c.games {
+		if filter.Query == "" || strings.Contains(strings.ToLower(g.Title), strings.ToLower(filter.Query)) {
+			out = append(out, g)
+		}
+	}
+	return out, pagination.Meta{Total: int64(len(out))}, nil
+}
+
+func (c fakeCatalog) GetGame(_ context.Context, id int64) (game.Game, error) {
+	for _, g := range c.games {
+		if g.ID == id {
+			return g, nil
+		}
+	}
+	return game.Game{}, game.ErrGameNotFound
+}
+
+func (c fakeCatalog) GetGames(ctx context.Context, ids []int64) ([]game.Game, error) {
+	var out []game.Game
+	for _, id := range ids {
+		if g, err := c.GetGame(ctx, id); err == nil {
+			out = append(out, g)
+		}
+	}
+	return out, nil
+}
+
+type fakeCollections []collection.Collection
+
+func (c fakeCollections) ListCollections(context.Context, int64, collection.ListFilter, pagination.Params) ([]collection.Collection, pagination.Meta, error) {
+	return c, pagination.Meta{Total: int64(len(c))}, nil
+}
+
+func newTestService() (*Service, *memoryRepo) {
+	repo := newMemoryRepo()
+	catalog := fakeCatalog{games: []game.Game{
+		{ID: 1, Title: "Каркассон"},
+		{ID: 2, Title: "Кодовые имена"},
+		{ID: 3, Title: "Серп"},
+	}}
+	shelves := fakeCollections{{ID: 10, Name: "Полка", GameIDs: []int64{3}}}
+	return NewService(repo, llm.NewStub(), catalog, shelves), repo
+}
+
+func TestChatCreatesConversation(t *testing.T) {
+	s, repo := newTestService()
+
+	conv, answer, err := s.Chat(context.Background(), 1, 0, "Посоветуй   игру\nна вечер")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conv.Title != "Посоветуй игру на вечер" {
+		t.Errorf("title = %q", conv.Title)
+	}
+	if answer.Role != RoleAssistant || len(repo.messages[conv.ID]) != 2 {
+		t.Errorf("answer = %+v, stored %d messages", answer, len(repo.messages[conv.ID]))
+	}
+
+	if _, _, err = s.Chat(context.Background(), 2, conv.ID, "чужой диалог"); err != ErrConversationNotFound {
+		t.Errorf("foreign conversation: err = %v", err)
+	}
+}
+
+// failingProvider — модель, которая всегда отвечает ошибкой.
+type failingProvider struct{}
+
+func (failingProvider) Complete(context.Context, []llm.Message, []llm.Tool) (llm.Message, error) {
+	return llm.Message{}, errors.New("model is down")
+}
+
+func (failingProvider) Stream(context.Context, []llm.Message, []llm.Tool, func(string) error) (llm.Message, error) {
+	return llm.Message{}, errors.New("model is down")
+}
+
+type nopObserver struct{}
+
+func (nopObserver) Start(Conversation) error { return nil }
+func (nopObserver) Delta(string) error       { return nil }
+
+func TestChatFailureLeavesNoConversation(t *testing.T) {
+	repo := newMemoryRepo()
+	s := NewService(repo, failingProvider{}, fakeCatalog{}, fakeCollections{})
+
+	if _, _, err := s.Chat(context.Background(), 1, 0, "Посоветуй игру"); err == nil {
+		t.Fatal("Chat() ignored a model error")
+	}
+	if _, _, err := s.ChatStream(context.Background(), 1, 0, "Посоветуй игру", nopObserver{}); err == nil {
+		t.Fatal("ChatStream() ignored a model error")
+	}
+	if len(repo.conversations) != 0 {
+		t.Errorf("conversations after failed answers = %+v", repo.conversations)
+	}
+}
+
+// toolThenAnswerProvider сначала пишет вступление и вызывает get_game, затем
+// отвечает текстом.
+type toolThenAnswerProvider struct {
+	rounds int
+}
+
+func (p *toolThenAnswerProvider) Complete(ctx context.Context, messages []llm.Message, tools []llm.Tool) (llm.Message, error) {
+	return p.Stream(ctx, messages, tools, func(string) error { return nil })
+}
+
+func (p *toolThenAnswerProvider) Stream(_ context.Context, _ []llm.Message, _ []llm.Tool, onDelta func(string) error) (llm.Message, error) {
+	p.rounds++
+	if p.rounds == 1 {
+		msg := llm.Message{
+			Role:      RoleAssistant,
+			Content:   "Сейчас посмотрю. ",
+			ToolCalls: []llm.ToolCall{{ID: "call-1", Type: "function", Function: llm.FunctionCall{Name: "get_game", Arguments: `{"id":3}`}}},
+		}
+		return msg, onDelta(msg.Content)
+	}
+	msg := llm.Message{Role: RoleAssistant, Content: "Советую Серп."}
+	return msg, onDelta(msg.Content)
+}
+
+type recordingObserver struct {
+	text strings.Builder
+}
+
+func (o *recordingObserver) Start(Conversation) error { return nil }
+func (o *recordingObserver) Delta(content string) error {
+	o.text.WriteString(content)
+	return nil
+}
+
+func TestChatStreamSavesStreamedText(t *testing.T) {
+	repo := newMemoryRepo()
+	s := NewService(repo, &toolThenAnswerProvider{}, fakeCatalog{games: []game.Game{{ID: 3, Title: "Серп"}}}, fakeCollections{})
+
+	var observer recordingObserver
+	conv, answer, err := s.ChatStream(context.Background(), 1, 0, "Что посоветуешь?", &observer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// В истории тот же текст, что получил клиент, включая раунд с инструментом
+	want := "Сейчас посмотрю. Советую Серп."
+	if got := observer.text.String(); got != want {
+		t.Errorf("streamed = %q, want %q", got, want)
+	}
+	stored := repo.messages[conv.ID]
+	if answer.Content != want || len(stored) != 2 || stored[1].Content != want {
+		t.Errorf("answer = %q, stored = %+v, want %q", answer.Content, stored, want)
+	}
+	if !equalIDs(answer.GameIDs, []int64{3}) {
+		t.Errorf("game_ids = %v, want [3]", answer.GameIDs)
+	}
+}
+
+func TestChatToolReferences(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		want []int64
+	}{
+		{name: "search", msg: `/search_games {"query":"ка"}`, want: []int64{1}},
+		{name: "get game", msg: `/get_game {"id":2}`, want: []int64{2}},
+		{name: "collections", msg: `/list_my_collections`, want: []int64{3}},
+		{name: "unknown game", msg: `/get_game {"id":99}`, want: nil},
+		{name: "bad arguments", msg: `/get_game {`, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newTestService()
+
+			conv, answer, err := s.Chat(context.Background(), 1, 0, tt.msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !equalIDs(answer.GameIDs, tt.want) {
+				t.Errorf("game_ids = %v, want %v (answer %q)", answer.GameIDs, tt.want, answer.Content)
+			}
+
+			// В историю попадают только вопрос и итоговый ответ, без вызовов инструментов
+			stored := repo.messages[conv.ID]
+			if len(stored) != 2 || !equalIDs(stored[1].GameIDs, tt.want) {
+				t.Errorf("stored messages = %+v", stored)
+			}
+		})
+	}
+}
+
+func TestBuildPromptWindow(t *testing.T) {
+	long := strings.Repeat("слово ", 2000)
+	history := []Message{
+		{Role: RoleUser, Content: long},
+		{Role: RoleAssistant, Content: "короткий ответ"},
+		{Role: RoleUser, Content: "ещё вопрос"},
+	}
+
+	prompt := buildPrompt(history, Message{Role: RoleUser, Content: "последний"})
+
+	// Длинное сообщение не влезает в бюджет и отбрасывается вместе со всем, что старше
+	if len(prompt) != 4 {
+		t.Fatalf("prompt has %d messages: %+v", len(prompt), prompt)
+	}
+	if prompt[0].Role != RoleSystem || prompt[1].Content != "короткий ответ" || prompt[3].Content != "последний" {
+		t.Errorf("unexpected prompt: %+v", prompt)
+	}
+}
+
+func TestGameReferences(t *testing.T) {
+	results := []toolResult{
+		{games: []gameRef{{ID: 1, Title: "Каркассон"}, {ID: 2, Title: "Серп"}}},
+		{games: []gameRef{{ID: 3, Title: "Манчкин"}}, explicit: true},
+	}
+
+	got := gameReferences("Попробуйте КАРКАССОН — он проще.", results)
+	if want := []int64{1, 3}; !equalIDs(got, want) {
+		t.Errorf("gameReferences = %v, want %v", got, want)
+	}
+}
+
+func equalIDs(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,272 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/board-box/backend/internal/pagination"
+	"github.com/board-box/backend/internal/service/chat/llm"
+	"github.com/board-box/backend/internal/service/collection"
+	"github.com/board-box/backend/internal/service/game"
+)
+
+const (
+	toolSearchGames       = "search_games"
+	toolGetGame           = "get_game"
+	toolListMyCollections = "list_my_collections"
+
+	// Сколько раундов вызова инструментов допускается на одно сообщение
+	maxToolRounds = 4
+
+	searchGamesDefaultLimit = 10
+	searchGamesMaxLimit     = 20
+	collectionsToolLimit    = 50
+)
+
+var errUnknownTool = errors.New("unknown tool")
+
+var chatTools = []llm.Tool{
+	{
+		Name:        toolSearchGames,
+		Description: "Поиск игр в каталоге BoardBox по тексту и фильтрам. Возвращает только игры, которые есть в каталоге.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"query": {"type": "string", "description": "Текст для поиска по названию и описанию"},
+				"genre": {"type": "string", "description": "Жанр"},
+				"players": {"type": "integer", "description": "Количество игроков"},
+				"max_time": {"type": "integer", "description": "Максимальное время партии в минутах"},
+				"age": {"type": "integer", "description": "Возраст самого младшего игрока"},
+				"limit": {"type": "integer", "description": "Сколько игр вернуть, не больше 20"}
+			}
+		}`),
+	},
+	{
+		Name:        toolGetGame,
+		Description: "Подробная информация об игре из каталога по её ID.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"id": {"type": "integer", "description": "ID игры"}
+			},
+			"required": ["id"]
+		}`),
+	},
+	{
+		Name:        toolListMyCollections,
+		Description: "Коллекции текущего пользователя с играми в них. Используй, чтобы не советовать то, что у пользователя уже есть.",
+		Parameters:  json.RawMessage(`{"type": "object", "properties": {}}`),
+	},
+}
+
+type searchGamesArgs struct {
+	Query   string `json:"query"`
+	Genre   string `json:"genre"`
+	Players int    `json:"players"`
+	MaxTime int    `json:"max_time"`
+	Age     int    `json:"age"`
+	Limit   int    `json:"limit"`
+}
+
+type getGameArgs struct {
+	ID int64 `json:"id"`
+}
+
+// gameRef — краткое описание игры, которое видит модель.
+type gameRef struct {
+	ID          int64  `json:"id"`
+	Title       string `json:"title"`
+	Genre       string `json:"genre,omitempty"`
+	Players     string `json:"players,omitempty"`
+	PlayTime    string `json:"play_time,omitempty"`
+	Age         string `json:"age,omitempty"`
+	Complexity  string `json:"complexity,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// collectionRef — коллекция для модели: Games — только первые игры, всего их
+// GameCount.
+type collectionRef struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	GameCount int       `json:"game_count"`
+	Games     []gameRef `json:"games"`
+}
+
+// toolResult — ответ инструмента для модели и игры, которые в нём встретились.
+type toolResult struct {
+	content string
+	games   []gameRef
+	// explicit — игры, запрошенные по ID; на них ссылаемся всегда
+	explicit bool
+}
+
+// runTool выполняет вызов инструмента от имени пользователя. Ошибки
+// аргументов и «не найдено» возвращаются модели текстом, чтобы она могла
+// поправиться; наружу уходят только ошибки инфраструктуры.
+func (s *Service) runTool(ctx context.Context, userID int64, call llm.ToolCall) (toolResult, error) {
+	switch call.Function.Name {
+	case toolSearchGames:
+		var args searchGamesArgs
+		if err := decodeToolArgs(call, &args); err != nil {
+			return toolError(err), nil
+		}
+		return s.searchGames(ctx, args)
+	case toolGetGame:
+		var args getGameArgs
+		if err := decodeToolArgs(call, &args); err != nil {
+			return toolError(err), nil
+		}
+		return s.getGame(ctx, args)
+	case toolListMyCollections:
+		return s.listMyCollections(ctx, userID)
+	}
+
+	return toolError(fmt.Errorf("%w %q", errUnknownTool, call.Function.Name)), nil
+}
+
+func (s *Service) searchGames(ctx context.Context, args searchGamesArgs) (toolResult, error) {
+	limit := args.Limit
+	if limit <= 0 {
+		limit = searchGamesDefaultLimit
+	}
+	limit = min(limit, searchGamesMaxLimit)
+
+	filter := game.ListFilter{
+		Query:     strings.TrimSpace(args.Query),
+		Players:   args.Players,
+		MaxTime:   args.MaxTime,
+		PlayerAge: args.Age,
+	}
+	if genre := strings.TrimSpace(args.Genre); genre != "" {
+		filter.Genres = []string{genre}
+	}
+
+	games, _, err := s.gameSvc.ListGames(ctx, filter, pagination.Params{Limit: limit})
+	if err != nil {
+		return toolResult{}, err
+	}
+
+	refs := make([]gameRef, 0, len(games))
+	for _, g := range games {
+		refs = append(refs, newGameRef(g, false))
+	}
+
+	return toolOK(refs, refs, false)
+}
+
+func (s *Service) getGame(ctx context.Context, args getGameArgs) (toolResult, error) {
+	g, err := s.gameSvc.GetGame(ctx, args.ID)
+	if errors.Is(err, game.ErrGameNotFound) {
+		return toolError(fmt.Errorf("game %d not found", args.ID)), nil
+	}
+	if err != nil {
+		return toolResult{}, err
+	}
+
+	ref := newGameRef(g, true)
+	return toolOK(ref, []gameRef{ref}, true)
+}
+
+func (s *Service) listMyCollections(ctx context.Context, userID int64) (toolResult, error) {
+	collections, _, err := s.collectionSvc.ListCollections(ctx, userID, collection.ListFilter{}, pagination.Params{Limit: collectionsToolLimit})
+	if err != nil {
+		return toolResult{}, err
+	}
+
+	var ids []int64
+	for _, c := range collections {
+		ids = append(ids, c.GameIDs...)
+	}
+
+	titles := make(map[int64]gameRef, len(ids))
+	if len(ids) > 0 {
+		games, err := s.gameSvc.GetGames(ctx, ids)
+		if err != nil && !errors.Is(err, game.ErrGameNotFound) {
+			return toolResult{}, err
+		}
+		for _, g := range games {
+			titles[g.ID] = gameRef{ID: g.ID, Title: g.Title}
+		}
+	}
+
+	refs := make([]collectionRef, 0, len(collections))
+	var games []gameRef
+	for _, c := range collections {
+		ref := collectionRef{ID: c.ID, Name: c.Name, GameCount: c.GameCount, Games: []gameRef{}}
+		for _, id := range c.GameIDs {
+			if g, ok := titles[id]; ok {
+				ref.Games = append(ref.Games, g)
+				games = append(games, g)
+			}
+		}
+		refs = append(refs, ref)
+	}
+
+	return toolOK(refs, games, false)
+}
+
+func newGameRef(g game.Game, detailed bool) gameRef {
+	ref := gameRef{
+		ID:         g.ID,
+		Title:      g.Title,
+		Genre:      g.Genre,
+		Players:    g.Person,
+		PlayTime:   g.AvgTime,
+		Age:        g.Age,
+		Complexity: g.Difficulty,
+	}
+	if detailed {
+		ref.Description = g.Description
+	}
+	return ref
+}
+
+func decodeToolArgs(call llm.ToolCall, dst any) error {
+	args := strings.TrimSpace(call.Function.Arguments)
+	if args == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(args), dst); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+func toolOK(payload any, games []gameRef, explicit bool) (toolResult, error) {
+	content, err := json.Marshal(payload)
+	if err != nil {
+		return toolResult{}, err
+	}
+	return toolResult{content: string(content), games: games, explicit: explicit}, nil
+}
+
+func toolError(err error) toolResult {
+	content, _ := json.Marshal(map[string]string{"error": err.Error()})
+	return toolResult{content: string(content)}
+}
+
+// gameReferences выбирает игры, на которые ссылается ответ: запрошенные по ID
+// и найденные инструментами, чьё название упомянуто в тексте.
+func gameReferences(answer string, results []toolResult) []int64 {
+	text := strings.ToLower(answer)
+
+	var ids []int64
+	seen := make(map[int64]bool)
+	for _, r := range results {
+		for _, g := range r.games {
+			if seen[g.ID] || g.Title == "" {
+				continue
+			}
+			if r.explicit || strings.Contains(text, strings.ToLower(g.Title)) {
+				seen[g.ID] = true
+				ids = append(ids, g.ID)
+			}
+		}
+	}
+
+	return ids
+}
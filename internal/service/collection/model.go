@@ -1,9 +1,217 @@
 package collection
 
+import (
+	"time"
+
+	"github.com/board-box/backend/internal/service/game"
+)
+
+// Visibility определяет, кто кроме владельца видит коллекцию.
+type Visibility string
+
+const (
+	// VisibilityPrivate — коллекцию видит только владелец.
+	VisibilityPrivate Visibility = "private"
+	// VisibilityUnlisted — коллекцию видит любой, у кого есть ссылка.
+	VisibilityUnlisted Visibility = "unlisted"
+	// VisibilityPublic — коллекция доступна по ссылке и в списке публичных
+	// коллекций владельца.
+	VisibilityPublic Visibility = "public"
+)
+
+func (v Visibility) Valid() bool {
+	switch v {
+	case VisibilityPrivate, VisibilityUnlisted, VisibilityPublic:
+		return true
+	}
+	return false
+}
+
+// Type — вид коллекции. Кроме обычных коллекций у каждого пользователя есть
+// по одной системной коллекции каждого вида; они создаются сами и не удаляются.
+type Type string
+
+const (
+	TypeCustom          Type = "custom"
+	TypeOwned           Type = "owned"
+	TypeWishlist        Type = "wishlist"
+	TypeWantToPlay      Type = "want_to_play"
+	TypePreviouslyOwned Type = "previously_owned"
+)
+
+// systemCollections — системные коллекции и их названия при создании.
+var systemCollections = []struct {
+	Type Type
+	Name string
+}{
+	{TypeOwned, "Моя коллекция"},
+	{TypeWishlist, "Хочу купить"},
+	{TypeWantToPlay, "Хочу сыграть"},
+	{TypePreviouslyOwned, "Были в коллекции"},
+}
+
+func (t Type) Valid() bool {
+	return t == TypeCustom || t.System()
+}
+
+// System сообщает, что коллекция этого вида системная.
+func (t Type) System() bool {
+	for _, sc := range systemCollections {
+		if sc.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Priority — приоритет игры в списке желаний, от MinPriority (купить в первую
+// очередь) до MaxPriority.
+const (
+	MinPriority = 1
+	MaxPriority = 5
+)
+
+// Condition — состояние экземпляра игры.
+type Condition string
+
+const (
+	ConditionNew     Condition = "new"
+	ConditionLikeNew Condition = "like_new"
+	ConditionGood    Condition = "good"
+	ConditionFair    Condition = "fair"
+	ConditionPoor    Condition = "poor"
+)
+
+func (c Condition) Valid() bool {
+	switch c {
+	case ConditionNew, ConditionLikeNew, ConditionGood, ConditionFair, ConditionPoor:
+		return true
+	}
+	return false
+}
+
+// Role — права участника коллекции. Каждая следующая роль включает права
+// предыдущей: viewer смотрит, editor добавляет и убирает игры, owner
+// управляет коллекцией и участниками.
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleOwner  Role = "owner"
+)
+
+var roleRank = map[Role]int{RoleViewer: 1, RoleEditor: 2, RoleOwner: 3}
+
+func (r Role) Valid() bool {
+	return roleRank[r] > 0
+}
+
+// AtLeast сообщает, есть ли у роли права required. Пустая роль — не участник.
+func (r Role) AtLeast(required Role) bool {
+	return roleRank[r] > 0 && roleRank[r] >= roleRank[required]
+}
+
+// Collection — коллекция игр. UserID — создатель коллекции: он всегда
+// остаётся её владельцем.
 type Collection struct {
-	ID      int64   `json:"id" db:"id"`
-	UserID  int64   `json:"user_id" db:"user_id"`
-	Name    string  `json:"name" db:"name"`
-	Pinned  bool    `json:"pinned" db:"pinned"`
-	GameIDs []int64 `json:"game_ids,omitempty"`
+	ID         int64      `json:"id" db:"id"`
+	UserID     int64      `json:"user_id" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	Type       Type       `json:"type" db:"type"`
+	Pinned     bool       `json:"pinned" db:"pinned"`
+	Visibility Visibility `json:"visibility" db:"visibility"`
+	// Role — роль текущего пользователя в коллекции
+	Role Role `json:"role,omitempty" db:"role"`
+	// ShareSlug — адрес коллекции для ссылки; появляется, когда коллекцию
+	// впервые открывают для других
+	ShareSlug *string `json:"share_slug,omitempty" db:"share_slug"`
+	// GameCount — число игр в коллекции
+	GameCount int `json:"game_count" db:"game_count"`
+	// GameIDs — первые игры коллекции по порядку (не больше десяти);
+	// заполняется в списках коллекций. Все игры — в ListCollectionGameIDs.
+	GameIDs []int64 `json:"game_ids,omitempty" db:"game_ids"`
+	// Entries — записи коллекции с данными игр; заполняется при получении
+	// одной коллекции
+	Entries   []Entry   `json:"entries,omitempty"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SharedCollection — коллекция, открытая по ссылке: только чтение, игры
+// целиком и имя владельца вместо его ID.
+type SharedCollection struct {
+	ID         int64       `json:"-" db:"id"`
+	Slug       string      `json:"slug" db:"share_slug"`
+	Name       string      `json:"name" db:"name"`
+	Owner      string      `json:"owner" db:"owner"`
+	Visibility Visibility  `json:"visibility" db:"visibility"`
+	Games      []game.Game `json:"games"`
+	CreatedAt  time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// Entry — игра в коллекции вместе с данными экземпляра. Position задаёт
+// порядок записей, меньшие идут первыми.
+type Entry struct {
+	GameID       int64      `json:"game_id" db:"game_id"`
+	Position     int        `json:"position" db:"position"`
+	Note         *string    `json:"note" db:"note"`
+	Condition    *Condition `json:"condition" db:"condition"`
+	PurchaseDate *time.Time `json:"purchase_date" db:"purchase_date"`
+	Price        *float64   `json:"price" db:"price"`
+	LentOut      bool       `json:"lent_out" db:"lent_out"`
+	// LentTo — кому одолжена игра, если известно
+	LentTo *string `json:"lent_to" db:"lent_to"`
+	// Expansions — названия дополнений, которые есть вместе с игрой
+	Expansions []string `json:"expansions" db:"expansions"`
+	// Priority — приоритет в списке желаний; у других коллекций пуст
+	Priority *int      `json:"priority" db:"priority"`
+	AddedAt  time.Time `json:"added_at" db:"added_at"`
+	Game     game.Game `json:"game" db:"-"`
+}
+
+// ListFilter отбирает коллекции; нулевые значения не фильтруют.
+type ListFilter struct {
+	Type Type
+}
+
+// MoveTarget — куда перенести игру: в коллекцию CollectionID или в свою
+// системную коллекцию вида Type.
+type MoveTarget struct {
+	CollectionID int64
+	Type         Type
+}
+
+// ExportRow — игра коллекции в выгрузке: одна строка на пару коллекция-игра.
+type ExportRow struct {
+	CollectionID   int64     `db:"collection_id"`
+	CollectionName string    `db:"collection_name"`
+	AddedAt        time.Time `db:"added_at"`
+	Game           game.Game `db:"game"`
+}
+
+// Member — участник коллекции или приглашённый, который ещё не принял
+// приглашение (AcceptedAt пуст).
+type Member struct {
+	UserID     int64      `json:"user_id" db:"user_id"`
+	Username   string     `json:"username" db:"username"`
+	Role       Role       `json:"role" db:"role"`
+	InvitedAt  time.Time  `json:"invited_at" db:"invited_at"`
+	AcceptedAt *time.Time `json:"accepted_at" db:"accepted_at"`
+}
+
+// Invite — приглашение текущего пользователя в чужую коллекцию.
+type Invite struct {
+	CollectionID   int64     `json:"collection_id" db:"collection_id"`
+	CollectionName string    `json:"collection_name" db:"collection_name"`
+	Role           Role      `json:"role" db:"role"`
+	InvitedBy      string    `json:"invited_by" db:"invited_by"`
+	InvitedAt      time.Time `json:"invited_at" db:"invited_at"`
+}
+
+// Invitee — кого пригласить: по имени пользователя или по email.
+type Invitee struct {
+	Username string
+	Email    string
 }
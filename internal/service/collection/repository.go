@@ -3,66 +3,231 @@ package collection
 import (
 	"context"
 	"errors"
-	"time"
+	"fmt"
+	"strings"
 
 	"github.com/Masterminds/squirrel"
+	"github.com/board-box/backend/internal/db"
+	"github.com/board-box/backend/internal/pagination"
 	"github.com/georgysavva/scany/v2/pgxscan"
 	pgx "github.com/jackc/pgx/v5"
 )
 
 const (
-	collectionTableName     = "collection"
-	collectionGameTableName = "collection_game"
+	collectionTableName       = "collection"
+	collectionGameTableName   = "collection_game"
+	collectionMemberTableName = "collection_member"
+	gameTableName             = "game"
+	userTableName             = "users"
+
+	// memberOf отбирает коллекции, в которых пользователь — участник, принявший приглашение.
+	memberOf = "id IN (SELECT collection_id FROM collection_member WHERE user_id = ? AND accepted_at IS NOT NULL)"
+	// memberRole — роль пользователя в коллекции или пустая строка.
+	memberRole = "COALESCE((SELECT m.role FROM collection_member m " +
+		"WHERE m.collection_id = collection.id AND m.user_id = ? AND m.accepted_at IS NOT NULL), '') AS role"
+	// gameCount — число игр в коллекции.
+	gameCount = "(SELECT COUNT(*) FROM collection_game cg WHERE cg.collection_id = collection.id) AS game_count"
+	// gamePreview — первые игры коллекции по порядку, не больше ?.
+	gamePreview = "ARRAY(SELECT cg.game_id FROM collection_game cg WHERE cg.collection_id = collection.id " +
+		"ORDER BY cg.position, cg.game_id LIMIT ?) AS game_ids"
+
+	// gamePreviewLimit — сколько игр коллекции показывается в списках
+	// коллекций; все игры — в ListCollectionGameIDs.
+	gamePreviewLimit = 10
 )
 
+var collectionColumns = []string{"id", "user_id", "name", "type", "pinned", "visibility", "share_slug", "created_at", "updated_at"}
+
+// exportGameColumns — поля игры в выгрузке коллекций, без длинных описаний и правил.
+var exportGameColumns = []string{
+	"id", "title", "genre", "age", "person", "avg_time", "difficulty", "image",
+	"min_players", "max_players", "min_play_time", "max_play_time", "min_age", "difficulty_level", "external_id",
+}
+
 var (
 	psql = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 )
 
+// Repository — хранилище коллекций пользователей.
+type Repository interface {
+	ListCollections(ctx context.Context, userID int64, filter ListFilter, page pagination.Params) ([]Collection, pagination.Meta, error)
+	CreateSystemCollections(ctx context.Context, userID int64) error
+	GetSystemCollectionID(ctx context.Context, userID int64, t Type) (int64, error)
+	ListPublicCollections(ctx context.Context, ownerID int64, page pagination.Params) ([]Collection, pagination.Meta, error)
+	GetCollection(ctx context.Context, collectionID, userID int64) (Collection, error)
+	GetSharedCollection(ctx context.Context, slug string) (SharedCollection, []int64, error)
+	ListCollectionGameIDs(ctx context.Context, collectionID int64, page pagination.Params) ([]int64, pagination.Meta, error)
+	ListEntries(ctx context.Context, collectionID int64) ([]Entry, error)
+	UpdateEntry(ctx context.Context, collectionID, userID int64, entry Entry) (Entry, error)
+	ReorderEntries(ctx context.Context, collectionID, userID int64, gameIDs []int64) error
+	CreateCollection(ctx context.Context, userID int64, req Collection) (Collection, error)
+	UpdateCollection(ctx context.Context, collectionID, userID int64, req Collection) (Collection, error)
+	SetShareSlug(ctx context.Context, collectionID, userID int64, slug string) (Collection, error)
+	DeleteCollection(ctx context.Context, collectionID, userID int64) error
+	AddGameToCollection(ctx context.Context, collectionID, gameID, userID int64) error
+	RemoveGameFromCollection(ctx context.Context, collectionID, gameID, userID int64) error
+	MoveGame(ctx context.Context, fromID, toID, gameID, userID int64) error
+	EachExportRow(ctx context.Context, userID, collectionID int64, fn func(ExportRow) error) error
+	ListMembers(ctx context.Context, collectionID int64) ([]Member, error)
+	InviteMember(ctx context.Context, collectionID, inviterID int64, invitee Invitee, role Role) (Member, error)
+	UpdateMemberRole(ctx context.Context, collectionID, actorID, memberID int64, role Role) (Member, error)
+	RemoveMember(ctx context.Context, collectionID, actorID, memberID int64) error
+	ListInvites(ctx context.Context, userID int64) ([]Invite, error)
+	AcceptInvite(ctx context.Context, collectionID, userID int64) error
+	DeclineInvite(ctx context.Context, collectionID, userID int64) error
+}
+
 type repository struct {
-	db *pgx.Conn
+	db db.DB
 }
 
-func newRepository(db *pgx.Conn) *repository {
+func NewRepository(db db.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) listCollections(ctx context.Context, userID int64) ([]Collection, error) {
+type collectionCursor struct {
+	Pinned bool   `json:"p"`
+	Name   string `json:"n"`
+	ID     int64  `json:"id"`
+}
+
+type collectionGameCursor struct {
+	Position int   `json:"p"`
+	GameID   int64 `json:"id"`
+}
+
+// ListCollections возвращает коллекции, в которых пользователь участвует,
+// в том числе чужие.
+func (r *repository) ListCollections(ctx context.Context, userID int64, filter ListFilter, page pagination.Params) ([]Collection, pagination.Meta, error) {
+	where := squirrel.And{squirrel.Expr(memberOf, userID)}
+	if filter.Type != "" {
+		where = append(where, squirrel.Eq{"type": filter.Type})
+	}
+	return r.listCollections(ctx, userID, where, page)
+}
+
+// CreateSystemCollections создаёт недостающие системные коллекции
+// пользователя. Уже созданные не меняются, поэтому повторный вызов безопасен.
+func (r *repository) CreateSystemCollections(ctx context.Context, userID int64) error {
+	values := make([]string, 0, len(systemCollections))
+	args := make([]any, 0, 3*len(systemCollections))
+	for _, sc := range systemCollections {
+		values = append(values, "(?, ?, ?)")
+		args = append(args, userID, sc.Type, sc.Name)
+	}
+
+	// Участник-владелец добавляется только к коллекциям, созданным сейчас.
+	// Вложенный SELECT собирается с ?, номера параметров расставляет psql.
 	query, args, err := psql.
-		Select("id", "name", "pinned", "created_at", "updated_at").
-		From(collectionTableName).
-		Where(squirrel.Eq{"user_id": userID}).
-		OrderBy("pinned DESC, name ASC").
+		Insert(collectionMemberTableName).
+		Prefix(
+			"WITH created AS (INSERT INTO "+collectionTableName+" (user_id, type, name) VALUES "+
+				strings.Join(values, ", ")+" ON CONFLICT DO NOTHING RETURNING id, user_id)",
+			args...,
+		).
+		Columns("collection_id", "user_id", "role", "accepted_at").
+		Select(squirrel.
+			Select("id", "user_id").
+			Column("?", RoleOwner).
+			Column("NOW()").
+			From("created")).
 		ToSql()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	rows, err := r.db.Query(ctx, query, args...)
+	_, err = r.db.Exec(ctx, query, args...)
+	return err
+}
+
+// GetSystemCollectionID возвращает ID системной коллекции пользователя вида t.
+func (r *repository) GetSystemCollectionID(ctx context.Context, userID int64, t Type) (int64, error) {
+	query, args, err := psql.
+		Select("id").
+		From(collectionTableName).
+		Where(squirrel.Eq{"user_id": userID, "type": t}).
+		ToSql()
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	defer rows.Close()
 
-	var collections []Collection
-	for rows.Next() {
-		var c Collection
-		if err := pgxscan.ScanRow(&c, rows); err != nil {
-			return nil, err
+	var id int64
+	if err = r.db.QueryRow(ctx, query, args...).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrCollectionNotFound
 		}
-		c.UserID = userID
-		collections = append(collections, c)
+		return 0, err
 	}
 
-	for i := range collections {
-		gameIDs, err := r.getCollectionGameIDs(ctx, collections[i].ID)
-		if err != nil {
-			return nil, err
+	return id, nil
+}
+
+// ListPublicCollections возвращает публичные коллекции, созданные пользователем,
+// в том же порядке, что и ListCollections.
+func (r *repository) ListPublicCollections(ctx context.Context, ownerID int64, page pagination.Params) ([]Collection, pagination.Meta, error) {
+	return r.listCollections(ctx, 0, squirrel.Eq{"user_id": ownerID, "visibility": VisibilityPublic}, page)
+}
+
+// listCollections возвращает страницу коллекций с ролью в них пользователя
+// viewerID, числом игр и первыми играми — одним запросом.
+func (r *repository) listCollections(ctx context.Context, viewerID int64, where squirrel.Sqlizer, page pagination.Params) ([]Collection, pagination.Meta, error) {
+	limit := page.PageLimit()
+
+	builder := psql.
+		Select(collectionColumns...).
+		Column(memberRole, viewerID).
+		Column(gameCount).
+		Column(gamePreview, gamePreviewLimit).
+		From(collectionTableName).
+		Where(where).
+		OrderBy("pinned DESC", "name ASC", "id ASC").
+		Limit(uint64(limit + 1))
+
+	if page.Cursor != "" {
+		var cursor collectionCursor
+		if err := pagination.Decode(page.Cursor, &cursor); err != nil {
+			return nil, pagination.Meta{}, err
 		}
-		collections[i].GameIDs = gameIDs
+		builder = builder.Where(
+			"(pinned < ? OR (pinned = ? AND (name, id) > (?, ?)))",
+			cursor.Pinned, cursor.Pinned, cursor.Name, cursor.ID,
+		)
+	}
+	if page.Offset > 0 {
+		builder = builder.Offset(uint64(page.Offset))
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	var collections []Collection
+	if err = pgxscan.Select(ctx, r.db, &collections, query, args...); err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	meta := pagination.Meta{}
+	collections, hasMore := pagination.Trim(collections, limit)
+	if hasMore {
+		last := collections[len(collections)-1]
+		meta.NextCursor = pagination.Encode(collectionCursor{Pinned: last.Pinned, Name: last.Name, ID: last.ID})
+	}
+
+	query, args, err = psql.
+		Select("COUNT(*)").
+		From(collectionTableName).
+		Where(where).
+		ToSql()
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	if err = r.db.QueryRow(ctx, query, args...).Scan(&meta.Total); err != nil {
+		return nil, pagination.Meta{}, err
 	}
 
-	return collections, nil
+	return collections, meta, nil
 }
 
 func (r *repository) getCollectionGameIDs(ctx context.Context, collectionID int64) ([]int64, error) {
@@ -70,6 +235,7 @@ func (r *repository) getCollectionGameIDs(ctx context.Context, collectionID int6
 		Select("game_id").
 		From(collectionGameTableName).
 		Where(squirrel.Eq{"collection_id": collectionID}).
+		OrderBy("position ASC", "game_id ASC").
 		ToSql()
 	if err != nil {
 		return nil, err
@@ -93,11 +259,86 @@ func (r *repository) getCollectionGameIDs(ctx context.Context, collectionID int6
 	return gameIDs, nil
 }
 
-func (r *repository) getCollection(ctx context.Context, collectionID, userID int64) (Collection, error) {
+func (r *repository) ListCollectionGameIDs(ctx context.Context, collectionID int64, page pagination.Params) ([]int64, pagination.Meta, error) {
+	limit := page.PageLimit()
+
+	builder := psql.
+		Select("game_id", "position").
+		From(collectionGameTableName).
+		Where(squirrel.Eq{"collection_id": collectionID}).
+		OrderBy("position ASC", "game_id ASC").
+		Limit(uint64(limit + 1))
+
+	if page.Cursor != "" {
+		var cursor collectionGameCursor
+		if err := pagination.Decode(page.Cursor, &cursor); err != nil {
+			return nil, pagination.Meta{}, err
+		}
+		builder = builder.Where("(position, game_id) > (?, ?)", cursor.Position, cursor.GameID)
+	}
+	if page.Offset > 0 {
+		builder = builder.Offset(uint64(page.Offset))
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	var entries []collectionGameCursor
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e collectionGameCursor
+		if err = rows.Scan(&e.GameID, &e.Position); err != nil {
+			return nil, pagination.Meta{}, err
+		}
+		entries = append(entries, e)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	meta := pagination.Meta{}
+	entries, hasMore := pagination.Trim(entries, limit)
+	if hasMore {
+		meta.NextCursor = pagination.Encode(entries[len(entries)-1])
+	}
+
+	query, args, err = psql.
+		Select("COUNT(*)").
+		From(collectionGameTableName).
+		Where(squirrel.Eq{"collection_id": collectionID}).
+		ToSql()
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	if err = r.db.QueryRow(ctx, query, args...).Scan(&meta.Total); err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	gameIDs := make([]int64, 0, len(entries))
+	for _, e := range entries {
+		gameIDs = append(gameIDs, e.GameID)
+	}
+
+	return gameIDs, meta, nil
+}
+
+// GetCollection возвращает коллекцию, если пользователь в ней участвует.
+func (r *repository) GetCollection(ctx context.Context, collectionID, userID int64) (Collection, error) {
 	query, args, err := psql.
-		Select("id", "name", "pinned", "created_at", "updated_at").
+		Select(collectionColumns...).
+		Column(memberRole, userID).
+		Column(gameCount).
 		From(collectionTableName).
-		Where(squirrel.Eq{"id": collectionID, "user_id": userID}).
+		Where(squirrel.Eq{"id": collectionID}).
+		Where(memberOf, userID).
 		ToSql()
 	if err != nil {
 		return Collection{}, err
@@ -112,8 +353,48 @@ func (r *repository) getCollection(ctx context.Context, collectionID, userID int
 		return Collection{}, err
 	}
 
-	c.UserID = userID
-	c.GameIDs, err = r.getCollectionGameIDs(ctx, c.ID)
+	return c, nil
+}
+
+// CreateCollection создаёт коллекцию и делает создателя её владельцем.
+func (r *repository) CreateCollection(ctx context.Context, userID int64, req Collection) (Collection, error) {
+	var c = Collection{
+		UserID:     userID,
+		Name:       req.Name,
+		Type:       TypeCustom,
+		Pinned:     req.Pinned,
+		Visibility: req.Visibility,
+		ShareSlug:  req.ShareSlug,
+		Role:       RoleOwner,
+	}
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		query, args, err := psql.
+			Insert(collectionTableName).
+			Columns("user_id", "name", "pinned", "visibility", "share_slug").
+			Values(userID, req.Name, req.Pinned, req.Visibility, req.ShareSlug).
+			Suffix("RETURNING id, created_at, updated_at").
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		if err = tx.QueryRow(ctx, query, args...).Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return err
+		}
+
+		query, args, err = psql.
+			Insert(collectionMemberTableName).
+			Columns("collection_id", "user_id", "role", "accepted_at").
+			Values(c.ID, userID, RoleOwner, squirrel.Expr("NOW()")).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, query, args...)
+		return err
+	})
 	if err != nil {
 		return Collection{}, err
 	}
@@ -121,163 +402,674 @@ func (r *repository) getCollection(ctx context.Context, collectionID, userID int
 	return c, nil
 }
 
-func (r *repository) createCollection(ctx context.Context, userID int64, req Collection) (Collection, error) {
-	tx, err := r.db.Begin(ctx)
-	if err != nil {
+// UpdateCollection меняет название и закрепление, а также видимость, если
+// она задана. req.ShareSlug сохраняется, только если ссылки ещё нет.
+// Менять коллекцию могут только владельцы.
+func (r *repository) UpdateCollection(ctx context.Context, collectionID, userID int64, req Collection) (Collection, error) {
+	builder := psql.
+		Update(collectionTableName).
+		Set("name", req.Name).
+		Set("pinned", req.Pinned).
+		Set("updated_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{"id": collectionID})
+	if req.Visibility != "" {
+		builder = builder.Set("visibility", req.Visibility)
+	}
+	if req.ShareSlug != nil {
+		builder = builder.Set("share_slug", squirrel.Expr("COALESCE(share_slug, ?)", *req.ShareSlug))
+	}
+
+	if err := r.updateAsOwner(ctx, collectionID, userID, builder); err != nil {
 		return Collection{}, err
 	}
-	defer func() {
-		if r := recover(); r != nil {
-			_ = tx.Rollback(ctx)
-			panic(r)
+
+	// Получаем обновленную коллекцию
+	return r.GetCollection(ctx, collectionID, userID)
+}
+
+// SetShareSlug заменяет адрес ссылки на коллекцию; старая ссылка перестаёт работать.
+func (r *repository) SetShareSlug(ctx context.Context, collectionID, userID int64, slug string) (Collection, error) {
+	builder := psql.
+		Update(collectionTableName).
+		Set("share_slug", slug).
+		Where(squirrel.Eq{"id": collectionID})
+
+	if err := r.updateAsOwner(ctx, collectionID, userID, builder); err != nil {
+		return Collection{}, err
+	}
+
+	return r.GetCollection(ctx, collectionID, userID)
+}
+
+// updateAsOwner выполняет изменение коллекции, если пользователь — её владелец.
+func (r *repository) updateAsOwner(ctx context.Context, collectionID, userID int64, builder squirrel.UpdateBuilder) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := requireRole(ctx, tx, collectionID, userID, RoleOwner); err != nil {
+			return err
+		}
+
+		query, args, err := builder.ToSql()
+		if err != nil {
+			return err
 		}
-	}()
 
+		_, err = tx.Exec(ctx, query, args...)
+		return err
+	})
+}
+
+// GetSharedCollection находит открытую для других коллекцию по адресу ссылки
+// и возвращает её вместе с ID игр по порядку коллекции.
+func (r *repository) GetSharedCollection(ctx context.Context, slug string) (SharedCollection, []int64, error) {
 	query, args, err := psql.
-		Insert(collectionTableName).
-		Columns("user_id", "name", "pinned").
-		Values(userID, req.Name, req.Pinned).
-		Suffix("RETURNING id").
+		Select("c.id", "c.share_slug", "c.name", "u.username AS owner", "c.visibility", "c.created_at", "c.updated_at").
+		From(collectionTableName + " c").
+		Join(userTableName + " u ON u.id = c.user_id").
+		Where(squirrel.Eq{"c.share_slug": slug}).
+		Where(squirrel.NotEq{"c.visibility": VisibilityPrivate}).
 		ToSql()
 	if err != nil {
-		return Collection{}, err
+		return SharedCollection{}, nil, err
 	}
 
-	var c = Collection{
-		UserID: userID,
-		Name:   req.Name,
-		Pinned: req.Pinned,
+	var c SharedCollection
+	if err = pgxscan.Get(ctx, r.db, &c, query, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return SharedCollection{}, nil, ErrCollectionNotFound
+		}
+		return SharedCollection{}, nil, err
 	}
-	err = tx.QueryRow(ctx, query, args...).Scan(&c.ID)
+
+	gameIDs, err := r.getCollectionGameIDs(ctx, c.ID)
 	if err != nil {
-		return Collection{}, err
+		return SharedCollection{}, nil, err
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return Collection{}, err
+	return c, gameIDs, nil
+}
+
+// DeleteCollection удаляет коллекцию вместе с участниками; удалить её может
+// только владелец. Системные коллекции не удаляются.
+func (r *repository) DeleteCollection(ctx context.Context, collectionID, userID int64) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		a, err := requireRole(ctx, tx, collectionID, userID, RoleOwner)
+		if err != nil {
+			return err
+		}
+		if a.kind.System() {
+			return ErrSystemCollection
+		}
+
+		query, args, err := psql.
+			Delete(collectionTableName).
+			Where(squirrel.Eq{"id": collectionID}).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, query, args...)
+		return err
+	})
+}
+
+func (r *repository) AddGameToCollection(ctx context.Context, collectionID, gameID, userID int64) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := requireRole(ctx, tx, collectionID, userID, RoleEditor); err != nil {
+			return err
+		}
+
+		// Добавляем игру в конец коллекции. Строка коллекции заблокирована
+		// в requireRole, поэтому позиции не повторятся.
+		query, args, err := psql.
+			Insert(collectionGameTableName).
+			Columns("collection_id", "game_id", "position").
+			Values(collectionID, gameID, squirrel.Expr(
+				"(SELECT COALESCE(MAX(position), 0) + 1 FROM "+collectionGameTableName+" WHERE collection_id = ?)",
+				collectionID,
+			)).
+			Suffix("ON CONFLICT DO NOTHING").
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, query, args...)
+		return err
+	})
+}
+
+func (r *repository) RemoveGameFromCollection(ctx context.Context, collectionID, gameID, userID int64) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := requireRole(ctx, tx, collectionID, userID, RoleEditor); err != nil {
+			return err
+		}
+
+		// Удаляем игру из коллекции
+		query, args, err := psql.
+			Delete(collectionGameTableName).
+			Where(squirrel.Eq{"collection_id": collectionID, "game_id": gameID}).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, query, args...)
+		return err
+	})
+}
+
+// MoveGame переносит игру со всеми данными экземпляра из одной коллекции в
+// конец другой в одной транзакции. Нужны права редактора в обеих коллекциях.
+// Если игра уже есть в целевой коллекции, перенос отклоняется с
+// ErrAlreadyInTarget и обе записи остаются как были. Приоритет сохраняется,
+// только если игра переносится в список желаний.
+func (r *repository) MoveGame(ctx context.Context, fromID, toID, gameID, userID int64) error {
+	if fromID == toID {
+		return ErrSameCollection
 	}
 
-	return c, nil
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		// Коллекции блокируются по возрастанию ID, чтобы встречные переносы
+		// не взаимоблокировались
+		var target access
+		for _, id := range []int64{min(fromID, toID), max(fromID, toID)} {
+			a, err := requireRole(ctx, tx, id, userID, RoleEditor)
+			if err != nil {
+				return err
+			}
+			if id == toID {
+				target = a
+			}
+		}
+
+		query, args, err := psql.
+			Delete(collectionGameTableName).
+			Where(squirrel.Eq{"collection_id": fromID, "game_id": gameID}).
+			Suffix("RETURNING " + strings.Join(entryColumns, ", ")).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		var e Entry
+		if err = pgxscan.Get(ctx, tx, &e, query, args...); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrEntryNotFound
+			}
+			return err
+		}
+		if target.kind != TypeWishlist {
+			e.Priority = nil
+		}
+
+		query, args, err = psql.
+			Insert(collectionGameTableName).
+			Columns(
+				"collection_id", "game_id", "position", "note", "condition", "purchase_date", "price",
+				"lent_out", "lent_to", "expansions", "priority",
+			).
+			Values(
+				toID, gameID, squirrel.Expr(
+					"(SELECT COALESCE(MAX(position), 0) + 1 FROM "+collectionGameTableName+" WHERE collection_id = ?)",
+					toID,
+				),
+				e.Note, e.Condition, e.PurchaseDate, e.Price, e.LentOut, e.LentTo, e.Expansions, e.Priority,
+			).
+			Suffix("ON CONFLICT DO NOTHING").
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		tag, err := tx.Exec(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		// Удаление из исходной коллекции откатится вместе с транзакцией
+		if tag.RowsAffected() == 0 {
+			return ErrAlreadyInTarget
+		}
+		return nil
+	})
+}
+
+// entryColumns — поля записи коллекции без данных игры.
+var entryColumns = []string{
+	"game_id", "position", "note", "condition", "purchase_date", "price",
+	"lent_out", "lent_to", "expansions", "priority", "added_at",
 }
 
-func (r *repository) updateCollection(ctx context.Context, collectionID, userID int64, req Collection) (Collection, error) {
+// ListEntries возвращает записи коллекции по порядку.
+func (r *repository) ListEntries(ctx context.Context, collectionID int64) ([]Entry, error) {
 	query, args, err := psql.
-		Update(collectionTableName).
-		Set("name", req.Name).
-		Set("pinned", req.Pinned).
-		Set("updated_at", squirrel.Expr("NOW()")).
-		Where(squirrel.Eq{"id": collectionID, "user_id": userID}).
-		Suffix("RETURNING updated_at").
+		Select(entryColumns...).
+		From(collectionGameTableName).
+		Where(squirrel.Eq{"collection_id": collectionID}).
+		OrderBy("position ASC", "game_id ASC").
 		ToSql()
 	if err != nil {
-		return Collection{}, err
+		return nil, err
 	}
 
-	var updatedAt time.Time
-	err = r.db.QueryRow(ctx, query, args...).Scan(&updatedAt)
+	var entries []Entry
+	if err = pgxscan.Select(ctx, r.db, &entries, query, args...); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// UpdateEntry заменяет данные экземпляра игры в коллекции. Позиция и дата
+// добавления не меняются; приоритет бывает только в списке желаний.
+func (r *repository) UpdateEntry(ctx context.Context, collectionID, userID int64, entry Entry) (Entry, error) {
+	var e Entry
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		a, err := requireRole(ctx, tx, collectionID, userID, RoleEditor)
+		if err != nil {
+			return err
+		}
+		if entry.Priority != nil && a.kind != TypeWishlist {
+			return fmt.Errorf("%w: priority is only for the wishlist", ErrInvalidEntry)
+		}
+
+		query, args, err := psql.
+			Update(collectionGameTableName).
+			Set("note", entry.Note).
+			Set("condition", entry.Condition).
+			Set("purchase_date", entry.PurchaseDate).
+			Set("price", entry.Price).
+			Set("lent_out", entry.LentOut).
+			Set("lent_to", entry.LentTo).
+			Set("expansions", entry.Expansions).
+			Set("priority", entry.Priority).
+			Where(squirrel.Eq{"collection_id": collectionID, "game_id": entry.GameID}).
+			Suffix("RETURNING " + strings.Join(entryColumns, ", ")).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		if err = pgxscan.Get(ctx, tx, &e, query, args...); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrEntryNotFound
+			}
+			return err
+		}
+		return nil
+	})
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return Collection{}, ErrCollectionNotFound
+		return Entry{}, err
+	}
+
+	return e, nil
+}
+
+// ReorderEntries расставляет записи коллекции в порядке gameIDs. Список должен
+// содержать каждую игру коллекции ровно один раз.
+func (r *repository) ReorderEntries(ctx context.Context, collectionID, userID int64, gameIDs []int64) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := requireRole(ctx, tx, collectionID, userID, RoleEditor); err != nil {
+			return err
 		}
-		return Collection{}, err
+
+		query, args, err := psql.
+			Select("game_id").
+			From(collectionGameTableName).
+			Where(squirrel.Eq{"collection_id": collectionID}).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		var current []int64
+		if err = pgxscan.Select(ctx, tx, &current, query, args...); err != nil {
+			return err
+		}
+		if !samePermutation(current, gameIDs) {
+			return ErrInvalidOrder
+		}
+		if len(gameIDs) == 0 {
+			return nil
+		}
+
+		query, args, err = psql.
+			Update(collectionGameTableName).
+			Set("position", squirrel.Expr("array_position(?::bigint[], game_id)", gameIDs)).
+			Where(squirrel.Eq{"collection_id": collectionID}).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, query, args...)
+		return err
+	})
+}
+
+// samePermutation сообщает, что order — перестановка current без повторов.
+func samePermutation(current, order []int64) bool {
+	if len(current) != len(order) {
+		return false
 	}
 
-	// Получаем обновленную коллекцию
-	return r.getCollection(ctx, collectionID, userID)
+	left := make(map[int64]bool, len(current))
+	for _, id := range current {
+		left[id] = true
+	}
+	for _, id := range order {
+		if !left[id] {
+			return false
+		}
+		delete(left, id)
+	}
+	return true
+}
+
+// access — права пользователя в коллекции.
+type access struct {
+	// creatorID — создатель коллекции (collection.user_id)
+	creatorID int64
+	// role пуста, если пользователь не участник
+	role Role
+	kind Type
 }
 
-func (r *repository) deleteCollection(ctx context.Context, collectionID, userID int64) error {
+// requireRole проверяет, что у пользователя в коллекции есть права required, и
+// блокирует строку коллекции до конца транзакции. Для несуществующей
+// коллекции возвращает ErrCollectionNotFound, для недостаточных прав — ErrForbidden.
+func requireRole(ctx context.Context, tx pgx.Tx, collectionID, userID int64, required Role) (access, error) {
 	query, args, err := psql.
-		Delete(collectionTableName).
-		Where(squirrel.Eq{"id": collectionID, "user_id": userID}).
+		Select("c.user_id", "COALESCE(m.role, '')", "c.type").
+		From(collectionTableName+" c").
+		LeftJoin(collectionMemberTableName+" m ON m.collection_id = c.id AND m.user_id = ? AND m.accepted_at IS NOT NULL", userID).
+		Where(squirrel.Eq{"c.id": collectionID}).
+		Suffix("FOR UPDATE OF c").
 		ToSql()
+	if err != nil {
+		return access{}, err
+	}
+
+	var a access
+	err = tx.QueryRow(ctx, query, args...).Scan(&a.creatorID, &a.role, &a.kind)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return access{}, ErrCollectionNotFound
+		}
+		return access{}, err
+	}
+
+	if !a.role.AtLeast(required) {
+		return access{}, ErrForbidden
+	}
+
+	return a, nil
+}
+
+// EachExportRow вызывает fn для каждой игры в коллекциях пользователя: во всех
+// или только в collectionID, если он не ноль. Строки читаются из курсора по одной.
+func (r *repository) EachExportRow(ctx context.Context, userID, collectionID int64, fn func(ExportRow) error) error {
+	columns := []string{
+		"c.id AS collection_id",
+		"c.name AS collection_name",
+		"cg.added_at",
+	}
+	for _, col := range exportGameColumns {
+		columns = append(columns, fmt.Sprintf(`g.%s AS "game.%s"`, col, col))
+	}
+
+	builder := psql.
+		Select(columns...).
+		From(collectionTableName+" c").
+		Join(collectionGameTableName+" cg ON cg.collection_id = c.id").
+		Join(gameTableName+" g ON g.id = cg.game_id").
+		Where("c."+memberOf, userID).
+		OrderBy("c.pinned DESC", "c.name ASC", "c.id ASC", "cg.position ASC", "g.id ASC")
+	if collectionID != 0 {
+		builder = builder.Where(squirrel.Eq{"c.id": collectionID})
+	}
+
+	query, args, err := builder.ToSql()
 	if err != nil {
 		return err
 	}
 
-	result, err := r.db.Exec(ctx, query, args...)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return err
 	}
+	defer rows.Close()
 
-	if result.RowsAffected() == 0 {
-		return ErrCollectionNotFound
+	scanner := pgxscan.NewRowScanner(rows)
+	for rows.Next() {
+		var row ExportRow
+		if err = scanner.Scan(&row); err != nil {
+			return err
+		}
+		if err = fn(row); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	return rows.Err()
 }
 
-func (r *repository) addGameToCollection(ctx context.Context, collectionID, gameID, userID int64) error {
-	return pgx.BeginTxFunc(ctx, r.db, pgx.TxOptions{}, func(tx pgx.Tx) error {
-		// Проверяем, что коллекция принадлежит пользователю
-		query, args, err := psql.
-			Select("1").
-			From(collectionTableName).
-			Where(squirrel.Eq{"id": collectionID, "user_id": userID}).
-			ToSql()
-		if err != nil {
+// ListMembers возвращает участников коллекции и приглашённых: сначала
+// принявших приглашение, затем в порядке приглашения.
+func (r *repository) ListMembers(ctx context.Context, collectionID int64) ([]Member, error) {
+	query, args, err := psql.
+		Select("m.user_id", "u.username", "m.role", "m.invited_at", "m.accepted_at").
+		From(collectionMemberTableName+" m").
+		Join(userTableName+" u ON u.id = m.user_id").
+		Where(squirrel.Eq{"m.collection_id": collectionID}).
+		OrderBy("m.accepted_at IS NULL", "m.invited_at ASC", "m.user_id ASC").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var members []Member
+	if err = pgxscan.Select(ctx, r.db, &members, query, args...); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// InviteMember приглашает пользователя в коллекцию. Приглашать могут только
+// владельцы; участником приглашённый станет, когда примет приглашение.
+func (r *repository) InviteMember(ctx context.Context, collectionID, inviterID int64, invitee Invitee, role Role) (Member, error) {
+	var m Member
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := requireRole(ctx, tx, collectionID, inviterID, RoleOwner); err != nil {
 			return err
 		}
 
-		var exists int
-		err = r.db.QueryRow(ctx, query, args...).Scan(&exists)
+		builder := psql.Select("id", "username").From(userTableName)
+		if invitee.Username != "" {
+			builder = builder.Where(squirrel.Eq{"username": invitee.Username})
+		} else {
+			builder = builder.Where("lower(email) = lower(?)", invitee.Email)
+		}
+		query, args, err := builder.ToSql()
 		if err != nil {
+			return err
+		}
+
+		if err = tx.QueryRow(ctx, query, args...).Scan(&m.UserID, &m.Username); err != nil {
 			if errors.Is(err, pgx.ErrNoRows) {
-				return ErrForbidden
+				return ErrUserNotFound
 			}
 			return err
 		}
 
-		// Добавляем игру в коллекцию
 		query, args, err = psql.
-			Insert(collectionTableName).
-			Columns("collection_id", "game_id").
-			Values(collectionID, gameID).
-			Suffix("ON CONFLICT DO NOTHING").
+			Insert(collectionMemberTableName).
+			Columns("collection_id", "user_id", "role", "invited_by").
+			Values(collectionID, m.UserID, role, inviterID).
+			Suffix("ON CONFLICT DO NOTHING RETURNING invited_at").
 			ToSql()
 		if err != nil {
 			return err
 		}
 
-		_, err = r.db.Exec(ctx, query, args...)
-		return err
+		if err = tx.QueryRow(ctx, query, args...).Scan(&m.InvitedAt); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrAlreadyMember
+			}
+			return err
+		}
+
+		m.Role = role
+		return nil
 	})
+	if err != nil {
+		return Member{}, err
+	}
+
+	return m, nil
 }
 
-func (r *repository) removeGameFromCollection(ctx context.Context, collectionID, gameID, userID int64) error {
-	return pgx.BeginTxFunc(ctx, r.db, pgx.TxOptions{}, func(tx pgx.Tx) error {
-		// Проверяем, что коллекция принадлежит пользователю
+// UpdateMemberRole меняет роль участника или приглашённого. Менять роли могут
+// только владельцы; роль создателя коллекции не меняется.
+func (r *repository) UpdateMemberRole(ctx context.Context, collectionID, actorID, memberID int64, role Role) (Member, error) {
+	var m Member
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		a, err := requireRole(ctx, tx, collectionID, actorID, RoleOwner)
+		if err != nil {
+			return err
+		}
+		if memberID == a.creatorID {
+			return ErrCreator
+		}
+
 		query, args, err := psql.
-			Select("1").
-			From(collectionTableName).
-			Where(squirrel.Eq{"id": collectionID, "user_id": userID}).
+			Update(collectionMemberTableName).
+			Set("role", role).
+			Where(squirrel.Eq{"collection_id": collectionID, "user_id": memberID}).
+			Suffix(`RETURNING user_id, (SELECT u.username FROM users u WHERE u.id = collection_member.user_id) AS username,
+				role, invited_at, accepted_at`).
 			ToSql()
 		if err != nil {
 			return err
 		}
 
-		var exists int
-		err = tx.QueryRow(ctx, query, args...).Scan(&exists)
-		if err != nil {
+		if err = pgxscan.Get(ctx, tx, &m, query, args...); err != nil {
 			if errors.Is(err, pgx.ErrNoRows) {
-				return ErrForbidden
+				return ErrMemberNotFound
 			}
 			return err
 		}
+		return nil
+	})
+	if err != nil {
+		return Member{}, err
+	}
 
-		// Удаляем игру из коллекции
-		query, args, err = psql.
-			Delete(collectionTableName).
-			Where(squirrel.Eq{"collection_id": collectionID, "game_id": gameID}).
+	return m, nil
+}
+
+// RemoveMember исключает участника или отзывает приглашение. Владельцы могут
+// исключить любого, кроме создателя; остальные — только выйти сами.
+func (r *repository) RemoveMember(ctx context.Context, collectionID, actorID, memberID int64) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		required := RoleOwner
+		if actorID == memberID {
+			required = RoleViewer
+		}
+
+		a, err := requireRole(ctx, tx, collectionID, actorID, required)
+		if err != nil {
+			return err
+		}
+		if memberID == a.creatorID {
+			return ErrCreator
+		}
+
+		query, args, err := psql.
+			Delete(collectionMemberTableName).
+			Where(squirrel.Eq{"collection_id": collectionID, "user_id": memberID}).
 			ToSql()
 		if err != nil {
 			return err
 		}
 
-		_, err = tx.Exec(ctx, query, args...)
+		result, err := tx.Exec(ctx, query, args...)
 		if err != nil {
 			return err
 		}
-		return tx.Commit(ctx)
+		if result.RowsAffected() == 0 {
+			return ErrMemberNotFound
+		}
+		return nil
 	})
 }
+
+// ListInvites возвращает непринятые приглашения пользователя, новые первыми.
+func (r *repository) ListInvites(ctx context.Context, userID int64) ([]Invite, error) {
+	query, args, err := psql.
+		Select(
+			"c.id AS collection_id",
+			"c.name AS collection_name",
+			"m.role",
+			"COALESCE(u.username, '') AS invited_by",
+			"m.invited_at",
+		).
+		From(collectionMemberTableName+" m").
+		Join(collectionTableName+" c ON c.id = m.collection_id").
+		LeftJoin(userTableName+" u ON u.id = m.invited_by").
+		Where(squirrel.Eq{"m.user_id": userID, "m.accepted_at": nil}).
+		OrderBy("m.invited_at DESC", "c.id ASC").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var invites []Invite
+	if err = pgxscan.Select(ctx, r.db, &invites, query, args...); err != nil {
+		return nil, err
+	}
+
+	return invites, nil
+}
+
+func (r *repository) AcceptInvite(ctx context.Context, collectionID, userID int64) error {
+	query, args, err := psql.
+		Update(collectionMemberTableName).
+		Set("accepted_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{"collection_id": collectionID, "user_id": userID, "accepted_at": nil}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrInviteNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) DeclineInvite(ctx context.Context, collectionID, userID int64) error {
+	query, args, err := psql.
+		Delete(collectionMemberTableName).
+		Where(squirrel.Eq{"collection_id": collectionID, "user_id": userID, "accepted_at": nil}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrInviteNotFound
+	}
+
+	return nil
+}
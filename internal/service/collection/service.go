@@ -2,64 +2,429 @@ package collection
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"strings"
 
+	"github.com/board-box/backend/internal/db"
+	"github.com/board-box/backend/internal/pagination"
 	"github.com/board-box/backend/internal/service/game"
-	pgx "github.com/jackc/pgx/v5"
 )
 
 var (
 	ErrCollectionNotFound = errors.New("collection not found")
 	ErrForbidden          = errors.New("forbidden")
+	ErrInvalidVisibility  = errors.New("invalid visibility")
+	ErrInvalidRole        = errors.New("invalid member role")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrAlreadyMember      = errors.New("user is already a member or invited")
+	ErrMemberNotFound     = errors.New("member not found")
+	ErrInviteNotFound     = errors.New("invite not found")
+	ErrEntryNotFound      = errors.New("game is not in the collection")
+	ErrInvalidEntry       = errors.New("invalid collection entry")
+	// ErrInvalidOrder — новый порядок должен содержать каждую игру коллекции ровно один раз
+	ErrInvalidOrder = errors.New("order must list every game of the collection once")
+	// ErrCreator — создателя коллекции нельзя исключить или лишить роли владельца
+	ErrCreator = errors.New("collection creator cannot be removed or demoted")
+	// ErrSystemCollection — системные коллекции создаются сами и не удаляются
+	ErrSystemCollection = errors.New("system collection cannot be deleted")
+	ErrInvalidType      = errors.New("invalid collection type")
+	ErrSameCollection   = errors.New("cannot move a game to the same collection")
+	ErrAlreadyInTarget  = errors.New("game is already in the target collection")
 )
 
+// slugBytes — длина адреса ссылки в случайных байтах: 128 бит не подобрать перебором.
+const slugBytes = 16
+
+// GameGetter — то, что коллекциям нужно от каталога игр.
+type GameGetter interface {
+	GetGame(ctx context.Context, id int64) (game.Game, error)
+	GetGames(ctx context.Context, ids []int64) ([]game.Game, error)
+}
+
 type Service struct {
-	repo    *repository
-	gameSvc *game.Service
+	repo    Repository
+	tx      db.TxManager
+	gameSvc GameGetter
 }
 
-func NewService(db *pgx.Conn, gameSvc *game.Service) *Service {
+func NewService(repo Repository, tx db.TxManager, gameSvc GameGetter) *Service {
 	return &Service{
-		repo:    newRepository(db),
+		repo:    repo,
+		tx:      tx,
 		gameSvc: gameSvc,
 	}
 }
 
-func (s *Service) ListCollections(ctx context.Context, userID int64) ([]Collection, error) {
-	return s.repo.listCollections(ctx, userID)
+// ListCollections возвращает коллекции пользователя, при необходимости только
+// одного вида.
+func (s *Service) ListCollections(ctx context.Context, userID int64, filter ListFilter, page pagination.Params) ([]Collection, pagination.Meta, error) {
+	if err := page.Validate(); err != nil {
+		return nil, pagination.Meta{}, err
+	}
+	if filter.Type != "" && !filter.Type.Valid() {
+		return nil, pagination.Meta{}, ErrInvalidType
+	}
+	return s.repo.ListCollections(ctx, userID, filter, page)
+}
+
+// CreateSystemCollections создаёт системные коллекции нового пользователя.
+// Вызывается при регистрации в той же транзакции, что и создание пользователя.
+func (s *Service) CreateSystemCollections(ctx context.Context, userID int64) error {
+	return s.repo.CreateSystemCollections(ctx, userID)
 }
 
+// GetCollection возвращает коллекцию с записями и данными игр по порядку.
 func (s *Service) GetCollection(ctx context.Context, collectionID, userID int64) (Collection, error) {
-	return s.repo.getCollection(ctx, collectionID, userID)
+	c, err := s.repo.GetCollection(ctx, collectionID, userID)
+	if err != nil {
+		return Collection{}, err
+	}
+	return s.withEntries(ctx, c)
 }
 
+// withEntries заполняет записи коллекции вместе с играми. Записи об играх,
+// которых уже нет в каталоге, пропускаются.
+func (s *Service) withEntries(ctx context.Context, c Collection) (Collection, error) {
+	entries, err := s.repo.ListEntries(ctx, c.ID)
+	if err != nil {
+		return Collection{}, err
+	}
+	if len(entries) == 0 {
+		return c, nil
+	}
+
+	ids := make([]int64, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.GameID)
+	}
+	games, err := s.gameSvc.GetGames(ctx, ids)
+	if err != nil && !errors.Is(err, game.ErrGameNotFound) {
+		return Collection{}, err
+	}
+
+	byID := make(map[int64]game.Game, len(games))
+	for _, g := range games {
+		byID[g.ID] = g
+	}
+	c.Entries = make([]Entry, 0, len(entries))
+	for _, e := range entries {
+		if g, ok := byID[e.GameID]; ok {
+			e.Game = g
+			c.Entries = append(c.Entries, e)
+		}
+	}
+
+	return c, nil
+}
+
+func (s *Service) ListCollectionGameIDs(ctx context.Context, collectionID, userID int64, page pagination.Params) ([]int64, pagination.Meta, error) {
+	if err := page.Validate(); err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	// Проверяем, что пользователь участвует в коллекции
+	if _, err := s.repo.GetCollection(ctx, collectionID, userID); err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	return s.repo.ListCollectionGameIDs(ctx, collectionID, page)
+}
+
+// CreateCollection создаёт коллекцию; без видимости она личная. Открытой для
+// других коллекции сразу выдаётся ссылка.
 func (s *Service) CreateCollection(ctx context.Context, userID int64, req Collection) (Collection, error) {
 	if req.Name == "" {
 		return Collection{}, errors.New("collection name cannot be empty")
 	}
-	return s.repo.createCollection(ctx, userID, req)
+	if req.Visibility == "" {
+		req.Visibility = VisibilityPrivate
+	}
+	if err := s.prepareVisibility(&req); err != nil {
+		return Collection{}, err
+	}
+	return s.repo.CreateCollection(ctx, userID, req)
 }
 
+// UpdateCollection обновляет коллекцию. Пустая видимость не меняется; при
+// первом открытии коллекции для других ей выдаётся ссылка.
 func (s *Service) UpdateCollection(ctx context.Context, collectionID, userID int64, req Collection) (Collection, error) {
 	if req.Name == "" {
 		return Collection{}, errors.New("collection name cannot be empty")
 	}
-	return s.repo.updateCollection(ctx, collectionID, userID, req)
+	if req.Visibility != "" {
+		if err := s.prepareVisibility(&req); err != nil {
+			return Collection{}, err
+		}
+	}
+	c, err := s.repo.UpdateCollection(ctx, collectionID, userID, req)
+	if err != nil {
+		return Collection{}, err
+	}
+	return s.withEntries(ctx, c)
+}
+
+// prepareVisibility проверяет видимость и готовит адрес ссылки для открытой
+// коллекции. Репозиторий не заменяет уже выданный адрес.
+func (s *Service) prepareVisibility(req *Collection) error {
+	if !req.Visibility.Valid() {
+		return ErrInvalidVisibility
+	}
+
+	req.ShareSlug = nil
+	if req.Visibility != VisibilityPrivate {
+		slug, err := newSlug()
+		if err != nil {
+			return err
+		}
+		req.ShareSlug = &slug
+	}
+	return nil
+}
+
+// RotateShareSlug выдаёт коллекции новую ссылку. Старая перестаёт работать
+// сразу, поэтому так можно закрыть доступ тем, кому ссылка попала случайно.
+func (s *Service) RotateShareSlug(ctx context.Context, collectionID, userID int64) (Collection, error) {
+	slug, err := newSlug()
+	if err != nil {
+		return Collection{}, err
+	}
+	c, err := s.repo.SetShareSlug(ctx, collectionID, userID, slug)
+	if err != nil {
+		return Collection{}, err
+	}
+	return s.withEntries(ctx, c)
+}
+
+// GetSharedCollection возвращает коллекцию по ссылке вместе с играми. Личные
+// коллекции по ссылке не открываются.
+func (s *Service) GetSharedCollection(ctx context.Context, slug string) (SharedCollection, error) {
+	c, gameIDs, err := s.repo.GetSharedCollection(ctx, slug)
+	if err != nil {
+		return SharedCollection{}, err
+	}
+
+	c.Games = []game.Game{}
+	if len(gameIDs) == 0 {
+		return c, nil
+	}
+
+	games, err := s.gameSvc.GetGames(ctx, gameIDs)
+	if err != nil {
+		return SharedCollection{}, err
+	}
+
+	// GetGames не сохраняет порядок, возвращаем игры в порядке коллекции
+	byID := make(map[int64]game.Game, len(games))
+	for _, g := range games {
+		byID[g.ID] = g
+	}
+	for _, id := range gameIDs {
+		if g, ok := byID[id]; ok {
+			c.Games = append(c.Games, g)
+		}
+	}
+
+	return c, nil
+}
+
+// ListPublicCollections возвращает публичные коллекции пользователя.
+func (s *Service) ListPublicCollections(ctx context.Context, userID int64, page pagination.Params) ([]Collection, pagination.Meta, error) {
+	if err := page.Validate(); err != nil {
+		return nil, pagination.Meta{}, err
+	}
+	return s.repo.ListPublicCollections(ctx, userID, page)
+}
+
+func newSlug() (string, error) {
+	b := make([]byte, slugBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
 
 func (s *Service) DeleteCollection(ctx context.Context, collectionID, userID int64) error {
-	return s.repo.deleteCollection(ctx, collectionID, userID)
+	return s.repo.DeleteCollection(ctx, collectionID, userID)
 }
 
+// AddGameToCollection проверяет игру и добавляет её в коллекцию в одной
+// транзакции.
 func (s *Service) AddGameToCollection(ctx context.Context, collectionID, gameID, userID int64) error {
-	_, err := s.gameSvc.GetGame(ctx, gameID)
+	return s.tx.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := s.gameSvc.GetGame(ctx, gameID); err != nil {
+			return err
+		}
+		return s.repo.AddGameToCollection(ctx, collectionID, gameID, userID)
+	})
+}
+
+// UpdateEntry заменяет данные экземпляра игры в коллекции и возвращает
+// запись вместе с игрой. Менять записи могут редакторы и владельцы.
+func (s *Service) UpdateEntry(ctx context.Context, collectionID, userID int64, entry Entry) (Entry, error) {
+	if err := normalizeEntry(&entry); err != nil {
+		return Entry{}, err
+	}
+
+	e, err := s.repo.UpdateEntry(ctx, collectionID, userID, entry)
 	if err != nil {
-		return err
+		return Entry{}, err
 	}
 
-	return s.repo.addGameToCollection(ctx, collectionID, gameID, userID)
+	e.Game, err = s.gameSvc.GetGame(ctx, e.GameID)
+	if err != nil {
+		return Entry{}, err
+	}
+	return e, nil
+}
+
+// normalizeEntry проверяет запись и приводит пустые значения к nil: пустая
+// заметка — то же, что её отсутствие.
+func normalizeEntry(e *Entry) error {
+	if e.Condition != nil && !e.Condition.Valid() {
+		return fmt.Errorf("%w: unknown condition %q", ErrInvalidEntry, *e.Condition)
+	}
+	if e.Price != nil && *e.Price < 0 {
+		return fmt.Errorf("%w: negative price", ErrInvalidEntry)
+	}
+	if e.Priority != nil && (*e.Priority < MinPriority || *e.Priority > MaxPriority) {
+		return fmt.Errorf("%w: priority must be between %d and %d", ErrInvalidEntry, MinPriority, MaxPriority)
+	}
+
+	e.Note = trimmedOrNil(e.Note)
+	e.LentTo = trimmedOrNil(e.LentTo)
+	if e.LentTo != nil {
+		e.LentOut = true
+	}
+	if !e.LentOut {
+		e.LentTo = nil
+	}
+
+	expansions := make([]string, 0, len(e.Expansions))
+	for _, name := range e.Expansions {
+		if name = strings.TrimSpace(name); name != "" {
+			expansions = append(expansions, name)
+		}
+	}
+	e.Expansions = expansions
+	return nil
+}
+
+func trimmedOrNil(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	if v := strings.TrimSpace(*s); v != "" {
+		return &v
+	}
+	return nil
+}
+
+// ReorderEntries задаёт порядок игр в коллекции: gameIDs должен содержать
+// каждую игру коллекции ровно один раз. Возвращает коллекцию в новом порядке.
+func (s *Service) ReorderEntries(ctx context.Context, collectionID, userID int64, gameIDs []int64) (Collection, error) {
+	if err := s.repo.ReorderEntries(ctx, collectionID, userID, gameIDs); err != nil {
+		return Collection{}, err
+	}
+	return s.GetCollection(ctx, collectionID, userID)
 }
 
 func (s *Service) RemoveGameFromCollection(ctx context.Context, collectionID, gameID, userID int64) error {
-	return s.repo.removeGameFromCollection(ctx, collectionID, gameID, userID)
+	return s.repo.RemoveGameFromCollection(ctx, collectionID, gameID, userID)
+}
+
+// MoveGame переносит игру из коллекции fromID вместе с данными экземпляра,
+// например из списка желаний в свою коллекцию после покупки. Перенос
+// атомарный: игра не теряется и не остаётся в обеих коллекциях. Если игра
+// уже есть в целевой коллекции, ничего не меняется и возвращается
+// ErrAlreadyInTarget, чтобы данные экземпляра не потерялись. Возвращает
+// целевую коллекцию.
+func (s *Service) MoveGame(ctx context.Context, fromID, gameID, userID int64, to MoveTarget) (Collection, error) {
+	if (to.CollectionID == 0) == (to.Type == "") {
+		return Collection{}, fmt.Errorf("%w: set either a collection or a type to move to", ErrInvalidType)
+	}
+	if to.Type != "" && !to.Type.System() {
+		return Collection{}, ErrInvalidType
+	}
+
+	err := s.tx.WithTx(ctx, func(ctx context.Context) error {
+		if to.Type != "" {
+			id, err := s.repo.GetSystemCollectionID(ctx, userID, to.Type)
+			if err != nil {
+				return err
+			}
+			to.CollectionID = id
+		}
+		return s.repo.MoveGame(ctx, fromID, to.CollectionID, gameID, userID)
+	})
+	if err != nil {
+		return Collection{}, err
+	}
+
+	return s.GetCollection(ctx, to.CollectionID, userID)
+}
+
+// Export передаёт fn игры коллекции collectionID или, если он ноль, всех
+// коллекций пользователя — по одной строке на пару коллекция-игра.
+func (s *Service) Export(ctx context.Context, userID, collectionID int64, fn func(ExportRow) error) error {
+	if collectionID != 0 {
+		if _, err := s.repo.GetCollection(ctx, collectionID, userID); err != nil {
+			return err
+		}
+	}
+	return s.repo.EachExportRow(ctx, userID, collectionID, fn)
+}
+
+// ListMembers возвращает участников и приглашённых; смотреть их может любой
+// участник коллекции.
+func (s *Service) ListMembers(ctx context.Context, collectionID, userID int64) ([]Member, error) {
+	if _, err := s.repo.GetCollection(ctx, collectionID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListMembers(ctx, collectionID)
+}
+
+// InviteMember приглашает пользователя по имени или email. Пустая роль — viewer.
+func (s *Service) InviteMember(ctx context.Context, collectionID, userID int64, invitee Invitee, role Role) (Member, error) {
+	if role == "" {
+		role = RoleViewer
+	}
+	if !role.Valid() {
+		return Member{}, ErrInvalidRole
+	}
+	if invitee.Username == "" && invitee.Email == "" {
+		return Member{}, ErrUserNotFound
+	}
+	return s.repo.InviteMember(ctx, collectionID, userID, invitee, role)
+}
+
+func (s *Service) UpdateMemberRole(ctx context.Context, collectionID, userID, memberID int64, role Role) (Member, error) {
+	if !role.Valid() {
+		return Member{}, ErrInvalidRole
+	}
+	return s.repo.UpdateMemberRole(ctx, collectionID, userID, memberID, role)
+}
+
+// RemoveMember исключает участника; если memberID совпадает с userID,
+// пользователь выходит из коллекции сам.
+func (s *Service) RemoveMember(ctx context.Context, collectionID, userID, memberID int64) error {
+	return s.repo.RemoveMember(ctx, collectionID, userID, memberID)
+}
+
+func (s *Service) ListInvites(ctx context.Context, userID int64) ([]Invite, error) {
+	return s.repo.ListInvites(ctx, userID)
+}
+
+// AcceptInvite принимает приглашение и возвращает коллекцию, ставшую доступной.
+func (s *Service) AcceptInvite(ctx context.Context, collectionID, userID int64) (Collection, error) {
+	if err := s.repo.AcceptInvite(ctx, collectionID, userID); err != nil {
+		return Collection{}, err
+	}
+	return s.GetCollection(ctx, collectionID, userID)
+}
+
+func (s *Service) DeclineInvite(ctx context.Context, collectionID, userID int64) error {
+	return s.repo.DeclineInvite(ctx, collectionID, userID)
 }
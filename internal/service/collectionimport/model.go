@@ -0,0 +1,158 @@
+package collectionimport
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Format — формат файла с коллекцией.
+type Format string
+
+const (
+	// FormatCSV — таблица с заголовком: выгрузка коллекции BGG или выгрузка
+	// коллекций BoardBox в CSV.
+	FormatCSV Format = "csv"
+	// FormatJSON — выгрузка коллекций BoardBox в JSON (массив объектов или
+	// JSON Lines).
+	FormatJSON Format = "json"
+	// FormatBGG — выгрузка коллекции BoardGameGeek XML API2 (/xmlapi2/collection).
+	FormatBGG Format = "bgg"
+)
+
+// Formats — поддерживаемые форматы в порядке вывода в справке.
+var Formats = []Format{FormatCSV, FormatJSON, FormatBGG}
+
+// Статусы записи в отчёте.
+const (
+	// StatusMatched — игра найдена однозначно и будет добавлена в коллекцию.
+	StatusMatched = "matched"
+	// StatusAmbiguous — подходят несколько игр, нужно выбрать одну из Candidates.
+	StatusAmbiguous = "ambiguous"
+	// StatusUnmatched — подходящих игр в каталоге нет.
+	StatusUnmatched = "unmatched"
+	// StatusSkipped — запись не относится к коллекции, например игра на BGG
+	// отмечена не как имеющаяся, а как желаемая.
+	StatusSkipped = "skipped"
+)
+
+// Способы, которыми найдена игра.
+const (
+	MatchByID         = "id"
+	MatchByExternalID = "external_id"
+	MatchByTitle      = "title"
+	MatchByFuzzyTitle = "fuzzy_title"
+	MatchByChoice     = "choice"
+)
+
+var (
+	ErrUnknownFormat  = errors.New("unknown import format")
+	ErrInvalidFile    = errors.New("invalid import file")
+	ErrInvalidChoice  = errors.New("invalid choice")
+	ErrNothingMatched = errors.New("no games matched")
+)
+
+// Options управляет импортом.
+type Options struct {
+	Format Format
+	// Name — название новой коллекции. Если не задано, берётся из файла
+	// (колонка collection выгрузки BoardBox) или из имени файла.
+	Name string
+	// Filename — имя загруженного файла
+	Filename string
+	// Confirm создаёт коллекцию. Без него импорт только возвращает отчёт
+	Confirm bool
+	// Choices — игры, выбранные пользователем для записей: номер записи -> ID
+	// игры. Так разрешаются неоднозначные и ненайденные записи.
+	Choices map[int]int64
+}
+
+// Report — результат сопоставления записей файла с каталогом. Пока импорт не
+// подтверждён, CollectionID пустой и ничего не сохранено.
+type Report struct {
+	Format       Format  `json:"format"`
+	Name         string  `json:"name"`
+	Confirmed    bool    `json:"confirmed"`
+	CollectionID int64   `json:"collection_id,omitempty"`
+	Total        int     `json:"total"`
+	Matched      int     `json:"matched"`
+	Ambiguous    int     `json:"ambiguous"`
+	Unmatched    int     `json:"unmatched"`
+	Skipped      int     `json:"skipped"`
+	Entries      []Entry `json:"entries"`
+}
+
+// Entry — одна запись файла и найденная для неё игра.
+type Entry struct {
+	// Row — номер строки в CSV и JSON Lines, номер элемента в JSON-массиве или XML
+	Row        int    `json:"row"`
+	Title      string `json:"title,omitempty"`
+	ExternalID string `json:"external_id,omitempty"`
+	// SourceID — ID игры из выгрузки BoardBox
+	SourceID   int64     `json:"source_id,omitempty"`
+	Status     string    `json:"status"`
+	MatchedBy  string    `json:"matched_by,omitempty"`
+	Game       *GameRef  `json:"game,omitempty"`
+	Candidates []GameRef `json:"candidates,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+}
+
+// GameRef — игра каталога. Score — сходство названий при поиске по названию.
+type GameRef struct {
+	ID    int64   `json:"id"`
+	Title string  `json:"title"`
+	Score float64 `json:"score,omitempty"`
+}
+
+// ParseFormat разбирает название формата. Пустое значение определяется по
+// расширению файла.
+func ParseFormat(name, filename string) (Format, error) {
+	if name == "" {
+		switch strings.ToLower(filepath.Ext(filename)) {
+		case ".csv":
+			return FormatCSV, nil
+		case ".json", ".jsonl", ".ndjson":
+			return FormatJSON, nil
+		case ".xml":
+			return FormatBGG, nil
+		}
+		return "", ErrUnknownFormat
+	}
+
+	switch f := Format(strings.ToLower(name)); f {
+	case FormatCSV, FormatJSON, FormatBGG:
+		return f, nil
+	case "jsonl":
+		return FormatJSON, nil
+	case "xml":
+		return FormatBGG, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+// ParseChoices разбирает выбор игр вида "3=17,5=42": номер записи = ID игры.
+func ParseChoices(s string) (map[int]int64, error) {
+	choices := make(map[int]int64)
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		row, id, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidChoice, pair)
+		}
+		r, err := strconv.Atoi(strings.TrimSpace(row))
+		if err != nil || r <= 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidChoice, pair)
+		}
+		gameID, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64)
+		if err != nil || gameID <= 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidChoice, pair)
+		}
+		choices[r] = gameID
+	}
+	return choices, nil
+}
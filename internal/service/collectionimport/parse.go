@@ -0,0 +1,330 @@
+package collectionimport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxLineSize = 1 << 20
+
+	utf8BOM   = "\ufeff"
+	bggPrefix = "bgg:"
+)
+
+// Поля записи, с которыми сопоставляются колонки файла.
+const (
+	fieldTitle      = "title"
+	fieldSourceID   = "game_id"
+	fieldExternalID = "external_id"
+	fieldBGGID      = "bgg_id"
+	fieldCollection = "collection"
+	fieldOwn        = "own"
+)
+
+// columns сопоставляют названия колонок выгрузок BoardBox и BGG с полями
+// записи. Ключи нормализованы функцией normalizeColumn.
+var columns = map[string]string{
+	"title":           fieldTitle,
+	"name":            fieldTitle,
+	"objectname":      fieldTitle,
+	"game":            fieldTitle,
+	"game_id":         fieldSourceID,
+	"external_id":     fieldExternalID,
+	"objectid":        fieldBGGID,
+	"bgg_id":          fieldBGGID,
+	"bggid":           fieldBGGID,
+	"collection":      fieldCollection,
+	"collection_name": fieldCollection,
+	"own":             fieldOwn,
+}
+
+// record — одна запись файла.
+type record struct {
+	row        int
+	title      string
+	sourceID   int64
+	externalID string
+	collection string
+	// skip — причина, по которой запись не импортируется
+	skip string
+}
+
+func parse(r io.Reader, format Format) ([]record, error) {
+	switch format {
+	case FormatCSV:
+		return parseCSV(r)
+	case FormatJSON:
+		return parseJSON(r)
+	case FormatBGG:
+		return parseBGG(r)
+	}
+	return nil, ErrUnknownFormat
+}
+
+// newRecord собирает запись из значений полей.
+func newRecord(row int, values map[string]string) record {
+	rec := record{
+		row:        row,
+		title:      values[fieldTitle],
+		externalID: values[fieldExternalID],
+		collection: values[fieldCollection],
+	}
+
+	if s := values[fieldSourceID]; s != "" {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || id <= 0 {
+			rec.skip = fmt.Sprintf("%s: %q is not a game id", fieldSourceID, s)
+			return rec
+		}
+		rec.sourceID = id
+	}
+
+	if s := values[fieldBGGID]; s != "" && rec.externalID == "" {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || id <= 0 {
+			rec.skip = fmt.Sprintf("%s: %q is not a BGG id", fieldBGGID, s)
+			return rec
+		}
+		rec.externalID = bggPrefix + strconv.FormatInt(id, 10)
+	}
+
+	// В выгрузке BGG есть и игры из списка желаемого, и проданные
+	if own, ok := values[fieldOwn]; ok && own != "1" {
+		rec.skip = "not owned"
+		return rec
+	}
+
+	if rec.title == "" && rec.sourceID == 0 && rec.externalID == "" {
+		rec.skip = "no title or game id"
+	}
+
+	return rec
+}
+
+func parseCSV(r io.Reader) ([]record, error) {
+	br := bufio.NewReader(r)
+
+	cr := csv.NewReader(br)
+	cr.FieldsPerRecord = -1
+	cr.Comma = sniffDelimiter(br)
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: empty file", ErrInvalidFile)
+		}
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidFile, err)
+	}
+
+	fields := make([]string, len(header))
+	seen := make(map[string]bool, len(header))
+	for i, column := range header {
+		// Первая колонка поля важнее: в выгрузке BGG objectname идёт раньше originalname
+		if field := columns[normalizeColumn(column)]; field != "" && !seen[field] {
+			fields[i], seen[field] = field, true
+		}
+	}
+	if !seen[fieldTitle] && !seen[fieldSourceID] && !seen[fieldExternalID] && !seen[fieldBGGID] {
+		return nil, fmt.Errorf("%w: no title or game id column", ErrInvalidFile)
+	}
+
+	var records []record
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			records = append(records, record{row: parseErr.StartLine, skip: parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := cr.FieldPos(0)
+		values := make(map[string]string, len(row))
+		for i, v := range row {
+			if i < len(fields) && fields[i] != "" {
+				values[fields[i]] = strings.TrimSpace(v)
+			}
+		}
+		records = append(records, newRecord(line, values))
+	}
+
+	return records, nil
+}
+
+// sniffDelimiter выбирает ";" для таблиц, сохранённых из Excel с русской
+// локалью, и "," во всех остальных случаях.
+func sniffDelimiter(br *bufio.Reader) rune {
+	line, _ := br.Peek(br.Size())
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	if bytes.Count(line, []byte(";")) > bytes.Count(line, []byte(",")) {
+		return ';'
+	}
+	return ','
+}
+
+func parseJSON(r io.Reader) ([]record, error) {
+	br := bufio.NewReader(r)
+
+	first, err := firstByte(br)
+	if err != nil {
+		return nil, fmt.Errorf("%w: empty file", ErrInvalidFile)
+	}
+
+	var records []record
+	if first == '[' {
+		dec := json.NewDecoder(br)
+		if _, err = dec.Token(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+		for row := 1; dec.More(); row++ {
+			var raw json.RawMessage
+			if err = dec.Decode(&raw); err != nil {
+				return nil, fmt.Errorf("%w: element %d: %v", ErrInvalidFile, row, err)
+			}
+			records = append(records, jsonRecord(row, raw))
+		}
+		return records, nil
+	}
+
+	sc := bufio.NewScanner(br)
+	sc.Buffer(make([]byte, 64<<10), maxLineSize)
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		records = append(records, jsonRecord(line, sc.Bytes()))
+	}
+	if err = sc.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	return records, nil
+}
+
+func jsonRecord(row int, data []byte) record {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var obj map[string]any
+	if err := dec.Decode(&obj); err != nil {
+		return record{row: row, skip: fmt.Sprintf("invalid JSON object: %v", err)}
+	}
+
+	values := make(map[string]string, len(obj))
+	for key, v := range obj {
+		field := columns[normalizeColumn(key)]
+		if field == "" {
+			continue
+		}
+
+		switch v := v.(type) {
+		case string:
+			values[field] = strings.TrimSpace(v)
+		case json.Number:
+			values[field] = v.String()
+		case bool:
+			// own в JSON может быть логическим значением
+			values[field] = "0"
+			if v {
+				values[field] = "1"
+			}
+		case nil:
+		default:
+			return record{row: row, skip: fmt.Sprintf("%s: unsupported value of type %T", key, v)}
+		}
+	}
+
+	return newRecord(row, values)
+}
+
+// firstByte пропускает BOM и пробелы и возвращает первый значимый байт, не
+// извлекая его из br.
+func firstByte(br *bufio.Reader) (byte, error) {
+	if bom, _ := br.Peek(len(utf8BOM)); string(bom) == utf8BOM {
+		_, _ = br.Discard(len(utf8BOM))
+	}
+
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			return b, br.UnreadByte()
+		}
+	}
+}
+
+// bggItem — элемент item из ответа BGG XML API2 /collection.
+type bggItem struct {
+	ObjectID string `xml:"objectid,attr"`
+	Name     string `xml:"name"`
+	Status   struct {
+		Own string `xml:"own,attr"`
+	} `xml:"status"`
+}
+
+// parseBGG читает выгрузку коллекции BGG. Игры без отметки own пропускаются.
+func parseBGG(r io.Reader) ([]record, error) {
+	dec := xml.NewDecoder(r)
+
+	var records []record
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "item" {
+			continue
+		}
+
+		var item bggItem
+		if err = dec.DecodeElement(&item, &start); err != nil {
+			return nil, fmt.Errorf("%w: item %d: %v", ErrInvalidFile, len(records)+1, err)
+		}
+
+		values := map[string]string{
+			fieldTitle: strings.TrimSpace(item.Name),
+			fieldBGGID: strings.TrimSpace(item.ObjectID),
+		}
+		if item.Status.Own != "" {
+			values[fieldOwn] = item.Status.Own
+		}
+		records = append(records, newRecord(len(records)+1, values))
+	}
+
+	// Пока BGG готовит выгрузку, вместо неё приходит <message>
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: no item elements found", ErrInvalidFile)
+	}
+
+	return records, nil
+}
+
+func normalizeColumn(column string) string {
+	column = strings.TrimPrefix(column, utf8BOM)
+	column = strings.ToLower(strings.TrimSpace(column))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(column)
+}
@@ -0,0 +1,127 @@
+package collectionimport
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseBGGCollectionCSV(t *testing.T) {
+	const file = "objectname,objectid,rating,own,wishlist,originalname\n" +
+		"Carcassonne,822,8,1,0,Carcassonne\n" +
+		"Scythe,169786,N/A,0,1,Scythe\n" +
+		"Catan,abc,7,1,0,\n"
+
+	records, err := parse(strings.NewReader(file), FormatCSV)
+	if err != nil {
+		t.Fatalf("parse() error = %v", err)
+	}
+
+	want := []record{
+		{row: 2, title: "Carcassonne", externalID: "bgg:822"},
+		{row: 3, title: "Scythe", externalID: "bgg:169786", skip: "not owned"},
+		{row: 4, title: "Catan", skip: `bgg_id: "abc" is not a BGG id`},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records = %+v\nwant %+v", records, want)
+	}
+}
+
+func TestParseBoardBoxExport(t *testing.T) {
+	const csvFile = "\ufeffcollection_id,collection,added_at,game_id,title,external_id\n" +
+		"1,Полка,2026-10-17T12:00:00Z,5,Серп,\n" +
+		"1,Полка,2026-10-17T12:00:00Z,7,Каркассон,bgg:822\n"
+	const jsonFile = `[
+{"collection_id":1,"collection":"Полка","game_id":5,"title":"Серп","external_id":null},
+{"collection_id":1,"collection":"Полка","game_id":7,"title":"Каркассон","external_id":"bgg:822"}
+]`
+
+	want := []record{
+		{row: 2, title: "Серп", sourceID: 5, collection: "Полка"},
+		{row: 3, title: "Каркассон", sourceID: 7, externalID: "bgg:822", collection: "Полка"},
+	}
+
+	records, err := parse(strings.NewReader(csvFile), FormatCSV)
+	if err != nil {
+		t.Fatalf("parse(csv) error = %v", err)
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("csv records = %+v\nwant %+v", records, want)
+	}
+
+	// В JSON-массиве номер записи — номер элемента
+	want[0].row, want[1].row = 1, 2
+	records, err = parse(strings.NewReader(jsonFile), FormatJSON)
+	if err != nil {
+		t.Fatalf("parse(json) error = %v", err)
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("json records = %+v\nwant %+v", records, want)
+	}
+}
+
+func TestParseBGGCollectionXML(t *testing.T) {
+	const file = `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<items totalitems="2" termsofuse="https://boardgamegeek.com/xmlapi/termsofuse">
+	<item objecttype="thing" objectid="13" subtype="boardgame" collid="1">
+		<name sortindex="1">Catan</name>
+		<yearpublished>1995</yearpublished>
+		<status own="1" prevowned="0" fortrade="0" want="0" wanttoplay="0" wanttobuy="0" wishlist="0" preordered="0" lastmodified="2026-01-01 10:00:00" />
+		<numplays>3</numplays>
+	</item>
+	<item objecttype="thing" objectid="822" subtype="boardgame" collid="2">
+		<name sortindex="1">Carcassonne</name>
+		<status own="0" prevowned="1" lastmodified="2026-01-01 10:00:00" />
+	</item>
+</items>`
+
+	records, err := parse(strings.NewReader(file), FormatBGG)
+	if err != nil {
+		t.Fatalf("parse() error = %v", err)
+	}
+
+	want := []record{
+		{row: 1, title: "Catan", externalID: "bgg:13"},
+		{row: 2, title: "Carcassonne", externalID: "bgg:822", skip: "not owned"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records = %+v\nwant %+v", records, want)
+	}
+}
+
+func TestParseInvalidFiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		file   string
+	}{
+		{"empty csv", FormatCSV, ""},
+		{"csv without title", FormatCSV, "rating,comment\n8,ok\n"},
+		{"bgg queued", FormatBGG, `<message>Your request for this collection has been accepted and will be processed.</message>`},
+		{"broken json array", FormatJSON, `[{"title":"Серп"},`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parse(strings.NewReader(tt.file), tt.format); !errors.Is(err, ErrInvalidFile) {
+				t.Errorf("parse() error = %v, want ErrInvalidFile", err)
+			}
+		})
+	}
+}
+
+func TestParseChoices(t *testing.T) {
+	got, err := ParseChoices(" 3=17, 5 = 42 ,")
+	if err != nil {
+		t.Fatalf("ParseChoices() error = %v", err)
+	}
+	if want := map[int]int64{3: 17, 5: 42}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseChoices() = %v, want %v", got, want)
+	}
+
+	for _, s := range []string{"3", "0=1", "a=1", "3=-1"} {
+		if _, err := ParseChoices(s); !errors.Is(err, ErrInvalidChoice) {
+			t.Errorf("ParseChoices(%q) error = %v, want ErrInvalidChoice", s, err)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+// Package collectionimport переносит коллекции из других приложений: по
+// выгрузке BoardGameGeek или BoardBox находит игры каталога и создаёт из них
+// коллекцию пользователя.
+package collectionimport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/board-box/backend/internal/db"
+	"github.com/board-box/backend/internal/service/collection"
+	"github.com/board-box/backend/internal/service/game"
+)
+
+const (
+	// maxCandidates ограничивает число похожих игр в отчёте для одной записи.
+	maxCandidates = 5
+	// fuzzyMatchScore — минимальное сходство названий, при котором похожая
+	// игра выбирается без участия пользователя.
+	fuzzyMatchScore = 0.6
+	// fuzzyMatchGap — насколько лучшая похожая игра должна опережать
+	// следующую, чтобы выбор был однозначным.
+	fuzzyMatchGap = 0.2
+
+	defaultName = "Импорт"
+)
+
+// Catalog — операции каталога, нужные импорту.
+type Catalog interface {
+	GetGames(ctx context.Context, ids []int64) ([]game.Game, error)
+	FindByExternalIDs(ctx context.Context, externalIDs []string) (map[string]int64, error)
+	MatchTitle(ctx context.Context, title string, limit int) ([]game.TitleMatch, error)
+}
+
+// Collections — операции с коллекциями, нужные импорту.
+type Collections interface {
+	CreateCollection(ctx context.Context, userID int64, req collection.Collection) (collection.Collection, error)
+	AddGameToCollection(ctx context.Context, collectionID, gameID, userID int64) error
+}
+
+type Service struct {
+	games       Catalog
+	collections Collections
+	tx          db.TxManager
+}
+
+func NewService(games Catalog, collections Collections, tx db.TxManager) *Service {
+	return &Service{
+		games:       games,
+		collections: collections,
+		tx:          tx,
+	}
+}
+
+// Import сопоставляет записи файла с играми каталога: по ID игры из выгрузки
+// BoardBox, по внешнему ID (например, ID игры на BGG), затем по точному и
+// похожему названию. Без opts.Confirm возвращает только отчёт. С ним создаёт
+// коллекцию из найденных игр и игр, выбранных в opts.Choices; неоднозначные и
+// ненайденные записи без выбора пропускаются.
+func (s *Service) Import(ctx context.Context, userID int64, r io.Reader, opts Options) (Report, error) {
+	records, err := parse(r, opts.Format)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Report{
+		Format:  opts.Format,
+		Name:    collectionName(opts, records),
+		Total:   len(records),
+		Entries: make([]Entry, len(records)),
+	}
+
+	games, externalIDs, err := s.lookup(ctx, records, opts.Choices)
+	if err != nil {
+		return Report{}, err
+	}
+
+	rows := make(map[int]bool, len(records))
+	titles := make(map[string][]game.TitleMatch)
+	for i, rec := range records {
+		rows[rec.row] = true
+
+		entry := Entry{
+			Row:        rec.row,
+			Title:      rec.title,
+			ExternalID: rec.externalID,
+			SourceID:   rec.sourceID,
+		}
+		if rec.skip != "" {
+			entry.Status, entry.Reason = StatusSkipped, rec.skip
+		} else if err = s.match(ctx, &entry, games, externalIDs, titles); err != nil {
+			return Report{}, err
+		}
+
+		if id, ok := opts.Choices[rec.row]; ok {
+			g, found := games[id]
+			if !found {
+				return Report{}, fmt.Errorf("%w: row %d: game %d not found", ErrInvalidChoice, rec.row, id)
+			}
+			entry.Status, entry.MatchedBy, entry.Reason, entry.Candidates = StatusMatched, MatchByChoice, "", nil
+			entry.Game = &GameRef{ID: g.ID, Title: g.Title}
+		}
+
+		report.Entries[i] = entry
+		report.count(entry.Status)
+	}
+
+	for row := range opts.Choices {
+		if !rows[row] {
+			return Report{}, fmt.Errorf("%w: row %d not found in file", ErrInvalidChoice, row)
+		}
+	}
+
+	if !opts.Confirm {
+		return report, nil
+	}
+
+	err = s.tx.WithTx(ctx, func(ctx context.Context) error {
+		return s.save(ctx, userID, &report)
+	})
+	if err != nil {
+		return Report{}, err
+	}
+
+	return report, nil
+}
+
+// lookup загружает игры, на которые записи ссылаются по ID и внешнему ID, и
+// игры, выбранные пользователем.
+func (s *Service) lookup(ctx context.Context, records []record, choices map[int]int64) (map[int64]game.Game, map[string]int64, error) {
+	var externalIDs []string
+	for _, rec := range records {
+		if rec.externalID != "" {
+			externalIDs = append(externalIDs, rec.externalID)
+		}
+	}
+
+	byExternalID, err := s.games.FindByExternalIDs(ctx, externalIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ids []int64
+	for _, rec := range records {
+		if rec.sourceID != 0 {
+			ids = append(ids, rec.sourceID)
+		}
+	}
+	for _, id := range byExternalID {
+		ids = append(ids, id)
+	}
+	for _, id := range choices {
+		ids = append(ids, id)
+	}
+
+	games := make(map[int64]game.Game, len(ids))
+	if len(ids) == 0 {
+		return games, byExternalID, nil
+	}
+
+	found, err := s.games.GetGames(ctx, ids)
+	if err != nil && !errors.Is(err, game.ErrGameNotFound) {
+		return nil, nil, err
+	}
+	for _, g := range found {
+		games[g.ID] = g
+	}
+
+	return games, byExternalID, nil
+}
+
+// match ищет игру для записи. titles кэширует поиск по названию: в выгрузках
+// одна игра может встречаться несколько раз.
+func (s *Service) match(ctx context.Context, entry *Entry, games map[int64]game.Game, externalIDs map[string]int64, titles map[string][]game.TitleMatch) error {
+	// ID из чужой базы может указывать на другую игру, поэтому название должно совпасть
+	if g, ok := games[entry.SourceID]; ok && (entry.Title == "" || strings.EqualFold(g.Title, entry.Title)) {
+		entry.Status, entry.MatchedBy = StatusMatched, MatchByID
+		entry.Game = &GameRef{ID: g.ID, Title: g.Title}
+		return nil
+	}
+
+	if id, ok := externalIDs[entry.ExternalID]; ok && entry.ExternalID != "" {
+		entry.Status, entry.MatchedBy = StatusMatched, MatchByExternalID
+		entry.Game = &GameRef{ID: id, Title: games[id].Title}
+		return nil
+	}
+
+	if entry.Title == "" {
+		entry.Status = StatusUnmatched
+		return nil
+	}
+
+	key := strings.ToLower(entry.Title)
+	matches, ok := titles[key]
+	if !ok {
+		var err error
+		if matches, err = s.games.MatchTitle(ctx, entry.Title, maxCandidates); err != nil {
+			return err
+		}
+		titles[key] = matches
+	}
+
+	var exact []GameRef
+	for _, m := range matches {
+		if m.Exact {
+			exact = append(exact, GameRef{ID: m.ID, Title: m.Title, Score: m.Score})
+		}
+	}
+
+	switch {
+	case len(exact) == 1:
+		entry.Status, entry.MatchedBy, entry.Game = StatusMatched, MatchByTitle, &exact[0]
+	case len(exact) > 1:
+		entry.Status, entry.Candidates = StatusAmbiguous, exact
+	case len(matches) == 0:
+		entry.Status = StatusUnmatched
+	case matches[0].Score >= fuzzyMatchScore && (len(matches) == 1 || matches[0].Score-matches[1].Score >= fuzzyMatchGap):
+		entry.Status, entry.MatchedBy = StatusMatched, MatchByFuzzyTitle
+		entry.Game = &GameRef{ID: matches[0].ID, Title: matches[0].Title, Score: matches[0].Score}
+	default:
+		entry.Status = StatusAmbiguous
+		for _, m := range matches {
+			entry.Candidates = append(entry.Candidates, GameRef{ID: m.ID, Title: m.Title, Score: m.Score})
+		}
+	}
+
+	return nil
+}
+
+// save создаёт коллекцию из найденных игр в порядке записей файла.
+func (s *Service) save(ctx context.Context, userID int64, report *Report) error {
+	var gameIDs []int64
+	seen := make(map[int64]bool)
+	for _, entry := range report.Entries {
+		if entry.Status == StatusMatched && !seen[entry.Game.ID] {
+			gameIDs = append(gameIDs, entry.Game.ID)
+			seen[entry.Game.ID] = true
+		}
+	}
+	if len(gameIDs) == 0 {
+		return ErrNothingMatched
+	}
+
+	c, err := s.collections.CreateCollection(ctx, userID, collection.Collection{Name: report.Name})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range gameIDs {
+		if err = s.collections.AddGameToCollection(ctx, c.ID, id, userID); err != nil {
+			return fmt.Errorf("add game %d: %w", id, err)
+		}
+	}
+
+	report.Confirmed, report.CollectionID = true, c.ID
+	return nil
+}
+
+func (r *Report) count(status string) {
+	switch status {
+	case StatusMatched:
+		r.Matched++
+	case StatusAmbiguous:
+		r.Ambiguous++
+	case StatusUnmatched:
+		r.Unmatched++
+	case StatusSkipped:
+		r.Skipped++
+	}
+}
+
+// collectionName выбирает название коллекции: заданное явно, общее для всех
+// записей выгрузки BoardBox или имя файла.
+func collectionName(opts Options, records []record) string {
+	if name := strings.TrimSpace(opts.Name); name != "" {
+		return name
+	}
+
+	var name string
+	for _, rec := range records {
+		if rec.collection == "" || (name != "" && rec.collection != name) {
+			name = ""
+			break
+		}
+		name = rec.collection
+	}
+	if name != "" {
+		return name
+	}
+
+	if base := strings.TrimSuffix(filepath.Base(opts.Filename), filepath.Ext(opts.Filename)); base != "" && base != "." {
+		return base
+	}
+	return defaultName
+}
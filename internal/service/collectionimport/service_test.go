@@ -0,0 +1,240 @@
+package collectionimport
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/board-box/backend/internal/service/collection"
+	"github.com/board-box/backend/internal/service/game"
+)
+
+// fakeCatalog ищет игры в памяти. Похожие названия задаются явно в similar.
+type fakeCatalog struct {
+	games   []game.Game
+	similar map[string][]game.TitleMatch
+}
+
+func (c *fakeCatalog) GetGames(_ context.Context, ids []int64) ([]game.Game, error) {
+	var found []game.Game
+	for _, g := range c.games {
+		for _, id := range ids {
+			if g.ID == id {
+				found = append(found, g)
+				break
+			}
+		}
+	}
+	if len(found) == 0 {
+		return nil, game.ErrGameNotFound
+	}
+	return found, nil
+}
+
+func (c *fakeCatalog) FindByExternalIDs(_ context.Context, externalIDs []string) (map[string]int64, error) {
+	ids := map[string]int64{}
+	for _, g := range c.games {
+		for _, id := range externalIDs {
+			if g.ExternalID != nil && *g.ExternalID == id {
+				ids[id] = g.ID
+			}
+		}
+	}
+	return ids, nil
+}
+
+func (c *fakeCatalog) MatchTitle(_ context.Context, title string, limit int) ([]game.TitleMatch, error) {
+	var matches []game.TitleMatch
+	for _, g := range c.games {
+		if strings.EqualFold(g.Title, title) {
+			matches = append(matches, game.TitleMatch{ID: g.ID, Title: g.Title, Score: 1, Exact: true})
+		}
+	}
+	matches = append(matches, c.similar[title]...)
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// fakeCollections запоминает созданную коллекцию и добавленные игры.
+type fakeCollections struct {
+	created collection.Collection
+	gameIDs []int64
+}
+
+func (c *fakeCollections) CreateCollection(_ context.Context, userID int64, req collection.Collection) (collection.Collection, error) {
+	c.created = collection.Collection{ID: 100, UserID: userID, Name: req.Name}
+	return c.created, nil
+}
+
+func (c *fakeCollections) AddGameToCollection(_ context.Context, collectionID, gameID, userID int64) error {
+	if collectionID != c.created.ID || userID != c.created.UserID {
+		return collection.ErrForbidden
+	}
+	c.gameIDs = append(c.gameIDs, gameID)
+	return nil
+}
+
+// fakeTx выполняет fn без транзакции.
+type fakeTx struct{}
+
+func (fakeTx) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func newFakeCatalog() *fakeCatalog {
+	return &fakeCatalog{
+		games: []game.Game{
+			{ID: 1, Title: "Каркассон", ExternalID: ptr("bgg:822")},
+			{ID: 2, Title: "Серп"},
+			{ID: 3, Title: "Манчкин"},
+			{ID: 4, Title: "Манчкин"},
+			{ID: 5, Title: "Кодовые имена"},
+			{ID: 6, Title: "Кодовые имена: Картинки"},
+		},
+		similar: map[string][]game.TitleMatch{
+			"Кодовые имена!": {
+				{ID: 5, Title: "Кодовые имена", Score: 0.8},
+				{ID: 6, Title: "Кодовые имена: Картинки", Score: 0.5},
+			},
+			"Кодовые": {
+				{ID: 5, Title: "Кодовые имена", Score: 0.5},
+				{ID: 6, Title: "Кодовые имена: Картинки", Score: 0.4},
+			},
+		},
+	}
+}
+
+const collectionCSV = `game_id,title,external_id,collection
+2,Серп,,Полка
+9,Каркассон,bgg:822,Полка
+1,Не Каркассон,,Полка
+,Манчкин,,Полка
+,Кодовые имена!,,Полка
+,Кодовые,,Полка
+,Неизвестная игра,,Полка
+`
+
+func TestImportReport(t *testing.T) {
+	collections := &fakeCollections{}
+	svc := NewService(newFakeCatalog(), collections, fakeTx{})
+
+	report, err := svc.Import(context.Background(), 7, strings.NewReader(collectionCSV), Options{Format: FormatCSV, Filename: "shelf.csv"})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	type result struct {
+		status, matchedBy string
+		gameID            int64
+		candidates        int
+	}
+	want := []result{
+		{StatusMatched, MatchByID, 2, 0},
+		// ID 9 нет в каталоге, игра находится по внешнему ID
+		{StatusMatched, MatchByExternalID, 1, 0},
+		// ID есть, но название другое: ID из чужой базы не используется
+		{StatusUnmatched, "", 0, 0},
+		{StatusAmbiguous, "", 0, 2},
+		{StatusMatched, MatchByFuzzyTitle, 5, 0},
+		{StatusAmbiguous, "", 0, 2},
+		{StatusUnmatched, "", 0, 0},
+	}
+	if len(report.Entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(report.Entries), len(want))
+	}
+	for i, e := range report.Entries {
+		got := result{e.Status, e.MatchedBy, 0, len(e.Candidates)}
+		if e.Game != nil {
+			got.gameID = e.Game.ID
+		}
+		if got != want[i] {
+			t.Errorf("entry %d (%q) = %+v, want %+v", e.Row, e.Title, got, want[i])
+		}
+	}
+
+	if report.Name != "Полка" || report.Matched != 3 || report.Ambiguous != 2 || report.Unmatched != 2 || report.Confirmed {
+		t.Errorf("report = %+v", report)
+	}
+	if collections.created.ID != 0 {
+		t.Error("report without confirm created a collection")
+	}
+}
+
+func TestImportConfirm(t *testing.T) {
+	collections := &fakeCollections{}
+	svc := NewService(newFakeCatalog(), collections, fakeTx{})
+
+	// Строка 5 — неоднозначный Манчкин, строка 4 — ненайденная игра
+	report, err := svc.Import(context.Background(), 7, strings.NewReader(collectionCSV), Options{
+		Format:  FormatCSV,
+		Name:    "С BGG",
+		Confirm: true,
+		Choices: map[int]int64{5: 4, 4: 2},
+	})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	if !report.Confirmed || report.CollectionID != 100 || collections.created.Name != "С BGG" {
+		t.Errorf("report = %+v, created = %+v", report, collections.created)
+	}
+	if e := report.Entries[3]; e.MatchedBy != MatchByChoice || e.Game.ID != 4 || e.Candidates != nil {
+		t.Errorf("chosen entry = %+v", e)
+	}
+	if report.Matched != 5 || report.Ambiguous != 1 || report.Unmatched != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	// Повторы не добавляются, игры идут в порядке записей
+	if want := []int64{2, 1, 4, 5}; !reflect.DeepEqual(collections.gameIDs, want) {
+		t.Errorf("added games = %v, want %v", collections.gameIDs, want)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	svc := NewService(newFakeCatalog(), &fakeCollections{}, fakeTx{})
+	ctx := context.Background()
+
+	_, err := svc.Import(ctx, 7, strings.NewReader(collectionCSV), Options{Format: FormatCSV, Choices: map[int]int64{5: 999}})
+	if !errors.Is(err, ErrInvalidChoice) {
+		t.Errorf("unknown game choice: error = %v, want ErrInvalidChoice", err)
+	}
+
+	_, err = svc.Import(ctx, 7, strings.NewReader(collectionCSV), Options{Format: FormatCSV, Choices: map[int]int64{42: 1}})
+	if !errors.Is(err, ErrInvalidChoice) {
+		t.Errorf("unknown row choice: error = %v, want ErrInvalidChoice", err)
+	}
+
+	_, err = svc.Import(ctx, 7, strings.NewReader("title\nНеизвестная игра\n"), Options{Format: FormatCSV, Confirm: true})
+	if !errors.Is(err, ErrNothingMatched) {
+		t.Errorf("nothing matched: error = %v, want ErrNothingMatched", err)
+	}
+}
+
+func TestCollectionName(t *testing.T) {
+	shelf := []record{{collection: "Полка"}, {collection: "Полка"}}
+	mixed := []record{{collection: "Полка"}, {collection: "Вишлист"}}
+
+	tests := []struct {
+		opts    Options
+		records []record
+		want    string
+	}{
+		{Options{Name: " Мои игры ", Filename: "bgg.csv"}, shelf, "Мои игры"},
+		{Options{Filename: "bgg.csv"}, shelf, "Полка"},
+		{Options{Filename: "collections.json"}, mixed, "collections"},
+		{Options{}, nil, defaultName},
+	}
+	for _, tt := range tests {
+		if got := collectionName(tt.opts, tt.records); got != tt.want {
+			t.Errorf("collectionName(%+v) = %q, want %q", tt.opts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Правила разбора повторяют бэкфилл в миграции game_typed_attributes.
+var (
+	numberRe   = regexp.MustCompile(`\d{1,3}`)
+	decimalRe  = regexp.MustCompile(`\d{1,2}(?:[.,]\d+)?`)
+	outOfTenRe = regexp.MustCompile(`\d{1,2}(?:[.,]\d+)?\s*/\s*10`)
+	// durationRe — число со словом после него: «1.5 часа», «30 мин», «2ч».
+	durationRe  = regexp.MustCompile(`(\d{1,3}(?:[.,]\d+)?)(\s*)([а-яёА-ЯЁa-zA-Z]*)`)
+	rangeSepRe  = regexp.MustCompile(`(?i)(-|–|—|до|to)`)
+	hourUnitRe  = regexp.MustCompile(`(?i)^(час|hour|ч$|h$|hrs?$)`)
+	minUnitRe   = regexp.MustCompile(`(?i)^(мин|min|м$|m$)`)
+	openEndedRe = regexp.MustCompile(`\+\s*$`)
+	veryHardRe  = regexp.MustCompile(`(?i)очень\s+сложн`)
+	hardRe      = regexp.MustCompile(`(?i)(сложн|трудн|высок)`)
+	mediumRe    = regexp.MustCompile(`(?i)средн`)
+	easyRe      = regexp.MustCompile(`(?i)(л[её]гк|прост|низк)`)
+)
+
+const (
+	minDifficulty = 1.0
+	maxDifficulty = 5.0
+)
+
+// fillAttributes дополняет числовые характеристики из строковых и наоборот,
+// чтобы обе формы оставались согласованными.
+func fillAttributes(g *Game) {
+	if g.MinPlayers == nil && g.MaxPlayers == nil {
+		g.MinPlayers, g.MaxPlayers = parsePlayers(g.Person)
+	} else if g.Person == "" {
+		g.Person = formatRange(g.MinPlayers, g.MaxPlayers, "")
+	}
+
+	if g.MinPlayTime == nil && g.MaxPlayTime == nil {
+		g.MinPlayTime, g.MaxPlayTime = parsePlayTime(g.AvgTime)
+	} else if g.AvgTime == "" {
+		g.AvgTime = formatRange(g.MinPlayTime, g.MaxPlayTime, " мин")
+	}
+
+	if g.MinAge == nil {
+		g.MinAge = parseAge(g.Age)
+	} else if g.Age == "" {
+		g.Age = fmt.Sprintf("%d+", *g.MinAge)
+	}
+
+	if g.DifficultyLevel == nil {
+		g.DifficultyLevel = parseDifficulty(g.Difficulty)
+	} else if g.Difficulty == "" {
+		g.Difficulty = strconv.FormatFloat(*g.DifficultyLevel, 'f', 1, 64) + "/5"
+	}
+}
+
+func parsePlayers(s string) (*int, *int) {
+	nums := numbers(s)
+	if len(nums) == 0 {
+		return nil, nil
+	}
+
+	lo := positive(nums[0])
+	if openEndedRe.MatchString(s) {
+		return lo, nil
+	}
+	return lo, positive(nums[len(nums)-1])
+}
+
+type timeUnit int
+
+const (
+	unitNone timeUnit = iota
+	unitMinute
+	unitHour
+)
+
+// durationPart — число из строки времени с единицей, указанной после него.
+type durationPart struct {
+	value float64
+	unit  timeUnit
+	// gap — текст между предыдущим числом и этим
+	gap string
+}
+
+// parsePlayTime разбирает время партии в минутах: «30-45 мин», «1.5 часа»,
+// «1-2 часа», «1 час 30 мин». Число без единицы получает единицу следующего
+// числа («1-2 часа»), последнее — минуты. Минуты сразу после часов без
+// разделителя диапазона прибавляются к ним.
+func parsePlayTime(s string) (*int, *int) {
+	var parts []durationPart
+	prevEnd := 0
+	for _, m := range durationRe.FindAllStringSubmatchIndex(s, -1) {
+		value, err := strconv.ParseFloat(strings.Replace(s[m[2]:m[3]], ",", ".", 1), 64)
+		if err != nil {
+			continue
+		}
+
+		unit := unitNone
+		switch word := s[m[6]:m[7]]; {
+		case hourUnitRe.MatchString(word):
+			unit = unitHour
+		case minUnitRe.MatchString(word):
+			unit = unitMinute
+		}
+		parts = append(parts, durationPart{value: value, unit: unit, gap: s[prevEnd:m[0]]})
+		prevEnd = m[5]
+	}
+	if len(parts) == 0 {
+		return nil, nil
+	}
+
+	carry := unitMinute
+	for i := len(parts) - 1; i >= 0; i-- {
+		if parts[i].unit == unitNone {
+			parts[i].unit = carry
+		} else {
+			carry = parts[i].unit
+		}
+	}
+
+	var minutes []float64
+	for i, p := range parts {
+		if i > 0 && parts[i-1].unit == unitHour && p.unit == unitMinute && !rangeSepRe.MatchString(p.gap) {
+			minutes[len(minutes)-1] += p.value
+			continue
+		}
+		if p.unit == unitHour {
+			p.value *= 60
+		}
+		minutes = append(minutes, p.value)
+	}
+
+	return positive(int(math.Round(minutes[0]))), positive(int(math.Round(minutes[len(minutes)-1])))
+}
+
+func parseAge(s string) *int {
+	nums := numbers(s)
+	if len(nums) == 0 {
+		return nil
+	}
+	return &nums[0]
+}
+
+func parseDifficulty(s string) *float64 {
+	var level float64
+	switch {
+	case strings.TrimSpace(s) == "":
+		return nil
+	case decimalRe.MatchString(s):
+		v, err := strconv.ParseFloat(strings.Replace(decimalRe.FindString(s), ",", ".", 1), 64)
+		if err != nil {
+			return nil
+		}
+		level = v
+		if outOfTenRe.MatchString(s) {
+			level /= 2
+		}
+	case veryHardRe.MatchString(s):
+		level = 5
+	case hardRe.MatchString(s):
+		level = 4
+	case mediumRe.MatchString(s):
+		level = 3
+	case easyRe.MatchString(s):
+		level = 1.5
+	}
+
+	if level < minDifficulty || level > maxDifficulty {
+		return nil
+	}
+	return &level
+}
+
+func numbers(s string) []int {
+	var nums []int
+	for _, m := range numberRe.FindAllString(s, -1) {
+		n, err := strconv.Atoi(m)
+		if err != nil {
+			continue
+		}
+		nums = append(nums, n)
+	}
+	return nums
+}
+
+func positive(n int) *int {
+	if n <= 0 {
+		return nil
+	}
+	return &n
+}
+
+func formatRange(lo, hi *int, unit string) string {
+	switch {
+	case lo != nil && hi != nil && *lo == *hi:
+		return fmt.Sprintf("%d%s", *lo, unit)
+	case lo != nil && hi != nil:
+		return fmt.Sprintf("%d-%d%s", *lo, *hi, unit)
+	case lo != nil:
+		return fmt.Sprintf("%d+%s", *lo, unit)
+	case hi != nil:
+		return fmt.Sprintf("до %d%s", *hi, unit)
+	}
+	return ""
+}
@@ -0,0 +1,111 @@
+package game
+
+import "testing"
+
+func intPtr(v int) *int { return &v }
+
+func floatPtr(v float64) *float64 { return &v }
+
+func equalInt(a, b *int) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+func TestParsePlayers(t *testing.T) {
+	tests := []struct {
+		in     string
+		lo, hi *int
+	}{
+		{"2-5", intPtr(2), intPtr(5)},
+		{"от 2 до 4 игроков", intPtr(2), intPtr(4)},
+		{"3+", intPtr(3), nil},
+		{"1", intPtr(1), intPtr(1)},
+		{"много", nil, nil},
+	}
+	for _, tt := range tests {
+		lo, hi := parsePlayers(tt.in)
+		if !equalInt(lo, tt.lo) || !equalInt(hi, tt.hi) {
+			t.Errorf("parsePlayers(%q) = %v, %v", tt.in, lo, hi)
+		}
+	}
+}
+
+func TestParsePlayTime(t *testing.T) {
+	tests := []struct {
+		in     string
+		lo, hi *int
+	}{
+		{"30-45 мин", intPtr(30), intPtr(45)},
+		{"1-2 часа", intPtr(60), intPtr(120)},
+		{"2 ч", intPtr(120), intPtr(120)},
+		{"1.5 часа", intPtr(90), intPtr(90)},
+		{"1,5-2 часа", intPtr(90), intPtr(120)},
+		{"1 час 30 мин", intPtr(90), intPtr(90)},
+		{"1ч30м", intPtr(90), intPtr(90)},
+		{"1 час 30 мин - 2 часа", intPtr(90), intPtr(120)},
+		{"45 мин - 1.5 ч", intPtr(45), intPtr(90)},
+		{"от 30 до 60 минут", intPtr(30), intPtr(60)},
+		{"2-3 hours", intPtr(120), intPtr(180)},
+		{"90", intPtr(90), intPtr(90)},
+		{"", nil, nil},
+	}
+	for _, tt := range tests {
+		lo, hi := parsePlayTime(tt.in)
+		if !equalInt(lo, tt.lo) || !equalInt(hi, tt.hi) {
+			t.Errorf("parsePlayTime(%q) = %v, %v", tt.in, lo, hi)
+		}
+	}
+}
+
+func TestParseDifficulty(t *testing.T) {
+	tests := []struct {
+		in   string
+		want *float64
+	}{
+		{"2.5", floatPtr(2.5)},
+		{"3,5/5", floatPtr(3.5)},
+		{"7/10", floatPtr(3.5)},
+		{"Очень сложная", floatPtr(5)},
+		{"Средняя", floatPtr(3)},
+		{"Лёгкая", floatPtr(1.5)},
+		{"9", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got := parseDifficulty(tt.in)
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("parseDifficulty(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFillAttributes(t *testing.T) {
+	g := Game{Person: "2-4", AvgTime: "1 час", Age: "10+", Difficulty: "Средняя"}
+	fillAttributes(&g)
+	if *g.MinPlayers != 2 || *g.MaxPlayers != 4 || *g.MinPlayTime != 60 || *g.MinAge != 10 || *g.DifficultyLevel != 3 {
+		t.Errorf("typed attributes not parsed: %+v", g)
+	}
+
+	g = Game{MinPlayers: intPtr(1), MaxPlayers: intPtr(4), MinPlayTime: intPtr(45), MinAge: intPtr(8), DifficultyLevel: floatPtr(2)}
+	fillAttributes(&g)
+	if g.Person != "1-4" || g.AvgTime != "45+ мин" || g.Age != "8+" || g.Difficulty != "2.0/5" {
+		t.Errorf("display attributes not formatted: %+v", g)
+	}
+}
+
+func TestGamePatchResetsDerivedForm(t *testing.T) {
+	g := Game{Title: "Азул", Person: "2-4"}
+	fillAttributes(&g)
+
+	GamePatch{MaxPlayers: intPtr(6)}.apply(&g)
+	fillAttributes(&g)
+	if g.Person != "2-6" {
+		t.Errorf("person = %q, want %q", g.Person, "2-6")
+	}
+
+	person := "3-5"
+	GamePatch{Person: &person}.apply(&g)
+	fillAttributes(&g)
+	if *g.MinPlayers != 3 || *g.MaxPlayers != 5 {
+		t.Errorf("players = %d-%d, want 3-5", *g.MinPlayers, *g.MaxPlayers)
+	}
+}
@@ -1,14 +1,158 @@
 package game
 
+// Game хранит характеристики и в виде строк для отображения (Age, Person,
+// AvgTime, Difficulty), и в числовом виде для фильтрации.
 type Game struct {
-	ID          int64  `json:"id" db:"id"`
-	Title       string `json:"title" db:"title"`
-	Description string `json:"description" db:"description"`
-	Genre       string `json:"genre" db:"genre"`
-	Age         string `json:"age" db:"age"`
-	Person      string `json:"person" db:"person"`
-	AvgTime     string `json:"avg_time" db:"avg_time"`
-	Difficulty  string `json:"complexity" db:"difficulty"`
-	Image       string `json:"image" db:"image"`
-	Rules       string `json:"rules" db:"rules"`
+	ID              int64    `json:"id" db:"id"`
+	Title           string   `json:"title" db:"title"`
+	Description     string   `json:"description" db:"description"`
+	Genre           string   `json:"genre" db:"genre"`
+	Age             string   `json:"age" db:"age"`
+	Person          string   `json:"person" db:"person"`
+	AvgTime         string   `json:"avg_time" db:"avg_time"`
+	Difficulty      string   `json:"complexity" db:"difficulty"`
+	Image           string   `json:"image" db:"image"`
+	Rules           string   `json:"rules" db:"rules"`
+	MinPlayers      *int     `json:"min_players" db:"min_players"`
+	MaxPlayers      *int     `json:"max_players" db:"max_players"`
+	MinPlayTime     *int     `json:"min_play_time" db:"min_play_time"`
+	MaxPlayTime     *int     `json:"max_play_time" db:"max_play_time"`
+	MinAge          *int     `json:"min_age" db:"min_age"`
+	DifficultyLevel *float64 `json:"complexity_level" db:"difficulty_level"`
+	// ExternalID — идентификатор во внешнем источнике импорта, например "bgg:13"
+	ExternalID *string `json:"external_id,omitempty" db:"external_id"`
+	// Оценки пользователей: число, среднее (нет, пока оценок нет) и
+	// байесовская оценка, которая при малом числе голосов близка к 5.5
+	RatingCount int      `json:"rating_count" db:"rating_count"`
+	RatingAvg   *float64 `json:"rating_avg" db:"rating_avg"`
+	RatingScore float64  `json:"rating_score" db:"rating_score"`
+}
+
+// TitleMatch — игра, похожая по названию на искомое. Score — сходство
+// триграмм от 0 до 1, Exact — названия совпадают без учёта регистра.
+type TitleMatch struct {
+	ID    int64   `json:"id" db:"id"`
+	Title string  `json:"title" db:"title"`
+	Score float64 `json:"score" db:"score"`
+	Exact bool    `json:"-" db:"exact"`
+}
+
+// Sort — порядок игр в каталоге.
+type Sort string
+
+const (
+	// SortTitle — по названию, порядок по умолчанию.
+	SortTitle Sort = "title"
+	// SortRating — по средней оценке, игры без оценок в конце.
+	SortRating Sort = "rating"
+	// SortRatingCount — по числу оценок.
+	SortRatingCount Sort = "rating_count"
+	// SortScore — по байесовской оценке.
+	SortScore Sort = "score"
+)
+
+// ListFilter описывает фильтры каталога. Несколько значений одного поля
+// объединяются через OR, разные поля — через AND. Нулевые значения не
+// фильтруют. Sort задаёт порядок списка и на фасеты не влияет.
+type ListFilter struct {
+	Query         string
+	Genres        []string
+	Players       int
+	MaxTime       int
+	PlayerAge     int
+	ComplexityMin float64
+	ComplexityMax float64
+	Sort          Sort
+
+	// Устаревшие фильтры по точному значению строковых характеристик;
+	// оставлены для старых клиентов, новым нужны числовые фильтры выше.
+	Ages         []string
+	Persons      []string
+	AvgTimes     []string
+	Difficulties []string
+}
+
+type FacetValue struct {
+	Value string `json:"value" db:"value"`
+	Count int64  `json:"count" db:"count"`
+}
+
+// Facets — количество игр по каждому значению фильтра, ключ — имя фильтра.
+type Facets map[string][]FacetValue
+
+// GamePatch — частичное обновление игры: nil-поля не меняются.
+type GamePatch struct {
+	Title           *string
+	Description     *string
+	Genre           *string
+	Age             *string
+	Person          *string
+	AvgTime         *string
+	Difficulty      *string
+	Image           *string
+	Rules           *string
+	MinPlayers      *int
+	MaxPlayers      *int
+	MinPlayTime     *int
+	MaxPlayTime     *int
+	MinAge          *int
+	DifficultyLevel *float64
+}
+
+// apply переносит изменения в игру. Если поменялась только одна из форм
+// характеристики, вторая сбрасывается и пересчитывается в fillAttributes.
+func (p GamePatch) apply(g *Game) {
+	setString(&g.Title, p.Title)
+	setString(&g.Description, p.Description)
+	setString(&g.Genre, p.Genre)
+	setString(&g.Image, p.Image)
+	setString(&g.Rules, p.Rules)
+
+	switch {
+	case p.MinPlayers != nil || p.MaxPlayers != nil:
+		setInt(&g.MinPlayers, p.MinPlayers)
+		setInt(&g.MaxPlayers, p.MaxPlayers)
+		g.Person = ""
+		setString(&g.Person, p.Person)
+	case p.Person != nil:
+		g.Person, g.MinPlayers, g.MaxPlayers = *p.Person, nil, nil
+	}
+
+	switch {
+	case p.MinPlayTime != nil || p.MaxPlayTime != nil:
+		setInt(&g.MinPlayTime, p.MinPlayTime)
+		setInt(&g.MaxPlayTime, p.MaxPlayTime)
+		g.AvgTime = ""
+		setString(&g.AvgTime, p.AvgTime)
+	case p.AvgTime != nil:
+		g.AvgTime, g.MinPlayTime, g.MaxPlayTime = *p.AvgTime, nil, nil
+	}
+
+	switch {
+	case p.MinAge != nil:
+		g.MinAge, g.Age = p.MinAge, ""
+		setString(&g.Age, p.Age)
+	case p.Age != nil:
+		g.Age, g.MinAge = *p.Age, nil
+	}
+
+	switch {
+	case p.DifficultyLevel != nil:
+		g.DifficultyLevel, g.Difficulty = p.DifficultyLevel, ""
+		setString(&g.Difficulty, p.Difficulty)
+	case p.Difficulty != nil:
+		g.Difficulty, g.DifficultyLevel = *p.Difficulty, nil
+	}
+}
+
+func setString(dst *string, src *string) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func setInt(dst **int, src *int) {
+	if src != nil {
+		*dst = src
+	}
 }
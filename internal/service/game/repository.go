@@ -3,13 +3,147 @@ package game
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/Masterminds/squirrel"
+	"github.com/board-box/backend/internal/db"
+	"github.com/board-box/backend/internal/pagination"
 	"github.com/georgysavva/scany/v2/pgxscan"
 	pgx "github.com/jackc/pgx/v5"
 )
 
-const gameTableName = "game"
+const (
+	gameTableName = "game"
+
+	// searchVector должен совпадать с выражением индекса idx_game_search.
+	searchVector = "to_tsvector('russian', coalesce(title, '') || ' ' || coalesce(description, ''))"
+	// titleTrgm должен совпадать с выражением индекса idx_game_title_trgm.
+	titleTrgm = "lower(title)"
+)
+
+// Ключи фасетов совпадают с именами параметров фильтра, по которым они
+// считаются: значение фасета можно передать обратно в этот параметр.
+const (
+	FacetGenre           = "genre"
+	FacetPlayers         = "players"
+	FacetMaxTime         = "max_time"
+	FacetPlayerAge       = "player_age"
+	FacetComplexityLevel = "complexity_level"
+
+	// Фасеты устаревших строковых фильтров по точному значению.
+	FacetAge        = "age"
+	FacetPerson     = "person"
+	FacetAvgTime    = "avg_time"
+	FacetComplexity = "complexity"
+
+	// maxPlayersFacet ограничивает фасет по игрокам для игр без верхней границы.
+	maxPlayersFacet = 12
+)
+
+var gameColumns = []string{
+	"id", "title", "description", "genre", "age", "person", "avg_time", "difficulty", "image", "rules",
+	"min_players", "max_players", "min_play_time", "max_play_time", "min_age", "difficulty_level",
+	"external_id",
+}
+
+// selectColumns — gameColumns и агрегаты оценок, которые пишет только
+// хранилище оценок.
+var selectColumns = append(append([]string{}, gameColumns...), "rating_count", "rating_avg", "rating_score")
+
+// sortExprs — выражения числовых сортировок; по ним идут индексы idx_game_rating_*.
+var sortExprs = map[Sort]string{
+	SortRating:      "COALESCE(rating_avg, 0)",
+	SortRatingCount: "rating_count",
+	SortScore:       "rating_score",
+}
+
+// upsertGroups — колонки, которые UpsertGame обновляет вместе: группа
+// меняется, только если в новой записи заполнена хотя бы одна её колонка,
+// чтобы строковая и числовая формы характеристики не разошлись.
+var upsertGroups = [][]string{
+	{"description"},
+	{"genre"},
+	{"image"},
+	{"rules"},
+	{"age", "min_age"},
+	{"person", "min_players", "max_players"},
+	{"avg_time", "min_play_time", "max_play_time"},
+	{"difficulty", "difficulty_level"},
+}
+
+var upsertSet = func() string {
+	sets := []string{"title = EXCLUDED.title"}
+	for _, group := range upsertGroups {
+		present := make([]string, len(group))
+		for i, column := range group {
+			present[i] = fmt.Sprintf("NULLIF(EXCLUDED.%s::TEXT, '') IS NOT NULL", column)
+		}
+		cond := strings.Join(present, " OR ")
+
+		for _, column := range group {
+			sets = append(sets, fmt.Sprintf("%s = CASE WHEN %s THEN EXCLUDED.%s ELSE %s.%s END",
+				column, cond, column, gameTableName, column))
+		}
+	}
+	return strings.Join(append(sets, "updated_at = NOW()"), ", ")
+}()
+
+// facet описывает, как посчитать количество игр по значениям одного фильтра.
+type facet struct {
+	from    string
+	value   string
+	where   squirrel.Sqlizer
+	orderBy string
+}
+
+// columnFacet считает игры по непустым значениям строковой колонки.
+func columnFacet(column string) facet {
+	return facet{
+		from:    gameTableName,
+		value:   column,
+		where:   squirrel.And{squirrel.NotEq{column: nil}, squirrel.NotEq{column: ""}},
+		orderBy: "count DESC, value ASC",
+	}
+}
+
+var (
+	facetFields = []string{
+		FacetGenre, FacetPlayers, FacetMaxTime, FacetPlayerAge, FacetComplexityLevel,
+		FacetAge, FacetPerson, FacetAvgTime, FacetComplexity,
+	}
+	facetDefs = map[string]facet{
+		FacetGenre: columnFacet("genre"),
+		FacetPlayers: {
+			from:    fmt.Sprintf("%s CROSS JOIN LATERAL generate_series(min_players, LEAST(COALESCE(max_players, %d), %d)) AS f(n)", gameTableName, maxPlayersFacet, maxPlayersFacet),
+			value:   "f.n",
+			where:   squirrel.NotEq{"min_players": nil},
+			orderBy: "f.n ASC",
+		},
+		FacetMaxTime: {
+			from:    gameTableName + " CROSS JOIN unnest(ARRAY[30, 60, 90, 120, 180]) AS f(n)",
+			value:   "f.n",
+			where:   squirrel.Expr("COALESCE(max_play_time, min_play_time) <= f.n"),
+			orderBy: "f.n ASC",
+		},
+		FacetPlayerAge: {
+			from:    gameTableName,
+			value:   "min_age",
+			where:   squirrel.NotEq{"min_age": nil},
+			orderBy: "min_age ASC",
+		},
+		FacetComplexityLevel: {
+			from:    gameTableName,
+			value:   "ROUND(difficulty_level)::INTEGER",
+			where:   squirrel.NotEq{"difficulty_level": nil},
+			orderBy: "ROUND(difficulty_level)::INTEGER ASC",
+		},
+		FacetAge:        columnFacet("age"),
+		FacetPerson:     columnFacet("person"),
+		FacetAvgTime:    columnFacet("avg_time"),
+		FacetComplexity: columnFacet("difficulty"),
+	}
+)
 
 var (
 	psql            = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
@@ -17,50 +151,215 @@ var (
 	ErrEmptyIDs     = errors.New("empty IDs")
 )
 
+// Repository — хранилище каталога игр.
+type Repository interface {
+	ListGames(ctx context.Context, filter ListFilter, page pagination.Params) ([]Game, pagination.Meta, error)
+	ListFacets(ctx context.Context, filter ListFilter) (Facets, error)
+	GetGameByID(ctx context.Context, id int64) (Game, error)
+	GetGamesByIDs(ctx context.Context, ids []int64) ([]Game, error)
+	GetGameIDsByExternalIDs(ctx context.Context, externalIDs []string) (map[string]int64, error)
+	MatchTitles(ctx context.Context, title string, limit int) ([]TitleMatch, error)
+	EachGame(ctx context.Context, fn func(Game) error) error
+	CreateGame(ctx context.Context, game Game) (int64, error)
+	UpsertGame(ctx context.Context, game Game) (int64, bool, error)
+	UpdateGame(ctx context.Context, game Game) error
+	DeleteGame(ctx context.Context, id int64) error
+}
+
 type repository struct {
-	db *pgx.Conn
+	db db.DB
 }
 
-func newRepository(db *pgx.Conn) *repository {
+func NewRepository(db db.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) listGames(ctx context.Context) ([]Game, error) {
-	query, args, err := psql.
-		Select("id", "title", "description", "genre", "age", "person", "avg_time", "difficulty", "image", "rules").
+type gameCursor struct {
+	Sort  Sort    `json:"s,omitempty"`
+	Title string  `json:"t,omitempty"`
+	Value float64 `json:"v,omitempty"`
+	ID    int64   `json:"id"`
+}
+
+// newGameCursor запоминает ключ сортировки игры g.
+func newGameCursor(sort Sort, g Game) gameCursor {
+	cursor := gameCursor{Sort: sort, ID: g.ID}
+	switch sort {
+	case SortRating:
+		if g.RatingAvg != nil {
+			cursor.Value = *g.RatingAvg
+		}
+	case SortRatingCount:
+		cursor.Value = float64(g.RatingCount)
+	case SortScore:
+		cursor.Value = g.RatingScore
+	default:
+		cursor.Title = g.Title
+	}
+	return cursor
+}
+
+func (r *repository) ListGames(ctx context.Context, filter ListFilter, page pagination.Params) ([]Game, pagination.Meta, error) {
+	limit := page.PageLimit()
+
+	sort := filter.Sort
+	if sort == "" {
+		sort = SortTitle
+	}
+	expr, numeric := sortExprs[sort]
+
+	builder := psql.
+		Select(selectColumns...).
 		From(gameTableName).
-		OrderBy("title ASC").
-		ToSql()
-	if err != nil {
-		return nil, err
+		Limit(uint64(limit + 1))
+	if numeric {
+		builder = builder.OrderBy(expr+" DESC", "id ASC")
+	} else {
+		builder = builder.OrderBy("title ASC", "id ASC")
 	}
 
-	rows, err := r.db.Query(ctx, query, args...)
+	if page.Cursor != "" {
+		var cursor gameCursor
+		if err := pagination.Decode(page.Cursor, &cursor); err != nil {
+			return nil, pagination.Meta{}, err
+		}
+		if cursor.Sort == "" {
+			cursor.Sort = SortTitle
+		}
+		// Курсор от другой сортировки указывает не на то место
+		if cursor.Sort != sort {
+			return nil, pagination.Meta{}, pagination.ErrInvalidCursor
+		}
+		if numeric {
+			builder = builder.Where("("+expr+" < ?::float8 OR ("+expr+" = ?::float8 AND id > ?))", cursor.Value, cursor.Value, cursor.ID)
+		} else {
+			builder = builder.Where("(title, id) > (?, ?)", cursor.Title, cursor.ID)
+		}
+	}
+	if page.Offset > 0 {
+		builder = builder.Offset(uint64(page.Offset))
+	}
+
+	query, args, err := applyFilter(builder, filter, "").ToSql()
 	if err != nil {
-		return nil, err
+		return nil, pagination.Meta{}, err
 	}
-	defer rows.Close()
 
 	var games []Game
-	for rows.Next() {
-		var game Game
-		err = pgxscan.ScanRow(&game, rows)
+	if err = pgxscan.Select(ctx, r.db, &games, query, args...); err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	total, err := r.countGames(ctx, filter)
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	meta := pagination.Meta{Total: total}
+	games, hasMore := pagination.Trim(games, limit)
+	if hasMore {
+		last := games[len(games)-1]
+		meta.NextCursor = pagination.Encode(newGameCursor(sort, last))
+	}
+
+	return games, meta, nil
+}
+
+func (r *repository) countGames(ctx context.Context, filter ListFilter) (int64, error) {
+	query, args, err := applyFilter(psql.Select("COUNT(*)").From(gameTableName), filter, "").ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	if err = r.db.QueryRow(ctx, query, args...).Scan(&total); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// ListFacets считает игры по значениям каждого фасета. Фильтр самого фасета
+// при подсчёте не применяется, чтобы были видны альтернативные значения.
+func (r *repository) ListFacets(ctx context.Context, filter ListFilter) (Facets, error) {
+	facets := make(Facets, len(facetFields))
+	for _, field := range facetFields {
+		def := facetDefs[field]
+
+		builder := psql.
+			Select(def.value+"::TEXT AS value", "COUNT(*) AS count").
+			From(def.from).
+			Where(def.where).
+			GroupBy(def.value).
+			OrderBy(def.orderBy)
+
+		query, args, err := applyFilter(builder, filter, field).ToSql()
 		if err != nil {
 			return nil, err
 		}
-		games = append(games, game)
+
+		var values []FacetValue
+		if err = pgxscan.Select(ctx, r.db, &values, query, args...); err != nil {
+			return nil, err
+		}
+
+		facets[field] = values
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
+	return facets, nil
+}
+
+// applyFilter добавляет условия фильтра к запросу, пропуская фасет skip.
+func applyFilter(builder squirrel.SelectBuilder, filter ListFilter, skip string) squirrel.SelectBuilder {
+	if filter.Query != "" {
+		builder = builder.Where(searchVector+" @@ plainto_tsquery('russian', ?)", filter.Query)
+	}
+	if len(filter.Genres) > 0 && skip != FacetGenre {
+		builder = builder.Where(squirrel.Eq{"genre": filter.Genres})
+	}
+	if filter.Players > 0 && skip != FacetPlayers {
+		builder = builder.Where(
+			"min_players <= ? AND (max_players IS NULL OR max_players >= ?)",
+			filter.Players, filter.Players,
+		)
+	}
+	if filter.MaxTime > 0 && skip != FacetMaxTime {
+		builder = builder.Where("COALESCE(max_play_time, min_play_time) <= ?", filter.MaxTime)
+	}
+	if filter.PlayerAge > 0 && skip != FacetPlayerAge {
+		builder = builder.Where(squirrel.LtOrEq{"min_age": filter.PlayerAge})
+	}
+	if skip != FacetComplexityLevel {
+		if filter.ComplexityMin > 0 {
+			builder = builder.Where(squirrel.GtOrEq{"difficulty_level": filter.ComplexityMin})
+		}
+		if filter.ComplexityMax > 0 {
+			builder = builder.Where(squirrel.LtOrEq{"difficulty_level": filter.ComplexityMax})
+		}
 	}
 
-	return games, nil
+	legacy := []struct {
+		facet  string
+		column string
+		values []string
+	}{
+		{FacetAge, "age", filter.Ages},
+		{FacetPerson, "person", filter.Persons},
+		{FacetAvgTime, "avg_time", filter.AvgTimes},
+		{FacetComplexity, "difficulty", filter.Difficulties},
+	}
+	for _, f := range legacy {
+		if len(f.values) > 0 && skip != f.facet {
+			builder = builder.Where(squirrel.Eq{f.column: f.values})
+		}
+	}
+
+	return builder
 }
 
-func (r *repository) getGameById(ctx context.Context, id int64) (Game, error) {
+func (r *repository) GetGameByID(ctx context.Context, id int64) (Game, error) {
 	query, args, err := psql.
-		Select("id", "title", "description", "genre", "age", "person", "avg_time", "difficulty", "image", "rules").
+		Select(selectColumns...).
 		From(gameTableName).
 		Where(squirrel.Eq{"id": id}).
 		ToSql()
@@ -80,13 +379,13 @@ func (r *repository) getGameById(ctx context.Context, id int64) (Game, error) {
 	return game, nil
 }
 
-func (r *repository) getGamesByIds(ctx context.Context, ids []int64) ([]Game, error) {
+func (r *repository) GetGamesByIDs(ctx context.Context, ids []int64) ([]Game, error) {
 	if len(ids) == 0 {
 		return nil, ErrEmptyIDs
 	}
 
 	query, args, err := psql.
-		Select("id", "title", "description", "genre", "age", "person", "avg_time", "difficulty", "image", "rules").
+		Select(selectColumns...).
 		From(gameTableName).
 		Where(squirrel.Eq{"id": ids}).
 		ToSql()
@@ -121,11 +420,47 @@ func (r *repository) getGamesByIds(ctx context.Context, ids []int64) ([]Game, er
 	return games, nil
 }
 
-func (r *repository) createGame(ctx context.Context, game Game) (int64, error) {
+// EachGame вызывает fn для каждой игры каталога по порядку ID. Строки читаются
+// из курсора по одной, весь каталог в памяти не держится.
+func (r *repository) EachGame(ctx context.Context, fn func(Game) error) error {
+	query, args, err := psql.
+		Select(selectColumns...).
+		From(gameTableName).
+		OrderBy("id ASC").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	scanner := pgxscan.NewRowScanner(rows)
+	for rows.Next() {
+		var game Game
+		if err = scanner.Scan(&game); err != nil {
+			return err
+		}
+		if err = fn(game); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *repository) CreateGame(ctx context.Context, game Game) (int64, error) {
 	query, args, err := psql.
 		Insert(gameTableName).
-		Columns("title", "description", "genre", "age", "person", "avg_time", "difficulty", "image", "rules").
-		Values(game.Title, game.Description, game.Genre, game.Age, game.Person, game.AvgTime, game.Difficulty, game.Image, game.Rules).
+		Columns(gameColumns[1:]...).
+		Values(
+			game.Title, game.Description, game.Genre, game.Age, game.Person, game.AvgTime, game.Difficulty, game.Image, game.Rules,
+			game.MinPlayers, game.MaxPlayers, game.MinPlayTime, game.MaxPlayTime, game.MinAge, game.DifficultyLevel,
+			game.ExternalID,
+		).
 		Suffix("RETURNING id").
 		ToSql()
 	if err != nil {
@@ -141,12 +476,116 @@ func (r *repository) createGame(ctx context.Context, game Game) (int64, error) {
 	return id, nil
 }
 
-func (r *repository) updateGame(ctx context.Context, game Game) error {
+// UpsertGame создаёт игру или обновляет игру с тем же external_id.
+// Характеристики, которых нет в game, остаются прежними. Второе значение
+// сообщает, была ли игра создана.
+func (r *repository) UpsertGame(ctx context.Context, game Game) (int64, bool, error) {
+	query, args, err := psql.
+		Insert(gameTableName).
+		Columns(gameColumns[1:]...).
+		Values(
+			game.Title, game.Description, game.Genre, game.Age, game.Person, game.AvgTime, game.Difficulty, game.Image, game.Rules,
+			game.MinPlayers, game.MaxPlayers, game.MinPlayTime, game.MaxPlayTime, game.MinAge, game.DifficultyLevel,
+			game.ExternalID,
+		).
+		Suffix("ON CONFLICT (external_id) DO UPDATE SET " + upsertSet + " RETURNING id, xmax = 0").
+		ToSql()
+	if err != nil {
+		return 0, false, err
+	}
+
+	var (
+		id      int64
+		created bool
+	)
+	if err = r.db.QueryRow(ctx, query, args...).Scan(&id, &created); err != nil {
+		return 0, false, err
+	}
+
+	return id, created, nil
+}
+
+func (r *repository) GetGameIDsByExternalIDs(ctx context.Context, externalIDs []string) (map[string]int64, error) {
+	ids := make(map[string]int64, len(externalIDs))
+	if len(externalIDs) == 0 {
+		return ids, nil
+	}
+
+	query, args, err := psql.
+		Select("external_id", "id").
+		From(gameTableName).
+		Where(squirrel.Eq{"external_id": externalIDs}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			externalID string
+			id         int64
+		)
+		if err = rows.Scan(&externalID, &id); err != nil {
+			return nil, err
+		}
+		ids[externalID] = id
+	}
+
+	return ids, rows.Err()
+}
+
+// MatchTitles ищет игры с похожим названием: совпадающие без учёта регистра
+// и похожие по триграммам сильнее порога pg_trgm.similarity_threshold.
+// Результат отсортирован по убыванию сходства.
+func (r *repository) MatchTitles(ctx context.Context, title string, limit int) ([]TitleMatch, error) {
+	query, args, err := psql.
+		Select("id", "title").
+		Column("similarity("+titleTrgm+", lower(?)) AS score", title).
+		Column(titleTrgm+" = lower(?) AS exact", title).
+		From(gameTableName).
+		Where(squirrel.Or{
+			squirrel.Expr(titleTrgm+" = lower(?)", title),
+			squirrel.Expr(titleTrgm+" % lower(?)", title),
+		}).
+		OrderBy("exact DESC", "score DESC", "id ASC").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []TitleMatch
+	if err = pgxscan.Select(ctx, r.db, &matches, query, args...); err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+func (r *repository) UpdateGame(ctx context.Context, game Game) error {
 	query, args, err := psql.
 		Update(gameTableName).
 		Set("title", game.Title).
 		Set("description", game.Description).
 		Set("genre", game.Genre).
+		Set("age", game.Age).
+		Set("person", game.Person).
+		Set("avg_time", game.AvgTime).
+		Set("difficulty", game.Difficulty).
+		Set("image", game.Image).
+		Set("rules", game.Rules).
+		Set("min_players", game.MinPlayers).
+		Set("max_players", game.MaxPlayers).
+		Set("min_play_time", game.MinPlayTime).
+		Set("max_play_time", game.MaxPlayTime).
+		Set("min_age", game.MinAge).
+		Set("difficulty_level", game.DifficultyLevel).
 		Set("updated_at", squirrel.Expr("NOW()")).
 		Where(squirrel.Eq{"id": game.ID}).
 		ToSql()
@@ -166,7 +605,7 @@ func (r *repository) updateGame(ctx context.Context, game Game) error {
 	return nil
 }
 
-func (r *repository) deleteGame(ctx context.Context, id int64) error {
+func (r *repository) DeleteGame(ctx context.Context, id int64) error {
 	query, args, err := psql.
 		Delete(gameTableName).
 		Where(squirrel.Eq{"id": id}).
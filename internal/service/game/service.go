@@ -2,40 +2,168 @@ package game
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
 
-	pgx "github.com/jackc/pgx/v5"
+	"github.com/board-box/backend/internal/pagination"
 )
 
+// Ограничения длины совпадают с размерами колонок таблицы game.
+const (
+	maxTitleLen     = 255
+	maxAttributeLen = 100
+)
+
+var ErrInvalidGame = errors.New("invalid game")
+
 type Service struct {
-	repo *repository
+	repo Repository
 }
 
-func NewService(db *pgx.Conn) *Service {
+func NewService(repo Repository) *Service {
 	return &Service{
-		repo: newRepository(db),
+		repo: repo,
+	}
+}
+
+func (s *Service) ListGames(ctx context.Context, filter ListFilter, page pagination.Params) ([]Game, pagination.Meta, error) {
+	if err := page.Validate(); err != nil {
+		return nil, pagination.Meta{}, err
 	}
+	return s.repo.ListGames(ctx, filter, page)
 }
 
-func (s *Service) ListGames(ctx context.Context) ([]Game, error) {
-	return s.repo.listGames(ctx)
+func (s *Service) ListFacets(ctx context.Context, filter ListFilter) (Facets, error) {
+	return s.repo.ListFacets(ctx, filter)
 }
 
 func (s *Service) GetGame(ctx context.Context, id int64) (Game, error) {
-	return s.repo.getGameById(ctx, id)
+	return s.repo.GetGameByID(ctx, id)
 }
 
 func (s *Service) GetGames(ctx context.Context, ids []int64) ([]Game, error) {
-	return s.repo.getGamesByIds(ctx, ids)
+	return s.repo.GetGamesByIDs(ctx, ids)
 }
 
 func (s *Service) CreateGame(ctx context.Context, game Game) (int64, error) {
-	return s.repo.createGame(ctx, game)
+	fillAttributes(&game)
+	if err := validateGame(game); err != nil {
+		return 0, err
+	}
+	return s.repo.CreateGame(ctx, game)
+}
+
+// ImportGame сохраняет игру из внешнего источника: игра с ExternalID
+// обновляется, если уже была импортирована, иначе создаётся. При обновлении
+// пустые поля game не затирают сохранённые. Второе значение сообщает, была ли
+// игра создана.
+func (s *Service) ImportGame(ctx context.Context, game Game) (int64, bool, error) {
+	game, err := PrepareGame(game)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if game.ExternalID == nil {
+		id, err := s.repo.CreateGame(ctx, game)
+		return id, err == nil, err
+	}
+	return s.repo.UpsertGame(ctx, game)
+}
+
+// ExportGames передаёт fn все игры каталога по одной.
+func (s *Service) ExportGames(ctx context.Context, fn func(Game) error) error {
+	return s.repo.EachGame(ctx, fn)
+}
+
+// FindByExternalIDs возвращает ID уже импортированных игр по внешним ID.
+func (s *Service) FindByExternalIDs(ctx context.Context, externalIDs []string) (map[string]int64, error) {
+	return s.repo.GetGameIDsByExternalIDs(ctx, externalIDs)
+}
+
+// MatchTitle возвращает до limit игр с таким же или похожим названием;
+// совпадающие точно идут первыми.
+func (s *Service) MatchTitle(ctx context.Context, title string, limit int) ([]TitleMatch, error) {
+	return s.repo.MatchTitles(ctx, title, limit)
+}
+
+// PrepareGame дополняет характеристики игры так же, как при сохранении, и
+// проверяет её, ничего не записывая в базу.
+func PrepareGame(game Game) (Game, error) {
+	fillAttributes(&game)
+	if err := validateGame(game); err != nil {
+		return Game{}, err
+	}
+	return game, nil
 }
 
 func (s *Service) UpdateGame(ctx context.Context, game Game) error {
-	return s.repo.updateGame(ctx, game)
+	fillAttributes(&game)
+	if err := validateGame(game); err != nil {
+		return err
+	}
+	return s.repo.UpdateGame(ctx, game)
+}
+
+func (s *Service) PatchGame(ctx context.Context, id int64, patch GamePatch) (Game, error) {
+	game, err := s.repo.GetGameByID(ctx, id)
+	if err != nil {
+		return Game{}, err
+	}
+
+	patch.apply(&game)
+	if err = s.UpdateGame(ctx, game); err != nil {
+		return Game{}, err
+	}
+
+	return s.repo.GetGameByID(ctx, id)
 }
 
 func (s *Service) DeleteGame(ctx context.Context, id int64) error {
-	return s.repo.deleteGame(ctx, id)
+	return s.repo.DeleteGame(ctx, id)
+}
+
+func validateGame(game Game) error {
+	if strings.TrimSpace(game.Title) == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidGame)
+	}
+	if game.MinPlayers != nil && game.MaxPlayers != nil && *game.MaxPlayers < *game.MinPlayers {
+		return fmt.Errorf("%w: max_players is less than min_players", ErrInvalidGame)
+	}
+	if game.MinPlayTime != nil && game.MaxPlayTime != nil && *game.MaxPlayTime < *game.MinPlayTime {
+		return fmt.Errorf("%w: max_play_time is less than min_play_time", ErrInvalidGame)
+	}
+	if game.DifficultyLevel != nil && (*game.DifficultyLevel < minDifficulty || *game.DifficultyLevel > maxDifficulty) {
+		return fmt.Errorf("%w: complexity_level must be between 1 and 5", ErrInvalidGame)
+	}
+
+	var externalID string
+	if game.ExternalID != nil {
+		externalID = *game.ExternalID
+		if strings.TrimSpace(externalID) == "" {
+			return fmt.Errorf("%w: external_id is empty", ErrInvalidGame)
+		}
+	}
+
+	limits := []struct {
+		field string
+		value string
+		max   int
+	}{
+		{"title", game.Title, maxTitleLen},
+		{"genre", game.Genre, maxAttributeLen},
+		{"age", game.Age, maxAttributeLen},
+		{"person", game.Person, maxAttributeLen},
+		{"avg_time", game.AvgTime, maxAttributeLen},
+		{"complexity", game.Difficulty, maxAttributeLen},
+		{"external_id", externalID, maxAttributeLen},
+	}
+	for _, l := range limits {
+		if utf8.RuneCountInString(l.value) > l.max {
+			return fmt.Errorf("%w: %s is longer than %d characters", ErrInvalidGame, l.field, l.max)
+		}
+	}
+
+	return nil
 }
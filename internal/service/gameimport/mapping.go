@@ -0,0 +1,210 @@
+package gameimport
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/board-box/backend/internal/service/game"
+)
+
+// Поля игры, с которыми сопоставляются колонки файла. Названия совпадают с
+// JSON-полями game.Game, кроме служебных play_time и bgg_id.
+const (
+	fieldTitle           = "title"
+	fieldDescription     = "description"
+	fieldGenre           = "genre"
+	fieldAge             = "age"
+	fieldPerson          = "person"
+	fieldAvgTime         = "avg_time"
+	fieldComplexity      = "complexity"
+	fieldImage           = "image"
+	fieldRules           = "rules"
+	fieldMinPlayers      = "min_players"
+	fieldMaxPlayers      = "max_players"
+	fieldMinPlayTime     = "min_play_time"
+	fieldMaxPlayTime     = "max_play_time"
+	fieldMinAge          = "min_age"
+	fieldComplexityLevel = "complexity_level"
+	fieldExternalID      = "external_id"
+
+	// fieldPlayTime задаёт и минимальное, и максимальное время, если они не указаны отдельно
+	fieldPlayTime = "play_time"
+	// fieldBGGID — ID игры на BoardGameGeek, превращается в external_id "bgg:<id>"
+	fieldBGGID = "bgg_id"
+
+	skipColumn = "-"
+	bggPrefix  = "bgg:"
+
+	// maxNumber отсекает заведомо ошибочные значения до записи в SMALLINT-колонки
+	maxNumber = 10000
+)
+
+var fields = map[string]bool{
+	fieldTitle: true, fieldDescription: true, fieldGenre: true, fieldAge: true, fieldPerson: true,
+	fieldAvgTime: true, fieldComplexity: true, fieldImage: true, fieldRules: true,
+	fieldMinPlayers: true, fieldMaxPlayers: true, fieldMinPlayTime: true, fieldMaxPlayTime: true,
+	fieldMinAge: true, fieldComplexityLevel: true, fieldExternalID: true, fieldPlayTime: true, fieldBGGID: true,
+}
+
+// aliases сопоставляют распространённые названия колонок (в том числе из
+// выгрузок BGG) с полями игры. Ключи нормализованы функцией normalizeColumn.
+var aliases = map[string]string{
+	"name":              fieldTitle,
+	"primary_name":      fieldTitle,
+	"objectname":        fieldTitle,
+	"game":              fieldTitle,
+	"desc":              fieldDescription,
+	"category":          fieldGenre,
+	"boardgamecategory": fieldGenre,
+	"players":           fieldPerson,
+	"time":              fieldAvgTime,
+	"duration":          fieldAvgTime,
+	"difficulty":        fieldComplexity,
+	"difficulty_level":  fieldComplexityLevel,
+	"weight":            fieldComplexityLevel,
+	"avgweight":         fieldComplexityLevel,
+	"averageweight":     fieldComplexityLevel,
+	"image_url":         fieldImage,
+	"thumbnail":         fieldImage,
+	"minplayers":        fieldMinPlayers,
+	"maxplayers":        fieldMaxPlayers,
+	"minplaytime":       fieldMinPlayTime,
+	"maxplaytime":       fieldMaxPlayTime,
+	"playingtime":       fieldPlayTime,
+	"playing_time":      fieldPlayTime,
+	"minage":            fieldMinAge,
+	"objectid":          fieldBGGID,
+	"bggid":             fieldBGGID,
+}
+
+// mapper сопоставляет колонки файла с полями игры.
+type mapper struct {
+	overrides map[string]string
+}
+
+func newMapper(mapping map[string]string) (*mapper, error) {
+	m := &mapper{overrides: make(map[string]string, len(mapping))}
+	for column, field := range mapping {
+		if field != skipColumn && !fields[field] {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidMapping, field)
+		}
+		m.overrides[normalizeColumn(column)] = field
+	}
+	return m, nil
+}
+
+// field возвращает поле игры для колонки или false, если колонка не используется.
+// Для колонок, пропущенных явно, возвращается skipColumn.
+func (m *mapper) field(column string) (string, bool) {
+	column = normalizeColumn(column)
+
+	if field, ok := m.overrides[column]; ok {
+		return field, field != skipColumn
+	}
+	if fields[column] {
+		return column, true
+	}
+	field, ok := aliases[column]
+	return field, ok
+}
+
+func normalizeColumn(column string) string {
+	column = strings.TrimPrefix(column, utf8BOM)
+	column = strings.ToLower(strings.TrimSpace(column))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(column)
+}
+
+// toGame собирает игру из значений полей. Числовые поля должны быть числами;
+// ноль, как принято в BGG, означает «не указано».
+func toGame(values map[string]string) (game.Game, error) {
+	g := game.Game{
+		Title:       values[fieldTitle],
+		Description: values[fieldDescription],
+		Genre:       values[fieldGenre],
+		Age:         values[fieldAge],
+		Person:      values[fieldPerson],
+		AvgTime:     values[fieldAvgTime],
+		Difficulty:  values[fieldComplexity],
+		Image:       values[fieldImage],
+		Rules:       values[fieldRules],
+	}
+
+	ints := []struct {
+		field string
+		dst   **int
+	}{
+		{fieldMinPlayers, &g.MinPlayers},
+		{fieldMaxPlayers, &g.MaxPlayers},
+		{fieldMinPlayTime, &g.MinPlayTime},
+		{fieldMaxPlayTime, &g.MaxPlayTime},
+		{fieldMinAge, &g.MinAge},
+	}
+	for _, f := range ints {
+		v, err := parseInt(f.field, values[f.field])
+		if err != nil {
+			return game.Game{}, err
+		}
+		*f.dst = v
+	}
+
+	playTime, err := parseInt(fieldPlayTime, values[fieldPlayTime])
+	if err != nil {
+		return game.Game{}, err
+	}
+	if playTime != nil && g.MinPlayTime == nil && g.MaxPlayTime == nil {
+		g.MinPlayTime, g.MaxPlayTime = playTime, playTime
+	}
+
+	if g.DifficultyLevel, err = parseFloat(fieldComplexityLevel, values[fieldComplexityLevel]); err != nil {
+		return game.Game{}, err
+	}
+
+	switch {
+	case values[fieldExternalID] != "":
+		externalID := values[fieldExternalID]
+		g.ExternalID = &externalID
+	case values[fieldBGGID] != "":
+		id, err := strconv.ParseInt(values[fieldBGGID], 10, 64)
+		if err != nil || id <= 0 {
+			return game.Game{}, fmt.Errorf("%s: %q is not a BGG id", fieldBGGID, values[fieldBGGID])
+		}
+		externalID := bggPrefix + strconv.FormatInt(id, 10)
+		g.ExternalID = &externalID
+	}
+
+	return g, nil
+}
+
+func parseInt(field, s string) (*int, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil || v < 0 {
+		return nil, fmt.Errorf("%s: %q is not a non-negative integer", field, s)
+	}
+	if v > maxNumber {
+		return nil, fmt.Errorf("%s: %d is out of range", field, v)
+	}
+	if v == 0 {
+		return nil, nil
+	}
+	return &v, nil
+}
+
+func parseFloat(field, s string) (*float64, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	v, err := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+	if err != nil || v < 0 {
+		return nil, fmt.Errorf("%s: %q is not a non-negative number", field, s)
+	}
+	if v == 0 {
+		return nil, nil
+	}
+	return &v, nil
+}
@@ -0,0 +1,113 @@
+package gameimport
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// Format — формат файла импорта.
+type Format string
+
+const (
+	// FormatCSV — таблица с заголовком, колонки сопоставляются с полями игры.
+	FormatCSV Format = "csv"
+	// FormatJSONL — JSON Lines: по объекту игры на строку. JSON-массив объектов
+	// тоже принимается.
+	FormatJSONL Format = "jsonl"
+	// FormatBGG — выгрузка BoardGameGeek XML API2 (/xmlapi2/thing).
+	FormatBGG Format = "bgg"
+)
+
+// Formats — поддерживаемые форматы в порядке вывода в справке.
+var Formats = []Format{FormatCSV, FormatJSONL, FormatBGG}
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionError  = "error"
+)
+
+var (
+	ErrUnknownFormat  = errors.New("unknown import format")
+	ErrInvalidMapping = errors.New("invalid field mapping")
+	ErrInvalidFile    = errors.New("invalid import file")
+)
+
+// Options управляет импортом.
+type Options struct {
+	Format Format
+	// DryRun проверяет файл и показывает, что будет сделано, ничего не записывая
+	DryRun bool
+	// Mapping переопределяет сопоставление колонок файла с полями игры:
+	// колонка -> поле. Поле "-" означает, что колонку нужно пропустить.
+	Mapping map[string]string
+}
+
+// Report — итог импорта. При DryRun описывает, что произойдёт при настоящем импорте.
+type Report struct {
+	Format  Format `json:"format"`
+	DryRun  bool   `json:"dry_run"`
+	Total   int    `json:"total"`
+	Created int    `json:"created"`
+	Updated int    `json:"updated"`
+	Failed  int    `json:"failed"`
+	// IgnoredColumns — колонки файла, которые не сопоставлены ни с одним полем
+	IgnoredColumns []string    `json:"ignored_columns,omitempty"`
+	Rows           []RowResult `json:"rows"`
+}
+
+// RowResult — результат по одной записи файла.
+type RowResult struct {
+	// Row — номер строки в CSV и JSONL или номер элемента item в XML
+	Row        int    `json:"row"`
+	Title      string `json:"title,omitempty"`
+	ExternalID string `json:"external_id,omitempty"`
+	Action     string `json:"action"`
+	GameID     int64  `json:"game_id,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ParseFormat разбирает название формата. Пустое значение определяется по
+// расширению файла.
+func ParseFormat(name, filename string) (Format, error) {
+	if name == "" {
+		switch strings.ToLower(filepath.Ext(filename)) {
+		case ".csv":
+			return FormatCSV, nil
+		case ".jsonl", ".ndjson", ".json":
+			return FormatJSONL, nil
+		case ".xml":
+			return FormatBGG, nil
+		}
+		return "", ErrUnknownFormat
+	}
+
+	switch f := Format(strings.ToLower(name)); f {
+	case FormatCSV, FormatJSONL, FormatBGG:
+		return f, nil
+	case "json":
+		return FormatJSONL, nil
+	case "xml":
+		return FormatBGG, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+// ParseMapping разбирает сопоставление вида "Name=title,Weight=complexity_level".
+func ParseMapping(s string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		column, field, ok := strings.Cut(pair, "=")
+		column, field = strings.TrimSpace(column), strings.TrimSpace(field)
+		if !ok || column == "" || field == "" {
+			return nil, ErrInvalidMapping
+		}
+		mapping[column] = field
+	}
+	return mapping, nil
+}
@@ -0,0 +1,334 @@
+package gameimport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxLineSize ограничивает длину строки JSONL: описания игр бывают длинными.
+	maxLineSize = 4 << 20
+
+	utf8BOM = "\ufeff"
+)
+
+// record — одна запись файла: значения полей игры или ошибка разбора.
+type record struct {
+	row    int
+	values map[string]string
+	err    error
+}
+
+// parse читает все записи файла. Ошибки отдельных записей попадают в
+// record.err, ошибка возвращается, только если файл нельзя читать дальше.
+// Второе значение — колонки файла, не сопоставленные с полями игры.
+func parse(r io.Reader, format Format, m *mapper) ([]record, []string, error) {
+	switch format {
+	case FormatCSV:
+		return parseCSV(r, m)
+	case FormatJSONL:
+		return parseJSONL(r, m)
+	case FormatBGG:
+		records, err := parseBGG(r)
+		return records, nil, err
+	}
+	return nil, nil, ErrUnknownFormat
+}
+
+func parseCSV(r io.Reader, m *mapper) ([]record, []string, error) {
+	br := bufio.NewReader(r)
+
+	cr := csv.NewReader(br)
+	cr.FieldsPerRecord = -1
+	cr.Comma = sniffDelimiter(br)
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, fmt.Errorf("%w: empty file", ErrInvalidFile)
+		}
+		return nil, nil, fmt.Errorf("%w: header: %v", ErrInvalidFile, err)
+	}
+
+	columns := make([]string, len(header))
+	var ignored []string
+	for i, column := range header {
+		field, ok := m.field(column)
+		switch {
+		case ok:
+			columns[i] = field
+		case field != skipColumn:
+			ignored = append(ignored, strings.TrimSpace(strings.TrimPrefix(column, utf8BOM)))
+		}
+	}
+
+	var records []record
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			records = append(records, record{row: parseErr.StartLine, err: parseErr.Err})
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		line, _ := cr.FieldPos(0)
+		if len(row) != len(header) {
+			records = append(records, record{row: line, err: fmt.Errorf("expected %d columns, got %d", len(header), len(row))})
+			continue
+		}
+
+		values := make(map[string]string, len(row))
+		for i, v := range row {
+			if columns[i] != "" {
+				values[columns[i]] = strings.TrimSpace(v)
+			}
+		}
+		records = append(records, record{row: line, values: values})
+	}
+
+	return records, ignored, nil
+}
+
+// sniffDelimiter выбирает ";" для таблиц, сохранённых из Excel с русской
+// локалью, и "," во всех остальных случаях.
+func sniffDelimiter(br *bufio.Reader) rune {
+	line, _ := br.Peek(br.Size())
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	if bytes.Count(line, []byte(";")) > bytes.Count(line, []byte(",")) {
+		return ';'
+	}
+	return ','
+}
+
+func parseJSONL(r io.Reader, m *mapper) ([]record, []string, error) {
+	br := bufio.NewReader(r)
+	ignored := make(map[string]bool)
+
+	first, err := firstByte(br)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: empty file", ErrInvalidFile)
+	}
+
+	var records []record
+	if first == '[' {
+		dec := json.NewDecoder(br)
+		if _, err = dec.Token(); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+		for row := 1; dec.More(); row++ {
+			var raw json.RawMessage
+			if err = dec.Decode(&raw); err != nil {
+				return nil, nil, fmt.Errorf("%w: element %d: %v", ErrInvalidFile, row, err)
+			}
+			records = append(records, jsonRecord(row, raw, m, ignored))
+		}
+	} else {
+		sc := bufio.NewScanner(br)
+		sc.Buffer(make([]byte, 64<<10), maxLineSize)
+		for line := 1; sc.Scan(); line++ {
+			if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+				continue
+			}
+			records = append(records, jsonRecord(line, sc.Bytes(), m, ignored))
+		}
+		if err = sc.Err(); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+	}
+
+	return records, sortedKeys(ignored), nil
+}
+
+func jsonRecord(row int, data []byte, m *mapper, ignored map[string]bool) record {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var obj map[string]any
+	if err := dec.Decode(&obj); err != nil {
+		return record{row: row, err: fmt.Errorf("invalid JSON object: %v", err)}
+	}
+
+	values := make(map[string]string, len(obj))
+	for key, v := range obj {
+		field, ok := m.field(key)
+		if !ok {
+			if field != skipColumn {
+				ignored[key] = true
+			}
+			continue
+		}
+
+		s, err := jsonString(v)
+		if err != nil {
+			return record{row: row, err: fmt.Errorf("%s: %v", key, err)}
+		}
+		values[field] = s
+	}
+
+	return record{row: row, values: values}
+}
+
+// jsonString приводит значение JSON к строке. Массив строк (например,
+// несколько жанров) склеивается через запятую.
+func jsonString(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return strings.TrimSpace(v), nil
+	case json.Number:
+		return v.String(), nil
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return "", errors.New("only arrays of strings are supported")
+			}
+			parts = append(parts, strings.TrimSpace(s))
+		}
+		return strings.Join(parts, ", "), nil
+	}
+	return "", fmt.Errorf("unsupported value of type %T", v)
+}
+
+// firstByte пропускает BOM и пробелы и возвращает первый значимый байт, не
+// извлекая его из br.
+func firstByte(br *bufio.Reader) (byte, error) {
+	if bom, _ := br.Peek(len(utf8BOM)); string(bom) == utf8BOM {
+		_, _ = br.Discard(len(utf8BOM))
+	}
+
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			return b, br.UnreadByte()
+		}
+	}
+}
+
+// bggItem — элемент item из ответа BGG XML API2 /thing.
+type bggItem struct {
+	ID          string    `xml:"id,attr"`
+	Thumbnail   string    `xml:"thumbnail"`
+	Image       string    `xml:"image"`
+	Names       []bggName `xml:"name"`
+	Description string    `xml:"description"`
+	MinPlayers  bggValue  `xml:"minplayers"`
+	MaxPlayers  bggValue  `xml:"maxplayers"`
+	PlayingTime bggValue  `xml:"playingtime"`
+	MinPlayTime bggValue  `xml:"minplaytime"`
+	MaxPlayTime bggValue  `xml:"maxplaytime"`
+	MinAge      bggValue  `xml:"minage"`
+	Links       []bggName `xml:"link"`
+	Weight      bggValue  `xml:"statistics>ratings>averageweight"`
+}
+
+type bggName struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type bggValue struct {
+	Value string `xml:"value,attr"`
+}
+
+// parseBGG читает выгрузку BGG. Сопоставление полей в ней фиксировано.
+func parseBGG(r io.Reader) ([]record, error) {
+	dec := xml.NewDecoder(r)
+
+	var records []record
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "item" {
+			continue
+		}
+
+		var item bggItem
+		if err = dec.DecodeElement(&item, &start); err != nil {
+			return nil, fmt.Errorf("%w: item %d: %v", ErrInvalidFile, len(records)+1, err)
+		}
+		records = append(records, record{row: len(records) + 1, values: item.values()})
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: no item elements found", ErrInvalidFile)
+	}
+
+	return records, nil
+}
+
+func (i bggItem) values() map[string]string {
+	values := map[string]string{
+		fieldBGGID:           strings.TrimSpace(i.ID),
+		fieldDescription:     strings.TrimSpace(html.UnescapeString(i.Description)),
+		fieldImage:           strings.TrimSpace(i.Image),
+		fieldMinPlayers:      i.MinPlayers.Value,
+		fieldMaxPlayers:      i.MaxPlayers.Value,
+		fieldPlayTime:        i.PlayingTime.Value,
+		fieldMinPlayTime:     i.MinPlayTime.Value,
+		fieldMaxPlayTime:     i.MaxPlayTime.Value,
+		fieldMinAge:          i.MinAge.Value,
+		fieldComplexityLevel: i.Weight.Value,
+	}
+	if values[fieldImage] == "" {
+		values[fieldImage] = strings.TrimSpace(i.Thumbnail)
+	}
+
+	for _, name := range i.Names {
+		if name.Type == "primary" {
+			values[fieldTitle] = strings.TrimSpace(name.Value)
+			break
+		}
+		if values[fieldTitle] == "" {
+			values[fieldTitle] = strings.TrimSpace(name.Value)
+		}
+	}
+
+	for _, link := range i.Links {
+		if link.Type == "boardgamecategory" {
+			values[fieldGenre] = strings.TrimSpace(link.Value)
+			break
+		}
+	}
+
+	return values
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,186 @@
+package gameimport
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCSV(t *testing.T) {
+	const file = "\ufeffName,Category,Players,MinAge,Weight,objectid,Notes\n" +
+		"Каркассон,Стратегия,2-5,7,\"1,9\",822,любимая\n" +
+		"Серп,Стратегия,1-5\n" +
+		"Манчкин,Карточная,3-6,12,1.5,,\n"
+
+	records, ignored, err := parse(strings.NewReader(file), FormatCSV, mustMapper(t, nil))
+	if err != nil {
+		t.Fatalf("parse() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(ignored, []string{"Notes"}) {
+		t.Errorf("ignored = %v, want [Notes]", ignored)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+
+	want := map[string]string{
+		fieldTitle: "Каркассон", fieldGenre: "Стратегия", fieldPerson: "2-5",
+		fieldMinAge: "7", fieldComplexityLevel: "1,9", fieldBGGID: "822",
+	}
+	if records[0].row != 2 || !reflect.DeepEqual(records[0].values, want) {
+		t.Errorf("record[0] = %+v, want row 2 with %v", records[0], want)
+	}
+	if records[1].row != 3 || records[1].err == nil {
+		t.Errorf("record[1] = %+v, want a column count error on row 3", records[1])
+	}
+	if records[2].row != 4 || records[2].values[fieldTitle] != "Манчкин" {
+		t.Errorf("record[2] = %+v, want Манчкин on row 4", records[2])
+	}
+}
+
+func TestParseCSVSemicolonAndMapping(t *testing.T) {
+	const file = "Игра;Жанр;Комментарий\nСерп;Стратегия;отличная\n"
+
+	m := mustMapper(t, map[string]string{"Игра": fieldTitle, "Жанр": fieldGenre, "Комментарий": skipColumn})
+	records, ignored, err := parse(strings.NewReader(file), FormatCSV, m)
+	if err != nil {
+		t.Fatalf("parse() error = %v", err)
+	}
+
+	if len(ignored) != 0 {
+		t.Errorf("ignored = %v, want none", ignored)
+	}
+	want := map[string]string{fieldTitle: "Серп", fieldGenre: "Стратегия"}
+	if len(records) != 1 || !reflect.DeepEqual(records[0].values, want) {
+		t.Fatalf("records = %+v, want one with %v", records, want)
+	}
+}
+
+func TestParseJSONL(t *testing.T) {
+	const file = `{"title": "Серп", "genre": ["Стратегия", "Экономика"], "min_players": 1, "id": 7}
+
+{"title": "Каркассон", "external_id": "hobbygames:1"}
+{"title": "broken"
+{"title": "Азул", "min_players": {"value": 2}}
+`
+
+	records, ignored, err := parse(strings.NewReader(file), FormatJSONL, mustMapper(t, nil))
+	if err != nil {
+		t.Fatalf("parse() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(ignored, []string{"id"}) {
+		t.Errorf("ignored = %v, want [id]", ignored)
+	}
+	if len(records) != 4 {
+		t.Fatalf("got %d records, want 4", len(records))
+	}
+
+	want := map[string]string{fieldTitle: "Серп", fieldGenre: "Стратегия, Экономика", fieldMinPlayers: "1"}
+	if !reflect.DeepEqual(records[0].values, want) {
+		t.Errorf("record[0].values = %v, want %v", records[0].values, want)
+	}
+	if records[1].row != 3 || records[1].values[fieldExternalID] != "hobbygames:1" {
+		t.Errorf("record[1] = %+v, want external_id on line 3", records[1])
+	}
+	if records[2].row != 4 || records[2].err == nil {
+		t.Errorf("record[2] = %+v, want a JSON error on line 4", records[2])
+	}
+	if records[3].err == nil {
+		t.Errorf("record[3] = %+v, want an unsupported value error", records[3])
+	}
+}
+
+func TestParseJSONArray(t *testing.T) {
+	const file = "\ufeff [\n{\"title\": \"Серп\"},\n{\"title\": \"Азул\"}\n]"
+
+	records, _, err := parse(strings.NewReader(file), FormatJSONL, mustMapper(t, nil))
+	if err != nil {
+		t.Fatalf("parse() error = %v", err)
+	}
+	if len(records) != 2 || records[1].row != 2 || records[1].values[fieldTitle] != "Азул" {
+		t.Fatalf("records = %+v, want Серп and Азул", records)
+	}
+
+	if _, _, err = parse(strings.NewReader(`[{"title": "Серп"`), FormatJSONL, mustMapper(t, nil)); !errors.Is(err, ErrInvalidFile) {
+		t.Fatalf("parse() of a truncated array error = %v, want ErrInvalidFile", err)
+	}
+}
+
+func TestParseBGG(t *testing.T) {
+	f, err := os.Open("testdata/thing.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	records, _, err := parse(f, FormatBGG, mustMapper(t, nil))
+	if err != nil {
+		t.Fatalf("parse() error = %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+
+	g, err := toGame(records[0].values)
+	if err != nil {
+		t.Fatalf("toGame() error = %v", err)
+	}
+	if g.Title != "Carcassonne" || g.Genre != "Medieval" || *g.ExternalID != "bgg:822" {
+		t.Errorf("game = %+v, want Carcassonne/Medieval/bgg:822", g)
+	}
+	if g.Description != "Carcassonne is a tile-placement game.\n\nPlayers draw tiles & place followers." {
+		t.Errorf("description = %q", g.Description)
+	}
+	if *g.MinPlayers != 2 || *g.MaxPlayers != 5 || *g.MinPlayTime != 30 || *g.MaxPlayTime != 45 || *g.MinAge != 7 || *g.DifficultyLevel != 1.9 {
+		t.Errorf("numeric attributes = %+v", g)
+	}
+
+	// Без основного названия берётся первое, время — из playingtime, вес 0 — не указан
+	g, err = toGame(records[1].values)
+	if err != nil {
+		t.Fatalf("toGame() error = %v", err)
+	}
+	if g.Title != "Колонизаторы" || *g.MinPlayTime != 120 || *g.MaxPlayTime != 120 || g.DifficultyLevel != nil {
+		t.Errorf("game = %+v", g)
+	}
+
+	if _, _, err = parse(strings.NewReader("<items><item"), FormatBGG, mustMapper(t, nil)); !errors.Is(err, ErrInvalidFile) {
+		t.Fatalf("parse() of broken XML error = %v, want ErrInvalidFile", err)
+	}
+}
+
+func TestToGameErrors(t *testing.T) {
+	tests := []map[string]string{
+		{fieldTitle: "Серп", fieldMinPlayers: "два"},
+		{fieldTitle: "Серп", fieldMinAge: "-1"},
+		{fieldTitle: "Серп", fieldMaxPlayTime: "100000"},
+		{fieldTitle: "Серп", fieldComplexityLevel: "сложно"},
+		{fieldTitle: "Серп", fieldBGGID: "abc"},
+	}
+
+	for _, values := range tests {
+		if _, err := toGame(values); err == nil {
+			t.Errorf("toGame(%v) succeeded, want an error", values)
+		}
+	}
+}
+
+func TestMapperRejectsUnknownField(t *testing.T) {
+	if _, err := newMapper(map[string]string{"Name": "name"}); !errors.Is(err, ErrInvalidMapping) {
+		t.Fatalf("newMapper() error = %v, want ErrInvalidMapping", err)
+	}
+}
+
+func mustMapper(t *testing.T, mapping map[string]string) *mapper {
+	t.Helper()
+
+	m, err := newMapper(mapping)
+	if err != nil {
+		t.Fatalf("newMapper() error = %v", err)
+	}
+	return m
+}
@@ -0,0 +1,189 @@
+// Package gameimport наполняет каталог игр из файлов: CSV, JSON Lines и
+// выгрузок BoardGameGeek XML API2.
+package gameimport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/board-box/backend/internal/db"
+	"github.com/board-box/backend/internal/service/game"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Catalog — операции каталога, нужные импорту.
+type Catalog interface {
+	ImportGame(ctx context.Context, g game.Game) (int64, bool, error)
+	FindByExternalIDs(ctx context.Context, externalIDs []string) (map[string]int64, error)
+}
+
+type Service struct {
+	games Catalog
+	tx    db.TxManager
+}
+
+func NewService(games Catalog, tx db.TxManager) *Service {
+	return &Service{
+		games: games,
+		tx:    tx,
+	}
+}
+
+// Import читает файл и сохраняет игры. Игры с внешним ID обновляются, если
+// уже были импортированы раньше, остальные создаются. Записи с ошибками —
+// в том числе отвергнутые базой — пропускаются и попадают в отчёт, остальные
+// сохраняются в одной транзакции. Ошибка возвращается, только если файл
+// нельзя разобрать целиком или база недоступна; в этом случае ничего не
+// сохраняется.
+func (s *Service) Import(ctx context.Context, r io.Reader, opts Options) (Report, error) {
+	m, err := newMapper(opts.Mapping)
+	if err != nil {
+		return Report{}, err
+	}
+
+	records, ignored, err := parse(r, opts.Format, m)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Report{
+		Format:         opts.Format,
+		DryRun:         opts.DryRun,
+		Total:          len(records),
+		IgnoredColumns: ignored,
+		Rows:           make([]RowResult, len(records)),
+	}
+
+	games := make(map[int]game.Game, len(records))
+	var externalIDs []string
+	for i, rec := range records {
+		row := RowResult{Row: rec.row, Title: rec.values[fieldTitle]}
+
+		g, err := prepare(rec)
+		if err != nil {
+			row.Action, row.Error = ActionError, err.Error()
+			report.Failed++
+		} else {
+			games[i] = g
+			if g.ExternalID != nil {
+				row.ExternalID = *g.ExternalID
+				externalIDs = append(externalIDs, *g.ExternalID)
+			}
+		}
+
+		report.Rows[i] = row
+	}
+
+	if opts.DryRun {
+		err = s.plan(ctx, &report, games, externalIDs)
+	} else {
+		err = s.tx.WithTx(ctx, func(ctx context.Context) error {
+			return s.save(ctx, &report, games)
+		})
+	}
+	if err != nil {
+		return Report{}, err
+	}
+
+	return report, nil
+}
+
+func prepare(rec record) (game.Game, error) {
+	if rec.err != nil {
+		return game.Game{}, rec.err
+	}
+
+	g, err := toGame(rec.values)
+	if err != nil {
+		return game.Game{}, err
+	}
+
+	return game.PrepareGame(g)
+}
+
+// plan заполняет отчёт так, как его заполнил бы save, ничего не записывая.
+func (s *Service) plan(ctx context.Context, report *Report, games map[int]game.Game, externalIDs []string) error {
+	existing, err := s.games.FindByExternalIDs(ctx, externalIDs)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(externalIDs))
+	for i := range report.Rows {
+		g, ok := games[i]
+		if !ok {
+			continue
+		}
+
+		row := &report.Rows[i]
+		if g.ExternalID != nil && (existing[*g.ExternalID] != 0 || seen[*g.ExternalID]) {
+			row.Action, row.GameID = ActionUpdate, existing[*g.ExternalID]
+			report.Updated++
+		} else {
+			row.Action = ActionCreate
+			report.Created++
+		}
+
+		if g.ExternalID != nil {
+			seen[*g.ExternalID] = true
+		}
+	}
+
+	return nil
+}
+
+// save сохраняет игры по одной, каждую в своей точке сохранения: запись,
+// которую отвергла база, откатывается и попадает в отчёт с ошибкой, не
+// прерывая остальные.
+func (s *Service) save(ctx context.Context, report *Report, games map[int]game.Game) error {
+	for i := range report.Rows {
+		g, ok := games[i]
+		if !ok {
+			continue
+		}
+
+		var (
+			id      int64
+			created bool
+		)
+		err := db.Savepoint(ctx, func(ctx context.Context) error {
+			var err error
+			id, created, err = s.games.ImportGame(ctx, g)
+			return err
+		})
+
+		row := &report.Rows[i]
+		switch {
+		case isRowError(err):
+			row.Action, row.Error = ActionError, err.Error()
+			report.Failed++
+		case err != nil:
+			return err
+		case created:
+			row.GameID, row.Action = id, ActionCreate
+			report.Created++
+		default:
+			row.GameID, row.Action = id, ActionUpdate
+			report.Updated++
+		}
+	}
+
+	return nil
+}
+
+// isRowError сообщает, что запись отвергнута из-за своих данных: она не прошла
+// проверку или база вернула ошибку класса 22 (недопустимые данные) или 23
+// (нарушение ограничения). Остальные ошибки базы прерывают весь импорт.
+func isRowError(err error) bool {
+	if errors.Is(err, game.ErrInvalidGame) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return strings.HasPrefix(pgErr.Code, "22") || strings.HasPrefix(pgErr.Code, "23")
+}
@@ -0,0 +1,211 @@
+package gameimport
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/board-box/backend/internal/service/game"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeCatalog хранит импортированные игры в памяти.
+type fakeCatalog struct {
+	byExternalID map[string]int64
+	games        map[int64]game.Game
+	nextID       int64
+	// failTitle — игра, на которой база падает не из-за данных записи
+	failTitle string
+	// rejectTitle — игра, которую отвергает база
+	rejectTitle string
+}
+
+func newFakeCatalog() *fakeCatalog {
+	return &fakeCatalog{byExternalID: map[string]int64{}, games: map[int64]game.Game{}}
+}
+
+func (c *fakeCatalog) ImportGame(_ context.Context, g game.Game) (int64, bool, error) {
+	if g.Title == c.failTitle {
+		return 0, false, &pgconn.PgError{Code: "40P01", Message: "deadlock detected"}
+	}
+	if g.Title == c.rejectTitle {
+		return 0, false, &pgconn.PgError{Code: "23514", Message: "check constraint violated"}
+	}
+
+	if g.ExternalID != nil {
+		if id, ok := c.byExternalID[*g.ExternalID]; ok {
+			c.games[id] = g
+			return id, false, nil
+		}
+	}
+
+	c.nextID++
+	c.games[c.nextID] = g
+	if g.ExternalID != nil {
+		c.byExternalID[*g.ExternalID] = c.nextID
+	}
+	return c.nextID, true, nil
+}
+
+func (c *fakeCatalog) FindByExternalIDs(_ context.Context, externalIDs []string) (map[string]int64, error) {
+	ids := map[string]int64{}
+	for _, id := range externalIDs {
+		if gameID, ok := c.byExternalID[id]; ok {
+			ids[id] = gameID
+		}
+	}
+	return ids, nil
+}
+
+// fakeTx выполняет fn без транзакции.
+type fakeTx struct{}
+
+func (fakeTx) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+const importCSV = `title,bgg_id,players,complexity_level
+Каркассон,822,2-5,1.9
+Серп,,1-5,7
+,,2,
+Колонизаторы,13,3-4,2.3
+Каркассон: Охотники,822,2-5,2
+`
+
+func TestImport(t *testing.T) {
+	catalog := newFakeCatalog()
+	svc := NewService(catalog, fakeTx{})
+
+	report, err := svc.Import(context.Background(), strings.NewReader(importCSV), Options{Format: FormatCSV})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	// Строка с тем же bgg_id обновляет игру, созданную строкой выше
+	wantActions := []string{ActionCreate, ActionError, ActionError, ActionCreate, ActionUpdate}
+	assertActions(t, report, wantActions)
+	if report.Total != 5 || report.Created != 2 || report.Updated != 1 || report.Failed != 2 {
+		t.Fatalf("report = %+v", report)
+	}
+	if !strings.Contains(report.Rows[1].Error, "complexity_level") || !strings.Contains(report.Rows[2].Error, "title") {
+		t.Errorf("row errors = %q, %q", report.Rows[1].Error, report.Rows[2].Error)
+	}
+	if report.Rows[4].GameID != report.Rows[0].GameID || report.Rows[4].ExternalID != "bgg:822" {
+		t.Errorf("row 6 = %+v, want update of game %d", report.Rows[4], report.Rows[0].GameID)
+	}
+	if len(catalog.games) != 2 || catalog.games[report.Rows[0].GameID].Title != "Каркассон: Охотники" {
+		t.Errorf("catalog = %+v", catalog.games)
+	}
+
+	// Повторный импорт только обновляет игры
+	report, err = svc.Import(context.Background(), strings.NewReader(importCSV), Options{Format: FormatCSV})
+	if err != nil {
+		t.Fatalf("second Import() error = %v", err)
+	}
+	if report.Created != 0 || report.Updated != 3 {
+		t.Fatalf("second report = %+v, want only updates", report)
+	}
+}
+
+func TestImportDryRun(t *testing.T) {
+	catalog := newFakeCatalog()
+	catalog.byExternalID["bgg:13"] = 42
+	svc := NewService(catalog, fakeTx{})
+
+	report, err := svc.Import(context.Background(), strings.NewReader(importCSV), Options{Format: FormatCSV, DryRun: true})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	assertActions(t, report, []string{ActionCreate, ActionError, ActionError, ActionUpdate, ActionUpdate})
+	if !report.DryRun || report.Created != 1 || report.Updated != 2 || report.Failed != 2 {
+		t.Fatalf("report = %+v", report)
+	}
+	if report.Rows[3].GameID != 42 {
+		t.Errorf("row for bgg:13 = %+v, want game 42", report.Rows[3])
+	}
+	if len(catalog.games) != 0 {
+		t.Fatalf("dry run wrote %d games", len(catalog.games))
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	catalog := newFakeCatalog()
+	catalog.failTitle = "Серп"
+	svc := NewService(catalog, fakeTx{})
+
+	_, err := svc.Import(context.Background(), strings.NewReader("title\nСерп\n"), Options{Format: FormatCSV})
+	if err == nil {
+		t.Fatal("Import() ignored a storage error")
+	}
+
+	// Запись, отвергнутая базой, попадает в отчёт, остальные сохраняются
+	catalog.rejectTitle = "Манчкин"
+	report, err := svc.Import(context.Background(), strings.NewReader("title\nМанчкин\nАзул\n"), Options{Format: FormatCSV})
+	if err != nil {
+		t.Fatalf("Import() with a rejected row error = %v", err)
+	}
+	if report.Created != 1 || report.Failed != 1 || report.Rows[0].Action != ActionError || report.Rows[0].Error == "" || report.Rows[1].GameID == 0 {
+		t.Fatalf("report with a rejected row = %+v", report)
+	}
+
+	_, err = svc.Import(context.Background(), strings.NewReader("title\nСерп\n"), Options{Format: FormatCSV, Mapping: map[string]string{"title": "name"}})
+	if !errors.Is(err, ErrInvalidMapping) {
+		t.Fatalf("Import() with bad mapping error = %v, want ErrInvalidMapping", err)
+	}
+
+	_, err = svc.Import(context.Background(), strings.NewReader(""), Options{Format: FormatCSV})
+	if !errors.Is(err, ErrInvalidFile) {
+		t.Fatalf("Import() of empty file error = %v, want ErrInvalidFile", err)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name, filename string
+		want           Format
+		wantErr        bool
+	}{
+		{filename: "games.CSV", want: FormatCSV},
+		{filename: "games.ndjson", want: FormatJSONL},
+		{filename: "things.xml", want: FormatBGG},
+		{name: "json", filename: "games.txt", want: FormatJSONL},
+		{name: "BGG", filename: "-", want: FormatBGG},
+		{filename: "games.txt", wantErr: true},
+		{name: "yaml", filename: "games.csv", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.name, tt.filename)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseFormat(%q, %q) = %q, %v; want %q", tt.name, tt.filename, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseMapping(t *testing.T) {
+	got, err := ParseMapping(" Name = title, Notes=-,")
+	if err != nil || len(got) != 2 || got["Name"] != "title" || got["Notes"] != "-" {
+		t.Fatalf("ParseMapping() = %v, %v", got, err)
+	}
+
+	for _, s := range []string{"Name", "=title", "Name="} {
+		if _, err = ParseMapping(s); !errors.Is(err, ErrInvalidMapping) {
+			t.Errorf("ParseMapping(%q) error = %v, want ErrInvalidMapping", s, err)
+		}
+	}
+}
+
+func assertActions(t *testing.T, report Report, want []string) {
+	t.Helper()
+
+	if len(report.Rows) != len(want) {
+		t.Fatalf("got %d rows, want %d: %+v", len(report.Rows), len(want), report.Rows)
+	}
+	for i, row := range report.Rows {
+		if row.Action != want[i] {
+			t.Errorf("row %d action = %q, want %q (%s)", row.Row, row.Action, want[i], row.Error)
+		}
+	}
+}
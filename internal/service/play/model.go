@@ -0,0 +1,71 @@
+package play
+
+import "time"
+
+// Play — записанная партия. UserID — кто её записал; он может и не быть
+// среди игроков.
+type Play struct {
+	ID              int64     `json:"id" db:"id"`
+	UserID          int64     `json:"user_id" db:"user_id"`
+	GameID          int64     `json:"game_id" db:"game_id"`
+	GameTitle       string    `json:"game_title" db:"game_title"`
+	PlayedOn        time.Time `json:"played_on" db:"played_on"`
+	DurationMinutes *int      `json:"duration_minutes" db:"duration_minutes"`
+	Location        *string   `json:"location" db:"location"`
+	Note            *string   `json:"note" db:"note"`
+	Players         []Player  `json:"players" db:"-"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// Player — участник партии: зарегистрированный пользователь (UserID) или
+// гость (GuestName). Победителей может быть несколько. Pending — пользователь
+// ещё не подтвердил, что играл.
+type Player struct {
+	PlayID    int64    `json:"-" db:"play_id"`
+	UserID    *int64   `json:"user_id,omitempty" db:"user_id"`
+	Username  *string  `json:"username,omitempty" db:"username"`
+	GuestName *string  `json:"guest_name,omitempty" db:"guest_name"`
+	Score     *float64 `json:"score" db:"score"`
+	Winner    bool     `json:"winner" db:"winner"`
+	Pending   bool     `json:"pending,omitempty" db:"pending"`
+}
+
+// ListFilter отбирает партии пользователя; нулевые значения не фильтруют.
+type ListFilter struct {
+	GameID int64
+}
+
+// GameStat — партии пользователя в одну игру. Plays считает все его партии:
+// записанные им и те, где он подтвердил участие; PlayedAs и Wins — только
+// те, где он играл сам.
+type GameStat struct {
+	GameID     int64     `json:"game_id" db:"game_id"`
+	Title      string    `json:"title" db:"title"`
+	Plays      int       `json:"plays" db:"plays"`
+	PlayedAs   int       `json:"-" db:"played_as"`
+	Wins       int       `json:"-" db:"wins"`
+	LastPlayed time.Time `json:"last_played" db:"last_played"`
+}
+
+// WinRate — доля побед пользователя в игре среди партий, где он играл.
+type WinRate struct {
+	GameID int64   `json:"game_id"`
+	Title  string  `json:"title"`
+	Plays  int     `json:"plays"`
+	Wins   int     `json:"wins"`
+	Rate   float64 `json:"rate"`
+}
+
+// HIndex — наибольшее h, при котором у пользователя есть h игр, сыгранных
+// хотя бы h раз. Games — эти игры.
+type HIndex struct {
+	HIndex int        `json:"h_index"`
+	Games  []GameStat `json:"games"`
+}
+
+// ShelfGame — игра из коллекций пользователя, в которую он ни разу не играл.
+type ShelfGame struct {
+	GameID  int64     `json:"game_id" db:"game_id"`
+	Title   string    `json:"title" db:"title"`
+	AddedAt time.Time `json:"added_at" db:"added_at"`
+}
@@ -0,0 +1,429 @@
+package play
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/board-box/backend/internal/db"
+	"github.com/board-box/backend/internal/pagination"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	pgx "github.com/jackc/pgx/v5"
+)
+
+const (
+	playTableName           = "play"
+	playerTableName         = "play_player"
+	gameTableName           = "game"
+	userTableName           = "users"
+	collectionTableName     = "collection"
+	collectionGameTableName = "collection_game"
+
+	// participant — партии пользователя: записанные им или те, где он среди
+	// игроков и подтвердил участие. Ожидает псевдоним p у таблицы партий.
+	participant = "(p.user_id = ? OR EXISTS (SELECT 1 FROM play_player pp WHERE pp.play_id = p.id AND pp.user_id = ? AND pp.confirmed_at IS NOT NULL))"
+	// visible — партии, которые пользователь может открыть: как participant,
+	// но и с ещё не подтверждённым участием.
+	visible = "(p.user_id = ? OR EXISTS (SELECT 1 FROM play_player pp WHERE pp.play_id = p.id AND pp.user_id = ?))"
+)
+
+// ownedCollectionTypes — виды коллекций с играми, которые у пользователя есть.
+var ownedCollectionTypes = []string{"owned", "custom"}
+
+var (
+	psql = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+
+	ErrPlayNotFound   = errors.New("play not found")
+	ErrPlayerNotFound = errors.New("player not found")
+)
+
+var playColumns = []string{
+	"p.id", "p.user_id", "p.game_id", "g.title AS game_title", "p.played_on",
+	"p.duration_minutes", "p.location", "p.note", "p.created_at",
+}
+
+// Repository — хранилище партий.
+type Repository interface {
+	ListPlays(ctx context.Context, userID int64, filter ListFilter, page pagination.Params) ([]Play, pagination.Meta, error)
+	GetPlay(ctx context.Context, playID, userID int64) (Play, error)
+	CreatePlay(ctx context.Context, p Play) (Play, error)
+	DeletePlay(ctx context.Context, playID, userID int64) error
+	ListPending(ctx context.Context, userID int64) ([]Play, error)
+	ConfirmPlay(ctx context.Context, playID, userID int64) error
+	DeclinePlay(ctx context.Context, playID, userID int64) error
+	GameStats(ctx context.Context, userID int64) ([]GameStat, error)
+	ShelfOfShame(ctx context.Context, userID int64) ([]ShelfGame, error)
+}
+
+type repository struct {
+	db db.DB
+}
+
+func NewRepository(db db.DB) Repository {
+	return &repository{db: db}
+}
+
+type playCursor struct {
+	PlayedOn time.Time `json:"d"`
+	ID       int64     `json:"id"`
+}
+
+// ListPlays возвращает партии пользователя, новые первыми.
+func (r *repository) ListPlays(ctx context.Context, userID int64, filter ListFilter, page pagination.Params) ([]Play, pagination.Meta, error) {
+	limit := page.PageLimit()
+
+	where := squirrel.And{squirrel.Expr(participant, userID, userID)}
+	if filter.GameID != 0 {
+		where = append(where, squirrel.Eq{"p.game_id": filter.GameID})
+	}
+
+	builder := psql.
+		Select(playColumns...).
+		From(playTableName+" p").
+		Join(gameTableName+" g ON g.id = p.game_id").
+		Where(where).
+		OrderBy("p.played_on DESC", "p.id DESC").
+		Limit(uint64(limit + 1))
+
+	if page.Cursor != "" {
+		var cursor playCursor
+		if err := pagination.Decode(page.Cursor, &cursor); err != nil {
+			return nil, pagination.Meta{}, err
+		}
+		builder = builder.Where("(p.played_on, p.id) < (?, ?)", cursor.PlayedOn, cursor.ID)
+	}
+	if page.Offset > 0 {
+		builder = builder.Offset(uint64(page.Offset))
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	var plays []Play
+	if err = pgxscan.Select(ctx, r.db, &plays, query, args...); err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	meta := pagination.Meta{}
+	plays, hasMore := pagination.Trim(plays, limit)
+	if hasMore {
+		last := plays[len(plays)-1]
+		meta.NextCursor = pagination.Encode(playCursor{PlayedOn: last.PlayedOn, ID: last.ID})
+	}
+
+	if err = r.loadPlayers(ctx, plays); err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	query, args, err = psql.
+		Select("COUNT(*)").
+		From(playTableName + " p").
+		Where(where).
+		ToSql()
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	if err = r.db.QueryRow(ctx, query, args...).Scan(&meta.Total); err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	return plays, meta, nil
+}
+
+// GetPlay возвращает партию, если пользователь её записал или указан среди
+// игроков — в том числе до подтверждения.
+func (r *repository) GetPlay(ctx context.Context, playID, userID int64) (Play, error) {
+	query, args, err := psql.
+		Select(playColumns...).
+		From(playTableName+" p").
+		Join(gameTableName+" g ON g.id = p.game_id").
+		Where(squirrel.Eq{"p.id": playID}).
+		Where(visible, userID, userID).
+		ToSql()
+	if err != nil {
+		return Play{}, err
+	}
+
+	var p Play
+	if err = pgxscan.Get(ctx, r.db, &p, query, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Play{}, ErrPlayNotFound
+		}
+		return Play{}, err
+	}
+
+	plays := []Play{p}
+	if err = r.loadPlayers(ctx, plays); err != nil {
+		return Play{}, err
+	}
+
+	return plays[0], nil
+}
+
+// loadPlayers заполняет игроков партий одним запросом, в порядке мест.
+func (r *repository) loadPlayers(ctx context.Context, plays []Play) error {
+	if len(plays) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(plays))
+	byID := make(map[int64]*Play, len(plays))
+	for i := range plays {
+		plays[i].Players = []Player{}
+		ids = append(ids, plays[i].ID)
+		byID[plays[i].ID] = &plays[i]
+	}
+
+	query, args, err := psql.
+		Select(
+			"pp.play_id", "pp.user_id", "u.username", "pp.guest_name", "pp.score", "pp.winner",
+			"(pp.user_id IS NOT NULL AND pp.confirmed_at IS NULL) AS pending",
+		).
+		From(playerTableName+" pp").
+		LeftJoin(userTableName+" u ON u.id = pp.user_id").
+		Where(squirrel.Eq{"pp.play_id": ids}).
+		OrderBy("pp.play_id", "pp.seat").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	var players []Player
+	if err = pgxscan.Select(ctx, r.db, &players, query, args...); err != nil {
+		return err
+	}
+
+	for _, player := range players {
+		p := byID[player.PlayID]
+		p.Players = append(p.Players, player)
+	}
+
+	return nil
+}
+
+// CreatePlay сохраняет партию вместе с игроками. Участие записавшего и гостей
+// подтверждено сразу, остальные пользователи подтверждают его сами. Для
+// незарегистрированных игроков возвращает ErrPlayerNotFound.
+func (r *repository) CreatePlay(ctx context.Context, p Play) (Play, error) {
+	var playID int64
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var userIDs []int64
+		for _, player := range p.Players {
+			if player.UserID != nil {
+				userIDs = append(userIDs, *player.UserID)
+			}
+		}
+		if len(userIDs) > 0 {
+			query, args, err := psql.
+				Select("COUNT(*)").
+				From(userTableName).
+				Where(squirrel.Eq{"id": userIDs}).
+				ToSql()
+			if err != nil {
+				return err
+			}
+
+			var found int
+			if err = tx.QueryRow(ctx, query, args...).Scan(&found); err != nil {
+				return err
+			}
+			if found != len(userIDs) {
+				return ErrPlayerNotFound
+			}
+		}
+
+		query, args, err := psql.
+			Insert(playTableName).
+			Columns("user_id", "game_id", "played_on", "duration_minutes", "location", "note").
+			Values(p.UserID, p.GameID, p.PlayedOn, p.DurationMinutes, p.Location, p.Note).
+			Suffix("RETURNING id").
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		if err = tx.QueryRow(ctx, query, args...).Scan(&playID); err != nil {
+			return err
+		}
+
+		builder := psql.
+			Insert(playerTableName).
+			Columns("play_id", "seat", "user_id", "guest_name", "score", "winner", "confirmed_at")
+		for i, player := range p.Players {
+			var confirmedAt any
+			if player.UserID == nil || *player.UserID == p.UserID {
+				confirmedAt = squirrel.Expr("NOW()")
+			}
+			builder = builder.Values(playID, i+1, player.UserID, player.GuestName, player.Score, player.Winner, confirmedAt)
+		}
+
+		query, args, err = builder.ToSql()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, query, args...)
+		return err
+	})
+	if err != nil {
+		return Play{}, err
+	}
+
+	return r.GetPlay(ctx, playID, p.UserID)
+}
+
+// DeletePlay удаляет партию; удалить её может только тот, кто записал.
+func (r *repository) DeletePlay(ctx context.Context, playID, userID int64) error {
+	query, args, err := psql.
+		Delete(playTableName).
+		Where(squirrel.Eq{"id": playID, "user_id": userID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrPlayNotFound
+	}
+
+	return nil
+}
+
+// ListPending возвращает партии, где пользователя указали игроком, а он ещё
+// не подтвердил участие; новые первыми.
+func (r *repository) ListPending(ctx context.Context, userID int64) ([]Play, error) {
+	query, args, err := psql.
+		Select(playColumns...).
+		From(playTableName+" p").
+		Join(gameTableName+" g ON g.id = p.game_id").
+		Join(playerTableName+" pp ON pp.play_id = p.id").
+		Where(squirrel.Eq{"pp.user_id": userID, "pp.confirmed_at": nil}).
+		OrderBy("p.played_on DESC", "p.id DESC").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	plays := []Play{}
+	if err = pgxscan.Select(ctx, r.db, &plays, query, args...); err != nil {
+		return nil, err
+	}
+
+	if err = r.loadPlayers(ctx, plays); err != nil {
+		return nil, err
+	}
+
+	return plays, nil
+}
+
+// ConfirmPlay подтверждает участие пользователя в партии.
+func (r *repository) ConfirmPlay(ctx context.Context, playID, userID int64) error {
+	query, args, err := psql.
+		Update(playerTableName).
+		Set("confirmed_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{"play_id": playID, "user_id": userID, "confirmed_at": nil}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrPlayNotFound
+	}
+
+	return nil
+}
+
+// DeclinePlay отказывается от участия в партии: место остаётся в партии,
+// но становится гостем с именем пользователя и больше с ним не связано.
+func (r *repository) DeclinePlay(ctx context.Context, playID, userID int64) error {
+	query, args, err := psql.
+		Update(playerTableName).
+		Set("user_id", nil).
+		Set("guest_name", squirrel.Expr("(SELECT username FROM "+userTableName+" WHERE id = ?)", userID)).
+		Where(squirrel.Eq{"play_id": playID, "user_id": userID, "confirmed_at": nil}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrPlayNotFound
+	}
+
+	return nil
+}
+
+// GameStats возвращает статистику пользователя по играм: самые частые
+// первыми, при равенстве — сыгранные позже.
+func (r *repository) GameStats(ctx context.Context, userID int64) ([]GameStat, error) {
+	query, args, err := psql.
+		Select(
+			"p.game_id",
+			"g.title",
+			"COUNT(*) AS plays",
+			"COUNT(pp.play_id) AS played_as",
+			"COUNT(*) FILTER (WHERE pp.winner) AS wins",
+			"MAX(p.played_on) AS last_played",
+		).
+		From(playTableName+" p").
+		Join(gameTableName+" g ON g.id = p.game_id").
+		LeftJoin(playerTableName+" pp ON pp.play_id = p.id AND pp.user_id = ? AND pp.confirmed_at IS NOT NULL", userID).
+		Where("(p.user_id = ? OR pp.user_id IS NOT NULL)", userID).
+		GroupBy("p.game_id", "g.title").
+		OrderBy("plays DESC", "last_played DESC", "p.game_id ASC").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []GameStat
+	if err = pgxscan.Select(ctx, r.db, &stats, query, args...); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// ShelfOfShame возвращает игры, которые есть у пользователя — в списке «Моя
+// коллекция» или в его обычных коллекциях, — но в которые он ни разу не
+// играл. Сначала те, что лежат дольше всех. Списки желаний и проданные игры
+// не учитываются.
+func (r *repository) ShelfOfShame(ctx context.Context, userID int64) ([]ShelfGame, error) {
+	query, args, err := psql.
+		Select("g.id AS game_id", "g.title", "MIN(cg.added_at) AS added_at").
+		From(collectionTableName+" c").
+		Join(collectionGameTableName+" cg ON cg.collection_id = c.id").
+		Join(gameTableName+" g ON g.id = cg.game_id").
+		Where(squirrel.Eq{"c.user_id": userID, "c.type": ownedCollectionTypes}).
+		Where("NOT EXISTS (SELECT 1 FROM "+playTableName+" p WHERE p.game_id = g.id AND "+participant+")", userID, userID).
+		GroupBy("g.id", "g.title").
+		OrderBy("added_at ASC", "g.id ASC").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var games []ShelfGame
+	if err = pgxscan.Select(ctx, r.db, &games, query, args...); err != nil {
+		return nil, err
+	}
+
+	return games, nil
+}
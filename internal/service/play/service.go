@@ -0,0 +1,235 @@
+package play
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/board-box/backend/internal/db"
+	"github.com/board-box/backend/internal/pagination"
+	"github.com/board-box/backend/internal/service/game"
+)
+
+var ErrInvalidPlay = errors.New("invalid play")
+
+const (
+	// maxPlayers ограничивает число игроков в одной партии.
+	maxPlayers = 50
+	// maxTextLen — длина места и имени гостя, как у колонок в базе.
+	maxTextLen = 255
+	// DefaultMostPlayed — сколько игр по умолчанию в списке самых частых.
+	DefaultMostPlayed = 10
+)
+
+// GameGetter — то, что партиям нужно от каталога игр.
+type GameGetter interface {
+	GetGame(ctx context.Context, id int64) (game.Game, error)
+}
+
+type Service struct {
+	repo    Repository
+	tx      db.TxManager
+	gameSvc GameGetter
+}
+
+func NewService(repo Repository, tx db.TxManager, gameSvc GameGetter) *Service {
+	return &Service{
+		repo:    repo,
+		tx:      tx,
+		gameSvc: gameSvc,
+	}
+}
+
+// LogPlay записывает партию от имени userID. Без даты партия считается
+// сыгранной сегодня.
+func (s *Service) LogPlay(ctx context.Context, userID int64, p Play) (Play, error) {
+	p.UserID = userID
+	if err := normalizePlay(&p, time.Now()); err != nil {
+		return Play{}, err
+	}
+
+	var created Play
+	err := s.tx.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := s.gameSvc.GetGame(ctx, p.GameID); err != nil {
+			return err
+		}
+
+		var err error
+		created, err = s.repo.CreatePlay(ctx, p)
+		return err
+	})
+	if err != nil {
+		return Play{}, err
+	}
+
+	return created, nil
+}
+
+// normalizePlay проверяет партию и убирает лишние пробелы; пустые строки
+// становятся nil.
+func normalizePlay(p *Play, now time.Time) error {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if p.PlayedOn.IsZero() {
+		p.PlayedOn = today
+	}
+	// Сутки запаса на разницу часовых поясов
+	if p.PlayedOn.After(today.AddDate(0, 0, 1)) {
+		return fmt.Errorf("%w: played_on is in the future", ErrInvalidPlay)
+	}
+	if p.DurationMinutes != nil && *p.DurationMinutes <= 0 {
+		return fmt.Errorf("%w: duration_minutes must be positive", ErrInvalidPlay)
+	}
+
+	p.Location = trimmedOrNil(p.Location)
+	p.Note = trimmedOrNil(p.Note)
+	if p.Location != nil && len([]rune(*p.Location)) > maxTextLen {
+		return fmt.Errorf("%w: location is longer than %d characters", ErrInvalidPlay, maxTextLen)
+	}
+
+	if len(p.Players) == 0 {
+		return fmt.Errorf("%w: at least one player is required", ErrInvalidPlay)
+	}
+	if len(p.Players) > maxPlayers {
+		return fmt.Errorf("%w: at most %d players", ErrInvalidPlay, maxPlayers)
+	}
+
+	seen := make(map[int64]bool, len(p.Players))
+	for i := range p.Players {
+		player := &p.Players[i]
+		player.GuestName = trimmedOrNil(player.GuestName)
+
+		switch {
+		case (player.UserID == nil) == (player.GuestName == nil):
+			return fmt.Errorf("%w: player %d needs either user_id or guest_name", ErrInvalidPlay, i+1)
+		case player.UserID != nil && seen[*player.UserID]:
+			return fmt.Errorf("%w: user %d is listed twice", ErrInvalidPlay, *player.UserID)
+		case player.GuestName != nil && len([]rune(*player.GuestName)) > maxTextLen:
+			return fmt.Errorf("%w: guest_name is longer than %d characters", ErrInvalidPlay, maxTextLen)
+		}
+		if player.UserID != nil {
+			seen[*player.UserID] = true
+		}
+	}
+
+	return nil
+}
+
+func trimmedOrNil(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	if v := strings.TrimSpace(*s); v != "" {
+		return &v
+	}
+	return nil
+}
+
+func (s *Service) ListPlays(ctx context.Context, userID int64, filter ListFilter, page pagination.Params) ([]Play, pagination.Meta, error) {
+	if err := page.Validate(); err != nil {
+		return nil, pagination.Meta{}, err
+	}
+	return s.repo.ListPlays(ctx, userID, filter, page)
+}
+
+func (s *Service) GetPlay(ctx context.Context, playID, userID int64) (Play, error) {
+	return s.repo.GetPlay(ctx, playID, userID)
+}
+
+func (s *Service) DeletePlay(ctx context.Context, playID, userID int64) error {
+	return s.repo.DeletePlay(ctx, playID, userID)
+}
+
+// ListPending возвращает партии, участие в которых пользователь ещё не
+// подтвердил.
+func (s *Service) ListPending(ctx context.Context, userID int64) ([]Play, error) {
+	return s.repo.ListPending(ctx, userID)
+}
+
+// ConfirmPlay подтверждает, что пользователь играл в партии; после этого она
+// учитывается в его списке и статистике.
+func (s *Service) ConfirmPlay(ctx context.Context, playID, userID int64) error {
+	return s.repo.ConfirmPlay(ctx, playID, userID)
+}
+
+// DeclinePlay убирает пользователя из партии, оставляя его место гостем.
+func (s *Service) DeclinePlay(ctx context.Context, playID, userID int64) error {
+	return s.repo.DeclinePlay(ctx, playID, userID)
+}
+
+// MostPlayed возвращает limit самых частых игр пользователя.
+func (s *Service) MostPlayed(ctx context.Context, userID int64, limit int) ([]GameStat, error) {
+	if limit <= 0 {
+		limit = DefaultMostPlayed
+	}
+
+	stats, err := s.repo.GameStats(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(stats) > limit {
+		stats = stats[:limit]
+	}
+	return stats, nil
+}
+
+// WinRates возвращает долю побед по играм, где пользователь играл сам.
+func (s *Service) WinRates(ctx context.Context, userID int64) ([]WinRate, error) {
+	stats, err := s.repo.GameStats(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return winRates(stats), nil
+}
+
+// HIndex считает h-индекс пользователя по числу партий в каждую игру.
+func (s *Service) HIndex(ctx context.Context, userID int64) (HIndex, error) {
+	stats, err := s.repo.GameStats(ctx, userID)
+	if err != nil {
+		return HIndex{}, err
+	}
+	return hIndex(stats), nil
+}
+
+// ShelfOfShame возвращает игры из коллекций пользователя, в которые он ещё
+// не играл.
+func (s *Service) ShelfOfShame(ctx context.Context, userID int64) ([]ShelfGame, error) {
+	return s.repo.ShelfOfShame(ctx, userID)
+}
+
+// winRates считает долю побед; лучшие первыми, при равенстве — где партий больше.
+func winRates(stats []GameStat) []WinRate {
+	var rates []WinRate
+	for _, st := range stats {
+		if st.PlayedAs == 0 {
+			continue
+		}
+		rates = append(rates, WinRate{
+			GameID: st.GameID,
+			Title:  st.Title,
+			Plays:  st.PlayedAs,
+			Wins:   st.Wins,
+			Rate:   float64(st.Wins) / float64(st.PlayedAs),
+		})
+	}
+
+	sort.SliceStable(rates, func(i, j int) bool {
+		if rates[i].Rate != rates[j].Rate {
+			return rates[i].Rate > rates[j].Rate
+		}
+		return rates[i].Plays > rates[j].Plays
+	})
+	return rates
+}
+
+// hIndex ожидает статистику, отсортированную по убыванию числа партий.
+func hIndex(stats []GameStat) HIndex {
+	h := 0
+	for h < len(stats) && stats[h].Plays >= h+1 {
+		h++
+	}
+	return HIndex{HIndex: h, Games: stats[:h]}
+}
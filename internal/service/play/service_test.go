@@ -0,0 +1,86 @@
+package play
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHIndex(t *testing.T) {
+	tests := []struct {
+		plays []int
+		want  int
+	}{
+		{plays: nil, want: 0},
+		{plays: []int{1}, want: 1},
+		{plays: []int{10, 8, 5, 4, 3}, want: 4},
+		{plays: []int{25, 8, 5, 3, 3}, want: 3},
+		{plays: []int{2, 2, 2}, want: 2},
+	}
+
+	for _, tt := range tests {
+		stats := make([]GameStat, len(tt.plays))
+		for i, n := range tt.plays {
+			stats[i] = GameStat{GameID: int64(i + 1), Plays: n}
+		}
+
+		got := hIndex(stats)
+		if got.HIndex != tt.want || len(got.Games) != tt.want {
+			t.Errorf("hIndex(%v) = %d with %d games, want %d", tt.plays, got.HIndex, len(got.Games), tt.want)
+		}
+	}
+}
+
+func TestWinRates(t *testing.T) {
+	stats := []GameStat{
+		{GameID: 1, Plays: 6, PlayedAs: 4, Wins: 1},
+		// Партии, записанные за других, в долю побед не входят
+		{GameID: 2, Plays: 3, PlayedAs: 0},
+		{GameID: 3, Plays: 2, PlayedAs: 2, Wins: 1},
+		{GameID: 4, Plays: 4, PlayedAs: 4, Wins: 2},
+	}
+
+	got := winRates(stats)
+	want := []int64{4, 3, 1}
+	if len(got) != len(want) {
+		t.Fatalf("winRates() = %+v", got)
+	}
+	for i, id := range want {
+		if got[i].GameID != id {
+			t.Errorf("winRates()[%d] = %+v, want game %d", i, got[i], id)
+		}
+	}
+	if got[2].Rate != 0.25 || got[2].Plays != 4 {
+		t.Errorf("rate for game 1 = %+v", got[2])
+	}
+}
+
+func TestNormalizePlay(t *testing.T) {
+	now := time.Date(2026, 10, 17, 22, 0, 0, 0, time.UTC)
+	user := int64(7)
+	guest := "  Аня "
+	blank := " "
+
+	p := Play{Players: []Player{{UserID: &user, Winner: true}, {GuestName: &guest}}, Location: &blank}
+	if err := normalizePlay(&p, now); err != nil {
+		t.Fatalf("normalizePlay() error = %v", err)
+	}
+	if !p.PlayedOn.Equal(time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)) || p.Location != nil || *p.Players[1].GuestName != "Аня" {
+		t.Errorf("normalized play = %+v", p)
+	}
+
+	zero := 0
+	invalid := []Play{
+		{},
+		{Players: []Player{{UserID: &user, GuestName: &guest}}},
+		{Players: []Player{{GuestName: &blank}}},
+		{Players: []Player{{UserID: &user}, {UserID: &user}}},
+		{Players: []Player{{UserID: &user}}, DurationMinutes: &zero},
+		{Players: []Player{{UserID: &user}}, PlayedOn: now.AddDate(0, 0, 3)},
+	}
+	for i, p := range invalid {
+		if err := normalizePlay(&p, now); !errors.Is(err, ErrInvalidPlay) {
+			t.Errorf("case %d: normalizePlay() error = %v, want ErrInvalidPlay", i, err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package rating
+
+import "time"
+
+// Rating — оценка игры пользователем с необязательным отзывом. У пользователя
+// одна оценка на игру; правки сохраняются в истории.
+type Rating struct {
+	GameID    int64     `json:"game_id" db:"game_id"`
+	UserID    int64     `json:"user_id" db:"user_id"`
+	Username  string    `json:"username" db:"username"`
+	Rating    int       `json:"rating" db:"rating"`
+	Review    *string   `json:"review" db:"review"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Revision — одна сохранённая версия оценки.
+type Revision struct {
+	Rating    int       `json:"rating" db:"rating"`
+	Review    *string   `json:"review" db:"review"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Aggregate — оценки игры в сумме; хранится в строке игры и меняется
+// приращениями при каждой оценке.
+type Aggregate struct {
+	Count int `db:"rating_count"`
+	Sum   int `db:"rating_sum"`
+}
+
+const (
+	// priorMean и priorWeight задают байесовскую оценку: к оценкам игры
+	// добавляются priorWeight воображаемых голосов со средним priorMean, так что
+	// игра с парой высоких оценок не обгоняет игру с сотней хороших.
+	priorMean   = 5.5
+	priorWeight = 10
+)
+
+// Add возвращает агрегаты с учётом изменения: count и sum прибавляются к
+// текущим значениям и могут быть отрицательными.
+func (a Aggregate) Add(count, sum int) Aggregate {
+	return Aggregate{Count: a.Count + count, Sum: a.Sum + sum}
+}
+
+// Average — средняя оценка; nil, пока оценок нет.
+func (a Aggregate) Average() *float64 {
+	if a.Count == 0 {
+		return nil
+	}
+	avg := float64(a.Sum) / float64(a.Count)
+	return &avg
+}
+
+// Score — байесовская оценка; без оценок равна priorMean.
+func (a Aggregate) Score() float64 {
+	return (priorMean*priorWeight + float64(a.Sum)) / float64(priorWeight+a.Count)
+}
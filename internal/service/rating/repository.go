@@ -0,0 +1,262 @@
+package rating
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/board-box/backend/internal/db"
+	"github.com/board-box/backend/internal/pagination"
+	"github.com/board-box/backend/internal/service/game"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	pgx "github.com/jackc/pgx/v5"
+)
+
+const (
+	ratingTableName   = "game_rating"
+	revisionTableName = "game_rating_revision"
+	gameTableName     = "game"
+	userTableName     = "users"
+)
+
+var (
+	psql = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+
+	ErrRatingNotFound = errors.New("rating not found")
+)
+
+var ratingColumns = []string{
+	"r.game_id", "r.user_id", "u.username", "r.rating", "r.review", "r.created_at", "r.updated_at",
+}
+
+// Repository — хранилище оценок. Методы, меняющие оценки, вызываются в
+// одной транзакции с LockGame.
+type Repository interface {
+	LockGame(ctx context.Context, gameID int64) (Aggregate, error)
+	UpdateAggregate(ctx context.Context, gameID int64, agg Aggregate) error
+	GetRating(ctx context.Context, gameID, userID int64) (Rating, error)
+	SaveRating(ctx context.Context, r Rating) (Rating, error)
+	DeleteRating(ctx context.Context, gameID, userID int64) (Rating, error)
+	ListRatings(ctx context.Context, gameID int64, page pagination.Params) ([]Rating, pagination.Meta, error)
+	ListRevisions(ctx context.Context, gameID, userID int64) ([]Revision, error)
+}
+
+type repository struct {
+	db db.DB
+}
+
+func NewRepository(db db.DB) Repository {
+	return &repository{db: db}
+}
+
+// LockGame блокирует строку игры до конца транзакции, чтобы одновременные
+// оценки не затёрли агрегаты друг друга, и возвращает текущие агрегаты.
+func (r *repository) LockGame(ctx context.Context, gameID int64) (Aggregate, error) {
+	query, args, err := psql.
+		Select("rating_count", "rating_sum").
+		From(gameTableName).
+		Where(squirrel.Eq{"id": gameID}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return Aggregate{}, err
+	}
+
+	var agg Aggregate
+	if err = pgxscan.Get(ctx, r.db, &agg, query, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Aggregate{}, game.ErrGameNotFound
+		}
+		return Aggregate{}, err
+	}
+
+	return agg, nil
+}
+
+// UpdateAggregate записывает агрегаты игры вместе с выведенными из них
+// средней и байесовской оценками.
+func (r *repository) UpdateAggregate(ctx context.Context, gameID int64, agg Aggregate) error {
+	query, args, err := psql.
+		Update(gameTableName).
+		Set("rating_count", agg.Count).
+		Set("rating_sum", agg.Sum).
+		Set("rating_avg", agg.Average()).
+		Set("rating_score", agg.Score()).
+		Where(squirrel.Eq{"id": gameID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, query, args...)
+	return err
+}
+
+func (r *repository) GetRating(ctx context.Context, gameID, userID int64) (Rating, error) {
+	query, args, err := psql.
+		Select(ratingColumns...).
+		From(ratingTableName + " r").
+		Join(userTableName + " u ON u.id = r.user_id").
+		Where(squirrel.Eq{"r.game_id": gameID, "r.user_id": userID}).
+		ToSql()
+	if err != nil {
+		return Rating{}, err
+	}
+
+	var rating Rating
+	if err = pgxscan.Get(ctx, r.db, &rating, query, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Rating{}, ErrRatingNotFound
+		}
+		return Rating{}, err
+	}
+
+	return rating, nil
+}
+
+// SaveRating создаёт или заменяет оценку пользователя и добавляет её версию
+// в историю.
+func (r *repository) SaveRating(ctx context.Context, rating Rating) (Rating, error) {
+	query, args, err := psql.
+		Insert(ratingTableName).
+		Columns("game_id", "user_id", "rating", "review").
+		Values(rating.GameID, rating.UserID, rating.Rating, rating.Review).
+		Suffix("ON CONFLICT (game_id, user_id) DO UPDATE SET rating = EXCLUDED.rating, review = EXCLUDED.review, updated_at = NOW()").
+		ToSql()
+	if err != nil {
+		return Rating{}, err
+	}
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		return Rating{}, err
+	}
+
+	query, args, err = psql.
+		Insert(revisionTableName).
+		Columns("game_id", "user_id", "rating", "review").
+		Values(rating.GameID, rating.UserID, rating.Rating, rating.Review).
+		ToSql()
+	if err != nil {
+		return Rating{}, err
+	}
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		return Rating{}, err
+	}
+
+	return r.GetRating(ctx, rating.GameID, rating.UserID)
+}
+
+// DeleteRating удаляет оценку вместе с историей её правок и возвращает
+// удалённую оценку.
+func (r *repository) DeleteRating(ctx context.Context, gameID, userID int64) (Rating, error) {
+	query, args, err := psql.
+		Delete(ratingTableName).
+		Where(squirrel.Eq{"game_id": gameID, "user_id": userID}).
+		Suffix("RETURNING game_id, user_id, '' AS username, rating, review, created_at, updated_at").
+		ToSql()
+	if err != nil {
+		return Rating{}, err
+	}
+
+	var rating Rating
+	if err = pgxscan.Get(ctx, r.db, &rating, query, args...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Rating{}, ErrRatingNotFound
+		}
+		return Rating{}, err
+	}
+
+	query, args, err = psql.
+		Delete(revisionTableName).
+		Where(squirrel.Eq{"game_id": gameID, "user_id": userID}).
+		ToSql()
+	if err != nil {
+		return Rating{}, err
+	}
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		return Rating{}, err
+	}
+
+	return rating, nil
+}
+
+type ratingCursor struct {
+	UpdatedAt time.Time `json:"u"`
+	UserID    int64     `json:"id"`
+}
+
+// ListRatings возвращает оценки игры, недавно изменённые первыми.
+func (r *repository) ListRatings(ctx context.Context, gameID int64, page pagination.Params) ([]Rating, pagination.Meta, error) {
+	limit := page.PageLimit()
+
+	builder := psql.
+		Select(ratingColumns...).
+		From(ratingTableName+" r").
+		Join(userTableName+" u ON u.id = r.user_id").
+		Where(squirrel.Eq{"r.game_id": gameID}).
+		OrderBy("r.updated_at DESC", "r.user_id DESC").
+		Limit(uint64(limit + 1))
+
+	if page.Cursor != "" {
+		var cursor ratingCursor
+		if err := pagination.Decode(page.Cursor, &cursor); err != nil {
+			return nil, pagination.Meta{}, err
+		}
+		builder = builder.Where("(r.updated_at, r.user_id) < (?, ?)", cursor.UpdatedAt, cursor.UserID)
+	}
+	if page.Offset > 0 {
+		builder = builder.Offset(uint64(page.Offset))
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	var ratings []Rating
+	if err = pgxscan.Select(ctx, r.db, &ratings, query, args...); err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	meta := pagination.Meta{}
+	ratings, hasMore := pagination.Trim(ratings, limit)
+	if hasMore {
+		last := ratings[len(ratings)-1]
+		meta.NextCursor = pagination.Encode(ratingCursor{UpdatedAt: last.UpdatedAt, UserID: last.UserID})
+	}
+
+	query, args, err = psql.
+		Select("COUNT(*)").
+		From(ratingTableName).
+		Where(squirrel.Eq{"game_id": gameID}).
+		ToSql()
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	if err = r.db.QueryRow(ctx, query, args...).Scan(&meta.Total); err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	return ratings, meta, nil
+}
+
+// ListRevisions возвращает историю оценки пользователя, новые версии первыми.
+func (r *repository) ListRevisions(ctx context.Context, gameID, userID int64) ([]Revision, error) {
+	query, args, err := psql.
+		Select("rating", "review", "created_at").
+		From(revisionTableName).
+		Where(squirrel.Eq{"game_id": gameID, "user_id": userID}).
+		OrderBy("id DESC").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []Revision
+	if err = pgxscan.Select(ctx, r.db, &revisions, query, args...); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
@@ -0,0 +1,140 @@
+package rating
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/board-box/backend/internal/db"
+	"github.com/board-box/backend/internal/pagination"
+)
+
+var ErrInvalidRating = errors.New("invalid rating")
+
+const (
+	minRating = 1
+	maxRating = 10
+	// maxReviewLen ограничивает длину отзыва в символах.
+	maxReviewLen = 10000
+)
+
+type Service struct {
+	repo Repository
+	tx   db.TxManager
+}
+
+func NewService(repo Repository, tx db.TxManager) *Service {
+	return &Service{
+		repo: repo,
+		tx:   tx,
+	}
+}
+
+// Rate ставит или меняет оценку пользователя. Агрегаты игры пересчитываются
+// приращением в той же транзакции; повторное сохранение без изменений в
+// историю не попадает.
+func (s *Service) Rate(ctx context.Context, userID, gameID int64, value int, review *string) (Rating, error) {
+	review, err := normalizeRating(value, review)
+	if err != nil {
+		return Rating{}, err
+	}
+
+	var saved Rating
+	err = s.tx.WithTx(ctx, func(ctx context.Context) error {
+		agg, err := s.repo.LockGame(ctx, gameID)
+		if err != nil {
+			return err
+		}
+
+		old, err := s.repo.GetRating(ctx, gameID, userID)
+		switch {
+		case errors.Is(err, ErrRatingNotFound):
+			agg = agg.Add(1, value)
+		case err != nil:
+			return err
+		case old.Rating == value && equalReview(old.Review, review):
+			saved = old
+			return nil
+		default:
+			agg = agg.Add(0, value-old.Rating)
+		}
+
+		saved, err = s.repo.SaveRating(ctx, Rating{GameID: gameID, UserID: userID, Rating: value, Review: review})
+		if err != nil {
+			return err
+		}
+		return s.repo.UpdateAggregate(ctx, gameID, agg)
+	})
+	if err != nil {
+		return Rating{}, err
+	}
+
+	return saved, nil
+}
+
+// DeleteRating убирает оценку пользователя и её историю.
+func (s *Service) DeleteRating(ctx context.Context, userID, gameID int64) error {
+	return s.tx.WithTx(ctx, func(ctx context.Context) error {
+		agg, err := s.repo.LockGame(ctx, gameID)
+		if err != nil {
+			return err
+		}
+
+		deleted, err := s.repo.DeleteRating(ctx, gameID, userID)
+		if err != nil {
+			return err
+		}
+		return s.repo.UpdateAggregate(ctx, gameID, agg.Add(-1, -deleted.Rating))
+	})
+}
+
+func (s *Service) GetRating(ctx context.Context, userID, gameID int64) (Rating, error) {
+	return s.repo.GetRating(ctx, gameID, userID)
+}
+
+func (s *Service) ListRatings(ctx context.Context, gameID int64, page pagination.Params) ([]Rating, pagination.Meta, error) {
+	return s.repo.ListRatings(ctx, gameID, page)
+}
+
+// History возвращает версии оценки пользователя, новые первыми.
+func (s *Service) History(ctx context.Context, userID, gameID int64) ([]Revision, error) {
+	revisions, err := s.repo.ListRevisions(ctx, gameID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, ErrRatingNotFound
+	}
+
+	return revisions, nil
+}
+
+// normalizeRating проверяет оценку и убирает пробелы вокруг отзыва; пустой
+// отзыв становится nil.
+func normalizeRating(value int, review *string) (*string, error) {
+	if value < minRating || value > maxRating {
+		return nil, fmt.Errorf("%w: rating must be between %d and %d", ErrInvalidRating, minRating, maxRating)
+	}
+	if review == nil {
+		return nil, nil
+	}
+
+	trimmed := strings.TrimSpace(*review)
+	if trimmed == "" {
+		return nil, nil
+	}
+	if utf8.RuneCountInString(trimmed) > maxReviewLen {
+		return nil, fmt.Errorf("%w: review is longer than %d characters", ErrInvalidRating, maxReviewLen)
+	}
+
+	return &trimmed, nil
+}
+
+func equalReview(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
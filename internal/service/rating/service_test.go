@@ -0,0 +1,63 @@
+package rating
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestAggregate(t *testing.T) {
+	var agg Aggregate
+	if agg.Average() != nil || agg.Score() != priorMean {
+		t.Fatalf("empty aggregate: average %v, score %v", agg.Average(), agg.Score())
+	}
+
+	agg = agg.Add(1, 10).Add(1, 8)
+	if avg := agg.Average(); avg == nil || *avg != 9 {
+		t.Fatalf("Average() = %v, want 9", avg)
+	}
+	// (5.5*10 + 18) / 12
+	if got := agg.Score(); math.Abs(got-73.0/12) > 1e-9 {
+		t.Errorf("Score() = %v, want %v", got, 73.0/12)
+	}
+
+	// Правка оценки меняет только сумму, удаление возвращает к исходному
+	agg = agg.Add(0, -4)
+	if agg.Count != 2 || *agg.Average() != 7 {
+		t.Errorf("after edit = %+v", agg)
+	}
+	if agg = agg.Add(-1, -4).Add(-1, -10); agg != (Aggregate{}) {
+		t.Errorf("after deleting all = %+v", agg)
+	}
+
+	// Две десятки уступают сотне восьмёрок
+	few := Aggregate{}.Add(2, 20)
+	many := Aggregate{}.Add(100, 800)
+	if few.Score() >= many.Score() {
+		t.Errorf("Score() of two 10s = %v, of a hundred 8s = %v", few.Score(), many.Score())
+	}
+}
+
+func TestNormalizeRating(t *testing.T) {
+	review := "  Отличная игра \n"
+	got, err := normalizeRating(8, &review)
+	if err != nil || got == nil || *got != "Отличная игра" {
+		t.Fatalf("normalizeRating() = %v, %v", got, err)
+	}
+
+	blank := "   "
+	if got, err = normalizeRating(1, &blank); err != nil || got != nil {
+		t.Errorf("blank review = %v, %v; want nil", got, err)
+	}
+
+	long := strings.Repeat("ы", maxReviewLen+1)
+	for _, tt := range []struct {
+		value  int
+		review *string
+	}{{0, nil}, {11, nil}, {5, &long}} {
+		if _, err = normalizeRating(tt.value, tt.review); !errors.Is(err, ErrInvalidRating) {
+			t.Errorf("normalizeRating(%d) error = %v, want ErrInvalidRating", tt.value, err)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package similar
+
+import "github.com/board-box/backend/internal/service/game"
+
+// Pair — посчитанная похожесть игры SimilarID на игру GameID, от 0 до 1.
+type Pair struct {
+	GameID    int64   `db:"game_id"`
+	SimilarID int64   `db:"similar_id"`
+	Score     float64 `db:"score"`
+}
+
+// Similar — похожая игра в ответе.
+type Similar struct {
+	Game  game.Game `json:"game"`
+	Score float64   `json:"score"`
+}
+
+// Filter сужает список похожих игр; нулевые значения не фильтруют.
+type Filter struct {
+	// ExcludeUserID убирает игры, которые уже есть в коллекциях этого пользователя
+	ExcludeUserID int64
+	Players       int
+	MaxTime       int
+	Limit         int
+}
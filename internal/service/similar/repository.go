@@ -0,0 +1,146 @@
+package similar
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/board-box/backend/internal/db"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	pgx "github.com/jackc/pgx/v5"
+)
+
+const (
+	similarityTableName = "game_similarity"
+	gameTableName       = "game"
+
+	// refreshLockKey — ключ advisory lock пересчёта, чтобы реплики не
+	// заменяли таблицу одновременно.
+	refreshLockKey = 7_340_025
+	// insertBatch — сколько пар вставляется одним запросом.
+	insertBatch = 1000
+
+	// inCollections отбирает игры из коллекций, в которых участвует пользователь.
+	// Ожидает псевдоним s у таблицы похожести.
+	inCollections = "EXISTS (SELECT 1 FROM collection_game cg " +
+		"JOIN collection_member m ON m.collection_id = cg.collection_id AND m.accepted_at IS NOT NULL " +
+		"WHERE m.user_id = ? AND cg.game_id = s.similar_id)"
+)
+
+var psql = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+
+// Repository — хранилище посчитанной похожести игр.
+type Repository interface {
+	TryLockRefresh(ctx context.Context) (bool, error)
+	LastRefresh(ctx context.Context) (time.Time, error)
+	ReplaceAll(ctx context.Context, pairs []Pair) error
+	ListSimilar(ctx context.Context, gameID int64, filter Filter) ([]Pair, error)
+}
+
+type repository struct {
+	db db.DB
+}
+
+func NewRepository(db db.DB) Repository {
+	return &repository{db: db}
+}
+
+// TryLockRefresh берёт advisory lock пересчёта до конца текущей транзакции.
+// false — пересчёт уже идёт в другом процессе.
+func (r *repository) TryLockRefresh(ctx context.Context) (bool, error) {
+	var locked bool
+	err := r.db.QueryRow(ctx, "SELECT pg_try_advisory_xact_lock($1)", refreshLockKey).Scan(&locked)
+	return locked, err
+}
+
+// LastRefresh возвращает время последнего пересчёта; нулевое, если таблица
+// пуста.
+func (r *repository) LastRefresh(ctx context.Context) (time.Time, error) {
+	query, args, err := psql.
+		Select("computed_at").
+		From(similarityTableName).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var last time.Time
+	if err = r.db.QueryRow(ctx, query, args...).Scan(&last); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+
+	return last, nil
+}
+
+// ReplaceAll заменяет всю таблицу похожести. Вызывается в транзакции, поэтому
+// читатели до её завершения видят прежние данные.
+func (r *repository) ReplaceAll(ctx context.Context, pairs []Pair) error {
+	query, args, err := psql.Delete(similarityTableName).ToSql()
+	if err != nil {
+		return err
+	}
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	for start := 0; start < len(pairs); start += insertBatch {
+		builder := psql.
+			Insert(similarityTableName).
+			Columns("game_id", "similar_id", "score")
+		for _, p := range pairs[start:min(start+insertBatch, len(pairs))] {
+			builder = builder.Values(p.GameID, p.SimilarID, p.Score)
+		}
+
+		query, args, err = builder.ToSql()
+		if err != nil {
+			return err
+		}
+		if _, err = r.db.Exec(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListSimilar возвращает самые похожие на gameID игры, подходящие под фильтр.
+func (r *repository) ListSimilar(ctx context.Context, gameID int64, filter Filter) ([]Pair, error) {
+	builder := psql.
+		Select("s.game_id", "s.similar_id", "s.score").
+		From(similarityTableName+" s").
+		Join(gameTableName+" g ON g.id = s.similar_id").
+		Where(squirrel.Eq{"s.game_id": gameID}).
+		OrderBy("s.score DESC", "s.similar_id ASC").
+		Limit(uint64(filter.Limit))
+
+	if filter.ExcludeUserID != 0 {
+		builder = builder.Where("NOT "+inCollections, filter.ExcludeUserID)
+	}
+	// Те же условия, что у фильтров каталога
+	if filter.Players > 0 {
+		builder = builder.Where(
+			"g.min_players <= ? AND (g.max_players IS NULL OR g.max_players >= ?)",
+			filter.Players, filter.Players,
+		)
+	}
+	if filter.MaxTime > 0 {
+		builder = builder.Where("COALESCE(g.max_play_time, g.min_play_time) <= ?", filter.MaxTime)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var pairs []Pair
+	if err = pgxscan.Select(ctx, r.db, &pairs, query, args...); err != nil {
+		return nil, err
+	}
+
+	return pairs, nil
+}
@@ -0,0 +1,147 @@
+package similar
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/board-box/backend/internal/db"
+	"github.com/board-box/backend/internal/service/game"
+)
+
+// ErrRefreshInProgress — таблицу уже пересчитывает другой процесс.
+var ErrRefreshInProgress = errors.New("similarity refresh is already in progress")
+
+const (
+	DefaultLimit = 10
+	MaxLimit     = 50
+	// perGame — сколько похожих игр хранится для каждой игры: с запасом на
+	// фильтры, которые убирают часть списка.
+	perGame = 2 * MaxLimit
+)
+
+// Catalog — то, что похожим играм нужно от каталога.
+type Catalog interface {
+	GetGame(ctx context.Context, id int64) (game.Game, error)
+	GetGames(ctx context.Context, ids []int64) ([]game.Game, error)
+	ExportGames(ctx context.Context, fn func(game.Game) error) error
+}
+
+type Service struct {
+	repo    Repository
+	tx      db.TxManager
+	catalog Catalog
+}
+
+func NewService(repo Repository, tx db.TxManager, catalog Catalog) *Service {
+	return &Service{
+		repo:    repo,
+		tx:      tx,
+		catalog: catalog,
+	}
+}
+
+// Similar возвращает игры, похожие на gameID, самые похожие первыми. Список
+// берётся из таблицы, посчитанной Refresh, поэтому новые игры появляются в
+// нём после очередного пересчёта.
+func (s *Service) Similar(ctx context.Context, gameID int64, filter Filter) ([]Similar, error) {
+	if filter.Limit <= 0 || filter.Limit > MaxLimit {
+		filter.Limit = DefaultLimit
+	}
+	if _, err := s.catalog.GetGame(ctx, gameID); err != nil {
+		return nil, err
+	}
+
+	pairs, err := s.repo.ListSimilar(ctx, gameID, filter)
+	if err != nil || len(pairs) == 0 {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(pairs))
+	for _, p := range pairs {
+		ids = append(ids, p.SimilarID)
+	}
+	games, err := s.catalog.GetGames(ctx, ids)
+	if err != nil && !errors.Is(err, game.ErrGameNotFound) {
+		return nil, err
+	}
+
+	byID := make(map[int64]game.Game, len(games))
+	for _, g := range games {
+		byID[g.ID] = g
+	}
+	result := make([]Similar, 0, len(pairs))
+	for _, p := range pairs {
+		if g, ok := byID[p.SimilarID]; ok {
+			result = append(result, Similar{Game: g, Score: p.Score})
+		}
+	}
+
+	return result, nil
+}
+
+// Refresh пересчитывает похожесть по всему каталогу и заменяет таблицу.
+// Каталог читается и похожесть считается вне транзакции; транзакция
+// открывается только на замену таблицы. Возвращает число сохранённых пар.
+func (s *Service) Refresh(ctx context.Context) (int, error) {
+	var games []game.Game
+	err := s.catalog.ExportGames(ctx, func(g game.Game) error {
+		games = append(games, g)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	pairs, err := Compute(ctx, games, perGame)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.tx.WithTx(ctx, func(ctx context.Context) error {
+		locked, err := s.repo.TryLockRefresh(ctx)
+		if err != nil {
+			return err
+		}
+		if !locked {
+			return ErrRefreshInProgress
+		}
+
+		return s.repo.ReplaceAll(ctx, pairs)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(pairs), nil
+}
+
+// RunRefresher пересчитывает похожесть каждые interval, пока не отменён ctx.
+// Первый пересчёт — сразу после старта, если таблица старше interval, иначе
+// когда она устареет: так перезапуски и новые реплики не пересчитывают свежую
+// таблицу. Ошибки пересчёта передаются onError; пересчёт в другой реплике
+// ошибкой не считается.
+func (s *Service) RunRefresher(ctx context.Context, interval time.Duration, onError func(error)) {
+	var delay time.Duration
+	if last, err := s.repo.LastRefresh(ctx); err != nil {
+		onError(err)
+	} else if !last.IsZero() {
+		delay = max(0, interval-time.Since(last))
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		if _, err := s.Refresh(ctx); err != nil && !errors.Is(err, ErrRefreshInProgress) && ctx.Err() == nil {
+			onError(err)
+		}
+		timer.Reset(interval)
+	}
+}
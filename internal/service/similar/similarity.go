@@ -0,0 +1,266 @@
+package similar
+
+import (
+	"container/heap"
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/board-box/backend/internal/service/game"
+)
+
+// Веса признаков в итоговой похожести. Если признака нет хотя бы у одной из
+// игр, он не учитывается, а остальные веса нормируются.
+const (
+	weightGenre       = 0.3
+	weightDescription = 0.25
+	weightDifficulty  = 0.15
+	weightPlayers     = 0.15
+	weightPlayTime    = 0.15
+
+	// minWeight — меньше признаков не хватает, чтобы судить о похожести
+	minWeight = 0.3
+	// stemLen обрезает слова описания до грубой основы, чтобы «стратегия» и
+	// «стратегии» совпадали.
+	stemLen    = 6
+	minTermLen = 3
+)
+
+var stopWords = map[string]bool{
+	"для": true, "как": true, "что": true, "это": true, "или": true, "при": true, "под": true,
+	"над": true, "все": true, "вас": true, "его": true, "она": true, "они": true, "так": true,
+	"где": true, "кто": true, "чем": true, "вам": true, "нет": true, "уже": true, "еще": true,
+	"ещё": true, "the": true, "and": true, "for": true, "with": true, "you": true,
+}
+
+// features — признаки игры, по которым считается похожесть.
+type features struct {
+	id         int64
+	genres     map[string]bool
+	difficulty *float64
+	// players — полуинтервал [min, max+1); нет, если неизвестен минимум
+	players *[2]float64
+	// playTime — середина диапазона времени партии в минутах
+	playTime *float64
+	// described — в описании есть слова; terms может быть пуст, если ни одно
+	// из них не встречается в других описаниях.
+	described bool
+	terms     map[string]float64
+}
+
+func newFeatures(g game.Game) features {
+	f := features{id: g.ID, genres: map[string]bool{}, difficulty: g.DifficultyLevel}
+
+	for _, genre := range strings.FieldsFunc(g.Genre, func(r rune) bool { return r == ',' || r == '/' || r == ';' }) {
+		if genre = strings.ToLower(strings.TrimSpace(genre)); genre != "" {
+			f.genres[genre] = true
+		}
+	}
+
+	if g.MinPlayers != nil {
+		hi := *g.MinPlayers
+		if g.MaxPlayers != nil && *g.MaxPlayers > hi {
+			hi = *g.MaxPlayers
+		}
+		f.players = &[2]float64{float64(*g.MinPlayers), float64(hi + 1)}
+	}
+
+	if lo, hi := g.MinPlayTime, g.MaxPlayTime; lo != nil || hi != nil {
+		if lo == nil {
+			lo = hi
+		}
+		if hi == nil {
+			hi = lo
+		}
+		mid := float64(*lo+*hi) / 2
+		f.playTime = &mid
+	}
+
+	return f
+}
+
+// terms разбивает текст на слова, отбрасывает короткие и служебные и
+// обрезает до основы. Возвращает частоты слов.
+func terms(text string) map[string]int {
+	counts := map[string]int{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if utf8.RuneCountInString(word) < minTermLen || stopWords[word] {
+			continue
+		}
+		if runes := []rune(word); len(runes) > stemLen {
+			word = string(runes[:stemLen])
+		}
+		counts[word]++
+	}
+	return counts
+}
+
+// Compute считает похожесть каждой пары игр каталога и оставляет для каждой
+// игры не больше perGame самых похожих. Описания сравниваются косинусом
+// TF-IDF векторов, поэтому слова, которые есть почти везде, почти не влияют.
+// Пары считаются за квадратичное время, поэтому между играми проверяется ctx.
+func Compute(ctx context.Context, games []game.Game, perGame int) ([]Pair, error) {
+	all := make([]features, len(games))
+	docTerms := make([]map[string]int, len(games))
+	df := map[string]int{}
+	for i, g := range games {
+		all[i] = newFeatures(g)
+		docTerms[i] = terms(g.Description)
+		all[i].described = len(docTerms[i]) > 0
+		for term := range docTerms[i] {
+			df[term]++
+		}
+	}
+
+	for i, counts := range docTerms {
+		vec := make(map[string]float64, len(counts))
+		var norm float64
+		for term, n := range counts {
+			// Слово только из одного описания ни с чем не совпадёт
+			if df[term] < 2 {
+				continue
+			}
+			w := float64(n) * math.Log(float64(len(games))/float64(df[term]))
+			if w > 0 {
+				vec[term] = w
+				norm += w * w
+			}
+		}
+		for term := range vec {
+			vec[term] /= math.Sqrt(norm)
+		}
+		all[i].terms = vec
+	}
+
+	var pairs []Pair
+	best := make(topPairs, 0, perGame+1)
+	for i := range all {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		best = best[:0]
+		for j := range all {
+			if i == j {
+				continue
+			}
+			score := similarity(all[i], all[j])
+			if score <= 0 {
+				continue
+			}
+			p := Pair{GameID: all[i].id, SimilarID: all[j].id, Score: score}
+			if len(best) < perGame {
+				heap.Push(&best, p)
+			} else if len(best) > 0 && worse(best[0], p) {
+				best[0] = p
+				heap.Fix(&best, 0)
+			}
+		}
+
+		start := len(pairs)
+		pairs = append(pairs, best...)
+		found := pairs[start:]
+		sort.Slice(found, func(a, b int) bool { return worse(found[b], found[a]) })
+	}
+
+	return pairs, nil
+}
+
+// worse — пара a похожа меньше, чем b; при равенстве хуже та, у которой
+// больше SimilarID, чтобы порядок был детерминированным.
+func worse(a, b Pair) bool {
+	if a.Score != b.Score {
+		return a.Score < b.Score
+	}
+	return a.SimilarID > b.SimilarID
+}
+
+// topPairs — куча с наименее похожей парой в корне: в ней держатся perGame
+// лучших пар игры, не сортируя всех кандидатов.
+type topPairs []Pair
+
+func (h topPairs) Len() int           { return len(h) }
+func (h topPairs) Less(i, j int) bool { return worse(h[i], h[j]) }
+func (h topPairs) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *topPairs) Push(x any)        { *h = append(*h, x.(Pair)) }
+func (h *topPairs) Pop() any {
+	old := *h
+	p := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return p
+}
+
+// similarity — взвешенное среднее похожести по признакам, которые есть у обеих игр.
+func similarity(a, b features) float64 {
+	var sum, weight float64
+	add := func(w, s float64) {
+		sum += w * s
+		weight += w
+	}
+
+	if len(a.genres) > 0 && len(b.genres) > 0 {
+		add(weightGenre, jaccard(a.genres, b.genres))
+	}
+	if a.described && b.described {
+		add(weightDescription, cosine(a.terms, b.terms))
+	}
+	if a.difficulty != nil && b.difficulty != nil {
+		// Сложность от 1 до 5
+		add(weightDifficulty, math.Max(0, 1-math.Abs(*a.difficulty-*b.difficulty)/4))
+	}
+	if a.players != nil && b.players != nil {
+		add(weightPlayers, overlap(*a.players, *b.players))
+	}
+	if a.playTime != nil && b.playTime != nil {
+		add(weightPlayTime, ratio(*a.playTime, *b.playTime))
+	}
+
+	if weight < minWeight {
+		return 0
+	}
+	return math.Min(sum/weight, 1)
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	var common int
+	for k := range a {
+		if b[k] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// cosine ожидает нормированные векторы.
+func cosine(a, b map[string]float64) float64 {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	var dot float64
+	for term, w := range a {
+		dot += w * b[term]
+	}
+	return math.Min(dot, 1)
+}
+
+// overlap — доля пересечения двух интервалов в их объединении.
+func overlap(a, b [2]float64) float64 {
+	common := math.Min(a[1], b[1]) - math.Max(a[0], b[0])
+	if common <= 0 {
+		return 0
+	}
+	return common / (math.Max(a[1], b[1]) - math.Min(a[0], b[0]))
+}
+
+// ratio — отношение меньшего значения к большему.
+func ratio(a, b float64) float64 {
+	if a <= 0 || b <= 0 {
+		return 0
+	}
+	return math.Min(a, b) / math.Max(a, b)
+}
@@ -0,0 +1,102 @@
+package similar
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/board-box/backend/internal/service/game"
+)
+
+func intPtr(v int) *int { return &v }
+
+func floatPtr(v float64) *float64 { return &v }
+
+func TestTerms(t *testing.T) {
+	got := terms("Стратегия о стратегиях: строим города и дороги, а ещё — города!")
+	want := map[string]int{"страте": 2, "строим": 1, "города": 2, "дороги": 1}
+
+	if len(got) != len(want) {
+		t.Fatalf("terms() = %v, want %v", got, want)
+	}
+	for term, n := range want {
+		if got[term] != n {
+			t.Errorf("terms()[%q] = %d, want %d", term, got[term], n)
+		}
+	}
+}
+
+func TestSimilarityFeatures(t *testing.T) {
+	a := newFeatures(game.Game{
+		ID: 1, Genre: "Стратегия, Экономика", DifficultyLevel: floatPtr(3),
+		MinPlayers: intPtr(2), MaxPlayers: intPtr(4), MinPlayTime: intPtr(60), MaxPlayTime: intPtr(120),
+	})
+
+	if got := similarity(a, a); math.Abs(got-1) > 1e-9 {
+		t.Errorf("similarity with itself = %v, want 1", got)
+	}
+
+	b := newFeatures(game.Game{
+		ID: 2, Genre: "экономика", DifficultyLevel: floatPtr(1),
+		MinPlayers: intPtr(5), MaxPlayers: intPtr(8), MinPlayTime: intPtr(30), MaxPlayTime: intPtr(30),
+	})
+	// Жанры 1/2, сложность 1/2, игроки не пересекаются, время 30/90
+	want := (0.3*0.5 + 0.15*0.5 + 0.15*0 + 0.15*(30.0/90)) / 0.75
+	if got := similarity(a, b); math.Abs(got-want) > 1e-9 {
+		t.Errorf("similarity() = %v, want %v", got, want)
+	}
+
+	// Одной сложности мало, чтобы считать игры похожими
+	c := newFeatures(game.Game{ID: 3, DifficultyLevel: floatPtr(3)})
+	if got := similarity(a, c); got != 0 {
+		t.Errorf("similarity() with only difficulty = %v, want 0", got)
+	}
+}
+
+func TestCompute(t *testing.T) {
+	games := []game.Game{
+		{ID: 1, Genre: "Стратегия", Description: "Строим города и дороги на острове", MinPlayers: intPtr(2), MaxPlayers: intPtr(4)},
+		{ID: 2, Genre: "Стратегия", Description: "Строим города в пустыне", MinPlayers: intPtr(2), MaxPlayers: intPtr(5)},
+		{ID: 3, Genre: "Стратегия", Description: "Торговля пряностями", MinPlayers: intPtr(2), MaxPlayers: intPtr(4)},
+		{ID: 4, Genre: "Вечеринка", Description: "Угадываем слова", MinPlayers: intPtr(4), MaxPlayers: intPtr(8)},
+	}
+
+	pairs, err := Compute(t.Context(), games, 2)
+	if err != nil {
+		t.Fatalf("Compute() error = %v", err)
+	}
+
+	var forFirst []int64
+	for _, p := range pairs {
+		if p.Score <= 0 || p.Score > 1 {
+			t.Errorf("pair %d-%d has score %v", p.GameID, p.SimilarID, p.Score)
+		}
+		if p.GameID == 1 {
+			forFirst = append(forFirst, p.SimilarID)
+		}
+	}
+	// Игра 2 совпадает и по жанру, и по описанию, игра 4 отсекается лимитом
+	if len(forFirst) != 2 || forFirst[0] != 2 || forFirst[1] != 3 {
+		t.Fatalf("similar to 1 = %v, want [2 3]", forFirst)
+	}
+
+	// Без лимита — все совпадения, самые похожие первыми
+	if pairs, err = Compute(t.Context(), games, 10); err != nil {
+		t.Fatalf("Compute() error = %v", err)
+	}
+	for i := 1; i < len(pairs); i++ {
+		if prev := pairs[i-1]; prev.GameID == pairs[i].GameID && prev.Score < pairs[i].Score {
+			t.Errorf("pairs of %d are not ordered: %+v", prev.GameID, pairs)
+		}
+	}
+	if len(pairs) <= 8 {
+		t.Errorf("pairs without limit = %d, want more than 8", len(pairs))
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err = Compute(ctx, games, 2); !errors.Is(err, context.Canceled) {
+		t.Errorf("Compute() with cancelled ctx error = %v, want context.Canceled", err)
+	}
+}
@@ -1,8 +1,32 @@
 package user
 
+import (
+	"time"
+
+	"github.com/board-box/backend/internal/auth"
+)
+
 type User struct {
-	ID           int64  `json:"id" db:"id"`
-	Email        string `json:"email" db:"email"`
-	Username     string `json:"username" db:"username"`
-	PasswordHash string `json:"-" db:"password_hash"`
+	ID           int64       `json:"id" db:"id"`
+	Email        string      `json:"email" db:"email"`
+	Username     string      `json:"username" db:"username"`
+	PasswordHash string      `json:"-" db:"password_hash"`
+	Roles        []auth.Role `json:"roles" db:"-"`
+}
+
+// Session — устройство, на котором выполнен вход. Хранит только хэш
+// refresh-токена; при каждом обновлении токен меняется.
+type Session struct {
+	ID               string     `db:"id"`
+	UserID           int64      `db:"user_id"`
+	RefreshTokenHash string     `db:"refresh_token_hash"`
+	UserAgent        string     `db:"user_agent"`
+	ExpiresAt        time.Time  `db:"expires_at"`
+	RevokedAt        *time.Time `db:"revoked_at"`
+}
+
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    time.Duration
 }
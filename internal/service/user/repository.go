@@ -3,34 +3,123 @@ package user
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/Masterminds/squirrel"
+	"github.com/board-box/backend/internal/auth"
+	"github.com/board-box/backend/internal/db"
 	"github.com/georgysavva/scany/v2/pgxscan"
 	pgx "github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
-const userTableName = "users"
+const (
+	userTableName         = "users"
+	userRoleTableName     = "user_role"
+	sessionTableName      = "user_session"
+	revokedTokenTableName = "revoked_token"
+)
 
 var (
 	psql = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 
-	ErrUserExists = errors.New("user already exists")
+	ErrUserExists      = errors.New("user already exists")
+	ErrSessionNotFound = errors.New("session not found")
 )
 
+// Repository — хранилище пользователей, ролей и сессий.
+type Repository interface {
+	SaveUser(ctx context.Context, user User) (int64, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserByID(ctx context.Context, id int64) (User, error)
+	UpdatePassword(ctx context.Context, userID int64, passwordHash string) error
+
+	GetUserRoles(ctx context.Context, userID int64) ([]auth.Role, error)
+	AddUserRole(ctx context.Context, userID int64, role auth.Role) error
+	RemoveUserRole(ctx context.Context, userID int64, role auth.Role) error
+	LockRoleHolders(ctx context.Context, role auth.Role) ([]int64, error)
+
+	CreateSession(ctx context.Context, session Session) error
+	RotateSession(ctx context.Context, oldHash, newHash string, expiresAt time.Time) (Session, error)
+	RevokeSession(ctx context.Context, sessionID string) error
+	RevokeUserSessions(ctx context.Context, userID int64) error
+	RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, sessionID, tokenID string) (bool, error)
+}
+
 type repository struct {
-	db *pgx.Conn
+	db db.DB
 }
 
-func newRepository(db *pgx.Conn) *repository {
+func NewRepository(db db.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) saveUser(ctx context.Context, user User) error {
+func (r *repository) SaveUser(ctx context.Context, user User) (int64, error) {
+	var id int64
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		query, args, err := psql.
+			Insert(userTableName).
+			Columns("email", "username", "password_hash").
+			Values(user.Email, user.Username, user.PasswordHash).
+			Suffix("RETURNING id").
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		if err = tx.QueryRow(ctx, query, args...).Scan(&id); err != nil {
+			return err
+		}
+
+		// Каждый пользователь получает базовую роль при регистрации
+		query, args, err = psql.
+			Insert(userRoleTableName).
+			Columns("user_id", "role").
+			Values(id, auth.RoleUser).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, query, args...)
+		return err
+	})
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return 0, ErrUserExists
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *repository) GetUserRoles(ctx context.Context, userID int64) ([]auth.Role, error) {
 	query, args, err := psql.
-		Insert(userTableName).
-		Columns("email", "username", "password_hash").
-		Values(user.Email, user.Username, user.PasswordHash).
+		Select("role").
+		From(userRoleTableName).
+		Where(squirrel.Eq{"user_id": userID}).
+		OrderBy("role ASC").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var roles []auth.Role
+	if err = pgxscan.Select(ctx, r.db, &roles, query, args...); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+func (r *repository) AddUserRole(ctx context.Context, userID int64, role auth.Role) error {
+	query, args, err := psql.
+		Insert(userRoleTableName).
+		Columns("user_id", "role").
+		Values(userID, role).
+		Suffix("ON CONFLICT DO NOTHING").
 		ToSql()
 	if err != nil {
 		return err
@@ -38,8 +127,8 @@ func (r *repository) saveUser(ctx context.Context, user User) error {
 
 	_, err = r.db.Exec(ctx, query, args...)
 	if err != nil {
-		if isDuplicateKeyError(err) {
-			return ErrUserExists
+		if isForeignKeyError(err) {
+			return ErrUserNotFound
 		}
 		return err
 	}
@@ -47,7 +136,41 @@ func (r *repository) saveUser(ctx context.Context, user User) error {
 	return nil
 }
 
-func (r *repository) getUserByEmail(ctx context.Context, email string) (User, error) {
+func (r *repository) RemoveUserRole(ctx context.Context, userID int64, role auth.Role) error {
+	query, args, err := psql.
+		Delete(userRoleTableName).
+		Where(squirrel.Eq{"user_id": userID, "role": role}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, query, args...)
+	return err
+}
+
+// LockRoleHolders возвращает пользователей с ролью и блокирует их строки
+// до конца транзакции, чтобы параллельный отзыв роли дождался текущего.
+func (r *repository) LockRoleHolders(ctx context.Context, role auth.Role) ([]int64, error) {
+	query, args, err := psql.
+		Select("user_id").
+		From(userRoleTableName).
+		Where(squirrel.Eq{"role": role}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+	if err = pgxscan.Select(ctx, r.db, &ids, query, args...); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (r *repository) GetUserByEmail(ctx context.Context, email string) (User, error) {
 	query, args, err := psql.
 		Select("id", "email", "username", "password_hash").
 		From(userTableName).
@@ -69,7 +192,7 @@ func (r *repository) getUserByEmail(ctx context.Context, email string) (User, er
 	return user, nil
 }
 
-func (r *repository) getUserByID(ctx context.Context, id int64) (User, error) {
+func (r *repository) GetUserByID(ctx context.Context, id int64) (User, error) {
 	query, args, err := psql.
 		Select("id", "email", "username", "password_hash").
 		From(userTableName).
@@ -88,6 +211,28 @@ func (r *repository) getUserByID(ctx context.Context, id int64) (User, error) {
 	return user, nil
 }
 
+func (r *repository) UpdatePassword(ctx context.Context, userID int64, passwordHash string) error {
+	query, args, err := psql.
+		Update(userTableName).
+		Set("password_hash", passwordHash).
+		Where(squirrel.Eq{"id": userID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
 // UpdateUser обновляет данные пользователя
 func (r *repository) updateUser(ctx context.Context, user User) error {
 	query, args, err := psql.
@@ -118,6 +263,132 @@ func (r *repository) deleteUser(ctx context.Context, id int64) error {
 	return err
 }
 
+func (r *repository) CreateSession(ctx context.Context, session Session) error {
+	query, args, err := psql.
+		Insert(sessionTableName).
+		Columns("id", "user_id", "refresh_token_hash", "user_agent", "expires_at").
+		Values(session.ID, session.UserID, session.RefreshTokenHash, session.UserAgent, session.ExpiresAt).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, query, args...)
+	return err
+}
+
+// RotateSession атомарно меняет хэш refresh-токена активной сессии. Если токен
+// уже был заменён ранее, значит его украли и переиспользовали — сессия отзывается.
+func (r *repository) RotateSession(ctx context.Context, oldHash, newHash string, expiresAt time.Time) (Session, error) {
+	query, args, err := psql.
+		Update(sessionTableName).
+		Set("previous_token_hash", squirrel.Expr("refresh_token_hash")).
+		Set("refresh_token_hash", newHash).
+		Set("expires_at", expiresAt).
+		Set("updated_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{"refresh_token_hash": oldHash, "revoked_at": nil}).
+		Where("expires_at > NOW()").
+		Suffix("RETURNING id, user_id, refresh_token_hash, user_agent, expires_at, revoked_at").
+		ToSql()
+	if err != nil {
+		return Session{}, err
+	}
+
+	var session Session
+	err = pgxscan.Get(ctx, r.db, &session, query, args...)
+	if err == nil {
+		return session, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return Session{}, err
+	}
+
+	query, args, err = psql.
+		Update(sessionTableName).
+		Set("revoked_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{"previous_token_hash": oldHash, "revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		return Session{}, err
+	}
+
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		return Session{}, err
+	}
+
+	return Session{}, ErrSessionNotFound
+}
+
+func (r *repository) RevokeSession(ctx context.Context, sessionID string) error {
+	query, args, err := psql.
+		Update(sessionTableName).
+		Set("revoked_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{"id": sessionID, "revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, query, args...)
+	return err
+}
+
+func (r *repository) RevokeUserSessions(ctx context.Context, userID int64) error {
+	query, args, err := psql.
+		Update(sessionTableName).
+		Set("revoked_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{"user_id": userID, "revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, query, args...)
+	return err
+}
+
+// RevokeToken заносит access-токен в чёрный список до истечения его срока
+// и заодно чистит записи, которые уже не нужны.
+func (r *repository) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	query, args, err := psql.
+		Insert(revokedTokenTableName).
+		Columns("jti", "expires_at").
+		Values(tokenID, expiresAt).
+		Suffix("ON CONFLICT DO NOTHING").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	query, args, err = psql.
+		Delete(revokedTokenTableName).
+		Where("expires_at < NOW()").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, query, args...)
+	return err
+}
+
+func (r *repository) IsRevoked(ctx context.Context, sessionID, tokenID string) (bool, error) {
+	const query = `
+		SELECT EXISTS (SELECT 1 FROM ` + revokedTokenTableName + ` WHERE jti = $1)
+			OR NOT EXISTS (SELECT 1 FROM ` + sessionTableName + ` WHERE id = $2 AND revoked_at IS NULL)`
+
+	var revoked bool
+	if err := r.db.QueryRow(ctx, query, tokenID, sessionID).Scan(&revoked); err != nil {
+		return false, err
+	}
+
+	return revoked, nil
+}
+
 func isDuplicateKeyError(err error) bool {
 	var pgErr *pgconn.PgError
 	if errors.As(err, &pgErr) {
@@ -125,3 +396,11 @@ func isDuplicateKeyError(err error) bool {
 	}
 	return false
 }
+
+func isForeignKeyError(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23503" // foreign_key_violation
+	}
+	return false
+}
@@ -4,64 +4,284 @@ import (
 	"context"
 	"errors"
 	"math/rand"
+	"time"
 
 	"github.com/board-box/backend/internal/auth"
+	"github.com/board-box/backend/internal/db"
 	pgx "github.com/jackc/pgx/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const maxUserAgentLen = 255
+
 var (
-	ErrUnauthorized = errors.New("unauthorized")
-	ErrUserNotFound = errors.New("user not found")
+	ErrUnauthorized  = errors.New("unauthorized")
+	ErrUserNotFound  = errors.New("user not found")
+	ErrInvalidRole   = errors.New("invalid role")
+	ErrBaseRole      = errors.New("base role cannot be removed")
+	ErrLastAdmin     = errors.New("last admin cannot be removed")
+	ErrEmptyPassword = errors.New("password is empty")
 )
 
+// CollectionCreator создаёт системные коллекции нового пользователя.
+type CollectionCreator interface {
+	CreateSystemCollections(ctx context.Context, userID int64) error
+}
+
 type Service struct {
-	repo *repository
-	jwt  *auth.JWTManager
+	repo        Repository
+	tx          db.TxManager
+	jwt         *auth.JWTManager
+	collections CollectionCreator
 }
 
-func NewService(db *pgx.Conn, jwt *auth.JWTManager) *Service {
+func NewService(repo Repository, tx db.TxManager, jwt *auth.JWTManager, collections CollectionCreator) *Service {
 	return &Service{
-		repo: newRepository(db),
-		jwt:  jwt,
+		repo:        repo,
+		tx:          tx,
+		jwt:         jwt,
+		collections: collections,
 	}
 }
 
+// Register создаёт пользователя вместе с его системными коллекциями.
 func (s *Service) Register(ctx context.Context, username, email, password string) error {
 	hashed, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	user := User{ID: rand.Int63(), Username: username, Email: email, PasswordHash: string(hashed)} // nolint:gosec
 
-	err := s.repo.saveUser(ctx, user)
+	return s.tx.WithTx(ctx, func(ctx context.Context) error {
+		id, err := s.repo.SaveUser(ctx, user)
+		if err != nil {
+			return err
+		}
+		return s.collections.CreateSystemCollections(ctx, id)
+	})
+}
+
+// Login проверяет пароль и открывает новую сессию.
+func (s *Service) Login(ctx context.Context, email, password, userAgent string) (Tokens, error) {
+	user, err := s.repo.GetUserByEmail(ctx, email)
 	if err != nil {
-		return err
+		return Tokens{}, ErrUnauthorized
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+	if err != nil {
+		return Tokens{}, ErrUnauthorized
 	}
 
-	return nil
+	refreshToken, refreshHash, err := auth.GenerateRefreshToken()
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	session := Session{
+		ID:               auth.RandomID(),
+		UserID:           user.ID,
+		RefreshTokenHash: refreshHash,
+		UserAgent:        truncate(userAgent, maxUserAgentLen),
+		ExpiresAt:        time.Now().Add(s.jwt.RefreshDuration),
+	}
+	if err = s.repo.CreateSession(ctx, session); err != nil {
+		return Tokens{}, err
+	}
+
+	return s.issueTokens(ctx, session, refreshToken)
 }
 
-func (s *Service) Login(ctx context.Context, email, password string) (string, error) {
-	user, err := s.repo.getUserByEmail(ctx, email)
+// Refresh меняет refresh-токен на новую пару токенов. Старый refresh-токен
+// становится недействительным.
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (Tokens, error) {
+	newToken, newHash, err := auth.GenerateRefreshToken()
 	if err != nil {
-		return "", ErrUnauthorized
+		return Tokens{}, err
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+	session, err := s.repo.RotateSession(ctx, auth.HashToken(refreshToken), newHash, time.Now().Add(s.jwt.RefreshDuration))
+	if err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return Tokens{}, ErrUnauthorized
+		}
+		return Tokens{}, err
+	}
+
+	return s.issueTokens(ctx, session, newToken)
+}
+
+// Logout завершает текущую сессию и отзывает предъявленный access-токен.
+func (s *Service) Logout(ctx context.Context, sessionID, tokenID string, tokenExpiresAt time.Time) error {
+	return s.tx.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.RevokeSession(ctx, sessionID); err != nil {
+			return err
+		}
+		return s.repo.RevokeToken(ctx, tokenID, tokenExpiresAt)
+	})
+}
+
+// LogoutAll завершает все сессии пользователя на всех устройствах.
+func (s *Service) LogoutAll(ctx context.Context, userID int64) error {
+	return s.repo.RevokeUserSessions(ctx, userID)
+}
+
+// IsRevoked реализует auth.RevocationChecker.
+func (s *Service) IsRevoked(ctx context.Context, sessionID, tokenID string) (bool, error) {
+	if sessionID == "" {
+		return true, nil
+	}
+	return s.repo.IsRevoked(ctx, sessionID, tokenID)
+}
+
+func (s *Service) issueTokens(ctx context.Context, session Session, refreshToken string) (Tokens, error) {
+	roles, err := s.repo.GetUserRoles(ctx, session.UserID)
 	if err != nil {
-		return "", ErrUnauthorized
+		return Tokens{}, err
 	}
 
-	token, err := s.jwt.GenerateToken(s.jwt.SecretKey, user.ID)
+	accessToken, err := s.jwt.GenerateToken(s.jwt.SecretKey, session.UserID, session.ID, roles)
 	if err != nil {
-		return "", err
+		return Tokens{}, err
 	}
 
-	return token, nil
+	return Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    s.jwt.TokenDuration,
+	}, nil
 }
 
 func (s *Service) Info(ctx context.Context, userID int64) (User, error) {
-	user, err := s.repo.getUserByID(ctx, userID)
+	user, err := s.repo.GetUserByID(ctx, userID)
 	if err != nil {
 		return User{}, ErrUserNotFound
 	}
+
+	user.Roles, err = s.repo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return User{}, err
+	}
+
 	return user, nil
 }
+
+func (s *Service) FindByEmail(ctx context.Context, email string) (User, error) {
+	user, err := s.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return User{}, ErrUserNotFound
+		}
+		return User{}, err
+	}
+
+	return user, nil
+}
+
+// CreateAdmin выдаёт роль admin пользователю с указанным email. Если такого
+// пользователя нет, он регистрируется с переданными именем и паролем.
+func (s *Service) CreateAdmin(ctx context.Context, username, email, password string) (User, error) {
+	var user User
+	err := s.tx.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = s.repo.GetUserByEmail(ctx, email)
+		if err != nil {
+			if !errors.Is(err, pgx.ErrNoRows) {
+				return err
+			}
+			if password == "" {
+				return ErrEmptyPassword
+			}
+
+			hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+			if err != nil {
+				return err
+			}
+
+			user = User{Username: username, Email: email, PasswordHash: string(hashed)}
+			if user.ID, err = s.repo.SaveUser(ctx, user); err != nil {
+				return err
+			}
+			if err = s.collections.CreateSystemCollections(ctx, user.ID); err != nil {
+				return err
+			}
+		}
+
+		if err = s.repo.AddUserRole(ctx, user.ID, auth.RoleAdmin); err != nil {
+			return err
+		}
+
+		user.Roles, err = s.repo.GetUserRoles(ctx, user.ID)
+		return err
+	})
+	if err != nil {
+		return User{}, err
+	}
+
+	return user, nil
+}
+
+// ResetPassword задаёт пользователю новый пароль и завершает все его сессии.
+func (s *Service) ResetPassword(ctx context.Context, email, password string) error {
+	if password == "" {
+		return ErrEmptyPassword
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return s.tx.WithTx(ctx, func(ctx context.Context) error {
+		user, err := s.repo.GetUserByEmail(ctx, email)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		if err = s.repo.UpdatePassword(ctx, user.ID, string(hashed)); err != nil {
+			return err
+		}
+		return s.repo.RevokeUserSessions(ctx, user.ID)
+	})
+}
+
+// AddRole выдаёт роль пользователю. Роли применяются после повторного входа.
+func (s *Service) AddRole(ctx context.Context, userID int64, role auth.Role) error {
+	if !role.Valid() {
+		return ErrInvalidRole
+	}
+	return s.repo.AddUserRole(ctx, userID, role)
+}
+
+// RemoveRole отзывает роль у пользователя. Базовую роль user отозвать
+// нельзя, как и роль admin у последнего администратора.
+func (s *Service) RemoveRole(ctx context.Context, userID int64, role auth.Role) error {
+	if !role.Valid() {
+		return ErrInvalidRole
+	}
+	if role == auth.RoleUser {
+		return ErrBaseRole
+	}
+	if role != auth.RoleAdmin {
+		return s.repo.RemoveUserRole(ctx, userID, role)
+	}
+
+	return s.tx.WithTx(ctx, func(ctx context.Context) error {
+		admins, err := s.repo.LockRoleHolders(ctx, auth.RoleAdmin)
+		if err != nil {
+			return err
+		}
+		if len(admins) == 1 && admins[0] == userID {
+			return ErrLastAdmin
+		}
+		return s.repo.RemoveUserRole(ctx, userID, role)
+	})
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,224 @@
+// Package testdb поднимает Postgres для интеграционных тестов и накатывает
+// на него встроенные миграции.
+//
+// Если задан TEST_PG_DSN, используется этот сервер: для каждого тестового
+// пакета создаётся отдельная временная база. Иначе запускается
+// embedded-postgres (бинарники скачиваются при первом запуске, отключить —
+// TEST_PG_EMBEDDED=off). Если Postgres недоступен, тесты с базой пропускаются.
+package testdb
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/board-box/backend/internal/migrator"
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	envDSN      = "TEST_PG_DSN"
+	envEmbedded = "TEST_PG_EMBEDDED"
+
+	startTimeout = 2 * time.Minute
+)
+
+var (
+	once    sync.Once
+	pool    *pgxpool.Pool
+	stop    func()
+	initErr error
+)
+
+// Main запускает тесты пакета и останавливает базу после них.
+// Вызывается из TestMain: os.Exit(testdb.Main(m)).
+func Main(m *testing.M) int {
+	code := m.Run()
+	if stop != nil {
+		stop()
+	}
+	return code
+}
+
+// Pool возвращает пул соединений с базой, в которой накатаны все миграции,
+// а таблицы пусты. База общая для тестов пакета, поэтому тесты с ней не
+// должны вызывать t.Parallel.
+func Pool(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+
+	once.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+		defer cancel()
+		pool, stop, initErr = start(ctx)
+	})
+	if initErr != nil {
+		t.Skipf("postgres is not available: %v", initErr)
+	}
+
+	if err := truncate(context.Background(), pool); err != nil {
+		t.Fatalf("truncate tables: %v", err)
+	}
+
+	return pool
+}
+
+func start(ctx context.Context) (*pgxpool.Pool, func(), error) {
+	cfg, cleanup, err := database(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	if err = migrator.Up(ctx, p, io.Discard); err != nil {
+		p.Close()
+		cleanup()
+		return nil, nil, fmt.Errorf("apply migrations: %w", err)
+	}
+
+	return p, func() {
+		p.Close()
+		cleanup()
+	}, nil
+}
+
+// database готовит пустую базу и возвращает настройки пула к ней и функцию,
+// которая её удаляет.
+func database(ctx context.Context) (*pgxpool.Config, func(), error) {
+	if dsn := os.Getenv(envDSN); dsn != "" {
+		return createDatabase(ctx, dsn)
+	}
+
+	if v := strings.ToLower(os.Getenv(envEmbedded)); v == "off" || v == "0" || v == "false" {
+		return nil, nil, fmt.Errorf("%s is not set and embedded postgres is disabled", envDSN)
+	}
+
+	return startEmbedded()
+}
+
+func createDatabase(ctx context.Context, dsn string) (*pgxpool.Config, func(), error) {
+	cfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid %s: %w", envDSN, err)
+	}
+	admin := cfg.ConnConfig.Copy()
+
+	conn, err := pgx.ConnectConfig(ctx, admin)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer conn.Close(ctx)
+
+	name := "boardbox_test_" + randomSuffix()
+	if _, err = conn.Exec(ctx, "CREATE DATABASE "+name); err != nil {
+		return nil, nil, err
+	}
+
+	cleanup := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		conn, err := pgx.ConnectConfig(ctx, admin)
+		if err != nil {
+			return
+		}
+		defer conn.Close(ctx)
+		_, _ = conn.Exec(ctx, "DROP DATABASE IF EXISTS "+name+" WITH (FORCE)")
+	}
+
+	cfg.ConnConfig.Database = name
+	return cfg, cleanup, nil
+}
+
+func startEmbedded() (*pgxpool.Config, func(), error) {
+	port, err := freePort()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "boardbox-pg-")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pgCfg := embeddedpostgres.DefaultConfig().
+		Port(port).
+		Username("boardbox").
+		Password("boardbox").
+		Database("boardbox").
+		RuntimePath(filepath.Join(dir, "runtime")).
+		DataPath(filepath.Join(dir, "data")).
+		StartTimeout(startTimeout).
+		Logger(io.Discard)
+
+	pg := embeddedpostgres.NewDatabase(pgCfg)
+	if err = pg.Start(); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, nil, fmt.Errorf("start embedded postgres: %w", err)
+	}
+
+	cleanup := func() {
+		_ = pg.Stop()
+		_ = os.RemoveAll(dir)
+	}
+
+	cfg, err := pgxpool.ParseConfig(pgCfg.GetConnectionURL() + "?sslmode=disable")
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	return cfg, cleanup, nil
+}
+
+// truncate очищает все таблицы, кроме служебной таблицы goose.
+func truncate(ctx context.Context, p *pgxpool.Pool) error {
+	rows, err := p.Query(ctx, `
+		SELECT quote_ident(tablename) FROM pg_tables
+		WHERE schemaname = 'public' AND tablename <> 'goose_db_version'`)
+	if err != nil {
+		return err
+	}
+
+	tables, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return err
+	}
+	if len(tables) == 0 {
+		return errors.New("no tables found, migrations were not applied")
+	}
+
+	_, err = p.Exec(ctx, "TRUNCATE "+strings.Join(tables, ", ")+" RESTART IDENTITY CASCADE")
+	return err
+}
+
+func freePort() (uint32, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return uint32(l.Addr().(*net.TCPAddr).Port), nil
+}
+
+func randomSuffix() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
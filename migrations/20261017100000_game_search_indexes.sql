@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX idx_game_search ON game
+    USING GIN (to_tsvector('russian', coalesce(title, '') || ' ' || coalesce(description, '')));
+
+CREATE INDEX idx_game_genre ON game(genre);
+CREATE INDEX idx_game_age ON game(age);
+CREATE INDEX idx_game_person ON game(person);
+CREATE INDEX idx_game_avg_time ON game(avg_time);
+CREATE INDEX idx_game_difficulty ON game(difficulty);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_game_difficulty;
+DROP INDEX IF EXISTS idx_game_avg_time;
+DROP INDEX IF EXISTS idx_game_person;
+DROP INDEX IF EXISTS idx_game_age;
+DROP INDEX IF EXISTS idx_game_genre;
+DROP INDEX IF EXISTS idx_game_search;
+-- +goose StatementEnd
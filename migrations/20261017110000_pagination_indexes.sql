@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX idx_game_title_id ON game(title, id);
+CREATE INDEX idx_collection_user_order ON collection(user_id, pinned DESC, name, id);
+CREATE INDEX idx_collection_game_order ON collection_game(collection_id, added_at, game_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_collection_game_order;
+DROP INDEX IF EXISTS idx_collection_user_order;
+DROP INDEX IF EXISTS idx_game_title_id;
+-- +goose StatementEnd
@@ -0,0 +1,154 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE game
+    ADD COLUMN min_players SMALLINT CHECK (min_players > 0),
+    ADD COLUMN max_players SMALLINT CHECK (max_players > 0),
+    ADD COLUMN min_play_time INTEGER CHECK (min_play_time > 0),
+    ADD COLUMN max_play_time INTEGER CHECK (max_play_time > 0),
+    ADD COLUMN min_age SMALLINT CHECK (min_age >= 0),
+    ADD COLUMN difficulty_level NUMERIC(2, 1) CHECK (difficulty_level BETWEEN 1 AND 5);
+
+-- Игроки: "2-4", "от 2 до 4", "2". Плюс в конце ("2+") — без верхней границы.
+UPDATE game SET
+    min_players = NULLIF((regexp_match(person, '(\d{1,3})'))[1]::SMALLINT, 0),
+    max_players = CASE
+        WHEN person ~ '\+\s*$' THEN NULL
+        ELSE NULLIF((regexp_match(person, '(\d{1,3})\D*$'))[1]::SMALLINT, 0)
+    END
+WHERE person ~ '\d';
+
+-- Время: "30-60 мин", "1.5 часа", "1-2 часа", "1 час 30 мин" — те же правила,
+-- что у parsePlayTime. Число без единицы берёт единицу следующего числа,
+-- последнее — минуты; минуты сразу после часов без разделителя прибавляются.
+CREATE FUNCTION game_parse_play_time(s TEXT) RETURNS INTEGER[] AS $$
+DECLARE
+    rest TEXT := s;
+    m TEXT[];
+    prev_word TEXT := '';
+    vals NUMERIC[] := '{}';
+    units TEXT[] := '{}';
+    gaps TEXT[] := '{}';
+    minutes NUMERIC[] := '{}';
+    carry TEXT := 'min';
+    n INT;
+BEGIN
+    LOOP
+        m := regexp_match(rest, '^([^0-9]*)([0-9]{1,3}(?:[.,][0-9]+)?)(\s*)([а-яёА-ЯЁa-zA-Z]*)');
+        EXIT WHEN m IS NULL;
+
+        vals := array_append(vals, replace(m[2], ',', '.')::NUMERIC);
+        units := array_append(units, CASE
+            WHEN m[4] ~* '^(час|hour|ч$|h$|hrs?$)' THEN 'hour'
+            WHEN m[4] ~* '^(мин|min|м$|m$)' THEN 'min'
+        END);
+        gaps := array_append(gaps, prev_word || m[1]);
+        prev_word := m[4];
+        rest := substr(rest, length(m[1]) + length(m[2]) + length(m[3]) + length(m[4]) + 1);
+    END LOOP;
+
+    n := coalesce(array_length(vals, 1), 0);
+    IF n = 0 THEN
+        RETURN NULL;
+    END IF;
+
+    FOR i IN REVERSE n..1 LOOP
+        IF units[i] IS NULL THEN
+            units[i] := carry;
+        ELSE
+            carry := units[i];
+        END IF;
+    END LOOP;
+
+    FOR i IN 1..n LOOP
+        IF i > 1 AND units[i - 1] = 'hour' AND units[i] = 'min' AND gaps[i] !~* '(-|–|—|до|to)' THEN
+            minutes[array_length(minutes, 1)] := minutes[array_length(minutes, 1)] + vals[i];
+        ELSE
+            minutes := array_append(minutes, vals[i] * CASE WHEN units[i] = 'hour' THEN 60 ELSE 1 END);
+        END IF;
+    END LOOP;
+
+    RETURN ARRAY[round(minutes[1]), round(minutes[array_length(minutes, 1)])]::INTEGER[];
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+UPDATE game SET
+    min_play_time = NULLIF(parsed.minutes[1], 0),
+    max_play_time = NULLIF(parsed.minutes[2], 0)
+FROM (SELECT id, game_parse_play_time(avg_time) AS minutes FROM game WHERE avg_time ~ '\d') AS parsed
+WHERE game.id = parsed.id;
+
+DROP FUNCTION game_parse_play_time(TEXT);
+
+-- Возраст: "12+", "от 8 лет".
+UPDATE game SET min_age = (regexp_match(age, '(\d{1,3})'))[1]::SMALLINT
+WHERE age ~ '\d';
+
+-- Сложность: число по шкале 1-5 ("2.5", "3/5"), по шкале 10 ("7/10") или словами.
+UPDATE game SET difficulty_level = CASE WHEN parsed.level BETWEEN 1 AND 5 THEN parsed.level END
+FROM (
+    SELECT id, CASE
+        WHEN difficulty ~ '\d{1,2}([.,]\d+)?\s*/\s*10' THEN
+            replace((regexp_match(difficulty, '(\d{1,2}(?:[.,]\d+)?)'))[1], ',', '.')::NUMERIC / 2
+        WHEN difficulty ~ '\d' THEN
+            replace((regexp_match(difficulty, '(\d{1,2}(?:[.,]\d+)?)'))[1], ',', '.')::NUMERIC
+        WHEN difficulty ~* 'очень\s+сложн' THEN 5
+        WHEN difficulty ~* '(сложн|трудн|высок)' THEN 4
+        WHEN difficulty ~* 'средн' THEN 3
+        WHEN difficulty ~* '(л[её]гк|прост|низк)' THEN 1.5
+    END AS level
+    FROM game
+    WHERE coalesce(difficulty, '') <> ''
+) AS parsed
+WHERE game.id = parsed.id;
+
+-- Отчёт о значениях, которые не удалось разобрать.
+CREATE TABLE game_attribute_backfill_issue (
+    game_id BIGINT NOT NULL,
+    attribute VARCHAR(50) NOT NULL,
+    raw_value VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (game_id, attribute)
+);
+
+INSERT INTO game_attribute_backfill_issue (game_id, attribute, raw_value)
+SELECT id, 'person', person FROM game WHERE coalesce(person, '') <> '' AND min_players IS NULL
+UNION ALL
+SELECT id, 'avg_time', avg_time FROM game WHERE coalesce(avg_time, '') <> '' AND min_play_time IS NULL
+UNION ALL
+SELECT id, 'age', age FROM game WHERE coalesce(age, '') <> '' AND min_age IS NULL
+UNION ALL
+SELECT id, 'difficulty', difficulty FROM game WHERE coalesce(difficulty, '') <> '' AND difficulty_level IS NULL;
+
+DROP INDEX IF EXISTS idx_game_age;
+DROP INDEX IF EXISTS idx_game_person;
+DROP INDEX IF EXISTS idx_game_avg_time;
+DROP INDEX IF EXISTS idx_game_difficulty;
+
+CREATE INDEX idx_game_players ON game(min_players, max_players);
+CREATE INDEX idx_game_play_time ON game(max_play_time);
+CREATE INDEX idx_game_min_age ON game(min_age);
+CREATE INDEX idx_game_difficulty_level ON game(difficulty_level);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_game_difficulty_level;
+DROP INDEX IF EXISTS idx_game_min_age;
+DROP INDEX IF EXISTS idx_game_play_time;
+DROP INDEX IF EXISTS idx_game_players;
+
+CREATE INDEX idx_game_age ON game(age);
+CREATE INDEX idx_game_person ON game(person);
+CREATE INDEX idx_game_avg_time ON game(avg_time);
+CREATE INDEX idx_game_difficulty ON game(difficulty);
+
+DROP TABLE IF EXISTS game_attribute_backfill_issue;
+
+ALTER TABLE game
+    DROP COLUMN difficulty_level,
+    DROP COLUMN min_age,
+    DROP COLUMN max_play_time,
+    DROP COLUMN min_play_time,
+    DROP COLUMN max_players,
+    DROP COLUMN min_players;
+-- +goose StatementEnd
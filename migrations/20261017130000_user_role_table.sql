@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Роли пользователей. Пока есть только admin: у обычных пользователей строк нет.
+CREATE TABLE user_role (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CONSTRAINT user_role_role_check CHECK (role IN ('admin')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, role)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_role;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Базовая роль user у каждого пользователя и роль moderator
+ALTER TABLE user_role DROP CONSTRAINT user_role_role_check;
+ALTER TABLE user_role ADD CONSTRAINT user_role_role_check CHECK (role IN ('user', 'moderator', 'admin'));
+
+INSERT INTO user_role (user_id, role) SELECT id, 'user' FROM users;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM user_role WHERE role IN ('user', 'moderator');
+
+ALTER TABLE user_role DROP CONSTRAINT user_role_role_check;
+ALTER TABLE user_role ADD CONSTRAINT user_role_role_check CHECK (role IN ('admin'));
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE chat_message ADD COLUMN game_ids BIGINT[];
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chat_message DROP COLUMN IF EXISTS game_ids;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- external_id — идентификатор игры во внешнем источнике импорта, например "bgg:13".
+-- Ссылки на картинки и правила из BGG не помещаются в 100 символов.
+ALTER TABLE game
+    ADD COLUMN external_id VARCHAR(100),
+    ALTER COLUMN image TYPE TEXT,
+    ALTER COLUMN rules TYPE TEXT;
+
+CREATE UNIQUE INDEX idx_game_external_id ON game(external_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_game_external_id;
+
+ALTER TABLE game
+    DROP COLUMN IF EXISTS external_id,
+    ALTER COLUMN image TYPE VARCHAR(100) USING left(image, 100),
+    ALTER COLUMN rules TYPE VARCHAR(100) USING left(rules, 100);
+-- +goose StatementEnd
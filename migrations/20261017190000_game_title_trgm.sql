@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_game_title_trgm ON game USING GIN (lower(title) gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_game_title_trgm;
+
+DROP EXTENSION IF EXISTS pg_trgm;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE collection
+    ADD COLUMN visibility VARCHAR(16) NOT NULL DEFAULT 'private'
+        CHECK (visibility IN ('private', 'unlisted', 'public')),
+    ADD COLUMN share_slug VARCHAR(32);
+
+CREATE UNIQUE INDEX idx_collection_share_slug ON collection(share_slug);
+CREATE INDEX idx_collection_public ON collection(user_id, pinned DESC, name, id) WHERE visibility = 'public';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_collection_public;
+DROP INDEX IF EXISTS idx_collection_share_slug;
+
+ALTER TABLE collection
+    DROP COLUMN IF EXISTS share_slug,
+    DROP COLUMN IF EXISTS visibility;
+-- +goose StatementEnd
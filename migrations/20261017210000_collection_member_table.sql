@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE collection_member (
+    collection_id BIGINT NOT NULL REFERENCES collection(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('viewer', 'editor', 'owner')),
+    invited_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    invited_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- accepted_at пуст, пока приглашение не принято
+    accepted_at TIMESTAMPTZ,
+    PRIMARY KEY (collection_id, user_id)
+);
+
+CREATE INDEX idx_collection_member_user ON collection_member(user_id);
+
+INSERT INTO collection_member (collection_id, user_id, role, accepted_at)
+SELECT id, user_id, 'owner', created_at FROM collection;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS collection_member;
+-- +goose StatementEnd
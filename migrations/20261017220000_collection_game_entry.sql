@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE collection_game
+    ADD COLUMN position INT NOT NULL DEFAULT 0,
+    ADD COLUMN note TEXT,
+    ADD COLUMN condition VARCHAR(16)
+        CHECK (condition IN ('new', 'like_new', 'good', 'fair', 'poor')),
+    ADD COLUMN purchase_date DATE,
+    ADD COLUMN price NUMERIC(10, 2) CHECK (price >= 0),
+    ADD COLUMN lent_out BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN lent_to VARCHAR(255),
+    ADD COLUMN expansions TEXT[] NOT NULL DEFAULT '{}';
+
+-- Существующие записи сохраняют порядок добавления
+UPDATE collection_game cg
+SET position = o.position
+FROM (
+    SELECT collection_id, game_id,
+           ROW_NUMBER() OVER (PARTITION BY collection_id ORDER BY added_at, game_id) AS position
+    FROM collection_game
+) o
+WHERE cg.collection_id = o.collection_id AND cg.game_id = o.game_id;
+
+CREATE INDEX idx_collection_game_position ON collection_game(collection_id, position, game_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_collection_game_position;
+
+ALTER TABLE collection_game
+    DROP COLUMN IF EXISTS expansions,
+    DROP COLUMN IF EXISTS lent_to,
+    DROP COLUMN IF EXISTS lent_out,
+    DROP COLUMN IF EXISTS price,
+    DROP COLUMN IF EXISTS purchase_date,
+    DROP COLUMN IF EXISTS condition,
+    DROP COLUMN IF EXISTS note,
+    DROP COLUMN IF EXISTS position;
+-- +goose StatementEnd
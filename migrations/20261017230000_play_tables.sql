@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE play (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    game_id BIGINT NOT NULL REFERENCES game(id) ON DELETE CASCADE,
+    played_on DATE NOT NULL,
+    duration_minutes INT CHECK (duration_minutes > 0),
+    location VARCHAR(255),
+    note TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_play_user_order ON play(user_id, played_on DESC, id DESC);
+CREATE INDEX idx_play_game_id ON play(game_id);
+
+-- Участники партии: зарегистрированные пользователи или гости по имени
+CREATE TABLE play_player (
+    play_id BIGINT NOT NULL REFERENCES play(id) ON DELETE CASCADE,
+    seat INT NOT NULL,
+    user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    guest_name VARCHAR(255),
+    score NUMERIC(12, 2),
+    winner BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Когда пользователь подтвердил, что играл; до этого партия не попадает
+    -- в его список и статистику. У гостей и у записавшего — сразу.
+    confirmed_at TIMESTAMPTZ,
+    PRIMARY KEY (play_id, seat),
+    CHECK (user_id IS NOT NULL OR guest_name IS NOT NULL)
+);
+
+CREATE UNIQUE INDEX idx_play_player_user ON play_player(user_id, play_id) WHERE user_id IS NOT NULL;
+
+-- Удалённый пользователь остаётся в чужих партиях гостем со своим именем:
+-- иначе ON DELETE SET NULL нарушил бы проверку выше.
+CREATE FUNCTION play_player_keep_deleted_user() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE play_player SET guest_name = OLD.username WHERE user_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_play_player_keep_deleted_user
+    BEFORE DELETE ON users
+    FOR EACH ROW EXECUTE FUNCTION play_player_keep_deleted_user();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trg_play_player_keep_deleted_user ON users;
+DROP FUNCTION IF EXISTS play_player_keep_deleted_user();
+DROP TABLE IF EXISTS play_player;
+DROP TABLE IF EXISTS play;
+-- +goose StatementEnd
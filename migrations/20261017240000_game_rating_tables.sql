@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Агрегаты оценок обновляются приращениями вместе с каждой оценкой.
+-- rating_score — байесовская оценка: среднее с априорными 10 голосами по 5.5.
+ALTER TABLE game
+    ADD COLUMN rating_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN rating_sum INT NOT NULL DEFAULT 0,
+    ADD COLUMN rating_avg DOUBLE PRECISION,
+    ADD COLUMN rating_score DOUBLE PRECISION NOT NULL DEFAULT 5.5;
+
+CREATE INDEX idx_game_rating_avg ON game((COALESCE(rating_avg, 0)) DESC, id);
+CREATE INDEX idx_game_rating_count ON game(rating_count DESC, id);
+CREATE INDEX idx_game_rating_score ON game(rating_score DESC, id);
+
+CREATE TABLE game_rating (
+    game_id BIGINT NOT NULL REFERENCES game(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 10),
+    review TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (game_id, user_id)
+);
+
+CREATE INDEX idx_game_rating_user ON game_rating(user_id);
+CREATE INDEX idx_game_rating_game_order ON game_rating(game_id, updated_at DESC, user_id);
+
+-- Каждая сохранённая версия оценки и отзыва
+CREATE TABLE game_rating_revision (
+    id BIGSERIAL PRIMARY KEY,
+    game_id BIGINT NOT NULL REFERENCES game(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rating SMALLINT NOT NULL,
+    review TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_game_rating_revision_owner ON game_rating_revision(game_id, user_id, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS game_rating_revision;
+DROP TABLE IF EXISTS game_rating;
+
+DROP INDEX IF EXISTS idx_game_rating_score;
+DROP INDEX IF EXISTS idx_game_rating_count;
+DROP INDEX IF EXISTS idx_game_rating_avg;
+
+ALTER TABLE game
+    DROP COLUMN IF EXISTS rating_score,
+    DROP COLUMN IF EXISTS rating_avg,
+    DROP COLUMN IF EXISTS rating_sum,
+    DROP COLUMN IF EXISTS rating_count;
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Системные коллекции (всё, кроме custom) есть у каждого пользователя, по одной каждого типа.
+ALTER TABLE collection
+    ADD COLUMN type VARCHAR(32) NOT NULL DEFAULT 'custom'
+        CHECK (type IN ('custom', 'owned', 'wishlist', 'want_to_play', 'previously_owned'));
+
+CREATE UNIQUE INDEX idx_collection_system_type ON collection(user_id, type) WHERE type <> 'custom';
+
+-- Приоритет игры в списке желаний: 1 — купить в первую очередь
+ALTER TABLE collection_game
+    ADD COLUMN priority SMALLINT CHECK (priority BETWEEN 1 AND 5);
+
+-- Новым пользователям системные коллекции создаёт регистрация, уже
+-- зарегистрированным — эта миграция.
+WITH created AS (
+    INSERT INTO collection (user_id, type, name)
+    SELECT u.id, t.type, t.name
+    FROM users u
+    CROSS JOIN (VALUES
+        ('owned', 'Моя коллекция'),
+        ('wishlist', 'Хочу купить'),
+        ('want_to_play', 'Хочу сыграть'),
+        ('previously_owned', 'Были в коллекции')
+    ) AS t(type, name)
+    RETURNING id, user_id, created_at
+)
+INSERT INTO collection_member (collection_id, user_id, role, accepted_at)
+SELECT id, user_id, 'owner', created_at FROM created;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE collection_game
+    DROP COLUMN IF EXISTS priority;
+
+DROP INDEX IF EXISTS idx_collection_system_type;
+
+-- Системные коллекции остаются обычными
+ALTER TABLE collection
+    DROP COLUMN IF EXISTS type;
+-- +goose StatementEnd
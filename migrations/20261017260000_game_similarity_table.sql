@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Похожие игры, посчитанные фоновым пересчётом: для каждой игры — лучшие
+-- совпадения по жанру, сложности, числу игроков, времени партии и описанию.
+CREATE TABLE game_similarity (
+    game_id BIGINT NOT NULL REFERENCES game(id) ON DELETE CASCADE,
+    similar_id BIGINT NOT NULL REFERENCES game(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL CHECK (score > 0 AND score <= 1),
+    -- Время пересчёта: у всех строк одно, по нему сервер при старте решает,
+    -- нужен ли пересчёт сразу.
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (game_id, similar_id),
+    CHECK (game_id <> similar_id)
+);
+
+CREATE INDEX idx_game_similarity_score ON game_similarity(game_id, score DESC, similar_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS game_similarity;
+-- +goose StatementEnd